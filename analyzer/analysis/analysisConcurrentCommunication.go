@@ -38,7 +38,7 @@ func checkForConcurrentRecv(ch *TraceElementChannel, vc map[int]clock.VectorCloc
 			continue
 		}
 
-		if elem[ch.id].Vc.GetClock() == nil {
+		if elem[ch.id].Vc.IsNil() {
 			continue
 		}
 
@@ -196,6 +196,10 @@ func checkForCyclicDeadlock() {
 	cycles = removeCyclicPermutations(cycles)
 
 	for _, cycle := range cycles {
+		if analysisCanceled() {
+			return
+		}
+
 		// check if the cycle can create a deadlock
 		res := isCycleDeadlock(cycle)
 		if res {
@@ -258,6 +262,9 @@ func traverseTreeAndAddOutsideConnections(node *lockGraphNode) {
 func findCycles() (bool, [][]*lockGraphNode) {
 	cycles := [][]*lockGraphNode{}
 	for routine, tree := range lockGraphs { // for each lock tree
+		if analysisCanceled() {
+			break
+		}
 		findCyclesDFS(tree, &([]*lockGraphNode{}), &cycles, routine, nil)
 	}
 
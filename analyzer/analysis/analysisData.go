@@ -129,4 +129,7 @@ func ClearData() {
 	leakingChannels = make(map[int][]VectorClockTID2)
 	selectCases = make([]allSelectCase, 0)
 	allForks = make(map[int]*TraceElementFork)
+	syncEdges = make(map[int]clock.VectorClock)
+	syncEdgesRead = make(map[int]clock.VectorClock)
+	relObj = make(map[int]clock.VectorClock)
 }
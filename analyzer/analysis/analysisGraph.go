@@ -76,6 +76,10 @@ func calculateMaxFlow(graph map[string][]string) (int, map[string][]string, erro
 	maxNumberRounds = 1e5 * int(math.Pow(float64(maxNumberRounds), 3.))
 
 	for i := 0; i < int(maxNumberRounds); i++ { // max number rounds to prevent infinite loop
+		if analysisCanceled() {
+			return maxFlow, graph, fmt.Errorf("Analysis canceled")
+		}
+
 		path, flow := findPath(graph)
 		if flow == 0 {
 			return maxFlow, graph, nil
@@ -13,6 +13,7 @@ package analysis
 import (
 	"analyzer/clock"
 	"analyzer/results"
+	"analyzer/utils"
 	"log"
 	"strconv"
 	"strings"
@@ -259,10 +260,17 @@ func CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opTy
 /*
  * After all operations have been analyzed, check if there are still leaking
  * operations without a possible partner.
+ * Args:
+ *   res (*results.Collector): The collector for the results
  */
-func checkForLeak() {
+func checkForLeak(res *results.Collector) {
 	// channel
-	for _, vcTIDs := range leakingChannels {
+	for _, id := range utils.SortedKeys(leakingChannels) {
+		if analysisCanceled() {
+			return
+		}
+
+		vcTIDs := leakingChannels[id]
 		buffered := false
 		for _, vcTID := range vcTIDs {
 			if vcTID.tID == "" {
@@ -270,8 +278,12 @@ func checkForLeak() {
 			}
 
 			found := false
-			var partner allSelectCase
-			for _, c := range selectCases {
+			// CheckForSelectCaseWithoutPartner can run concurrently and changes
+			// partnerFound and partner of the select cases. Those fields are
+			// therefore not read here
+			var partner *allSelectCase
+			for i := range selectCases {
+				c := &selectCases[i]
 				if c.chanID != vcTID.id {
 					continue
 				}
@@ -321,7 +333,7 @@ func checkForLeak() {
 					arg2 := results.TraceElementResult{ // select
 						RoutineID: partner.vcTID.Routine, ObjID: partner.sel.GetID(), TPre: tPre2, ObjType: "SS", File: file2, Line: line2}
 
-					results.Result(results.CRITICAL, results.LSelectWith,
+					res.Result(results.CRITICAL, results.LSelectWith,
 						"select", []results.ResultElem{arg1}, "partner", []results.ResultElem{arg2})
 				} else {
 					obType := "C"
@@ -342,7 +354,7 @@ func checkForLeak() {
 					arg2 := results.TraceElementResult{ // select
 						RoutineID: partner.vcTID.Routine, ObjID: partner.sel.GetID(), TPre: tPre2, ObjType: "SS", File: file2, Line: line2}
 
-					results.Result(results.CRITICAL, bugType,
+					res.Result(results.CRITICAL, bugType,
 						"channel", []results.ResultElem{arg1}, "partner", []results.ResultElem{arg2})
 				}
 
@@ -357,7 +369,7 @@ func checkForLeak() {
 					arg1 := results.TraceElementResult{
 						RoutineID: vcTID.routine, ObjID: vcTID.selID, TPre: tPre, ObjType: "SS", File: file, Line: line}
 
-					results.Result(results.CRITICAL, results.LSelectWithout,
+					res.Result(results.CRITICAL, results.LSelectWithout,
 						"select", []results.ResultElem{arg1}, "", []results.ResultElem{})
 
 				} else {
@@ -382,7 +394,7 @@ func checkForLeak() {
 						bugType = results.LBufferedWithout
 					}
 
-					results.Result(results.CRITICAL, bugType,
+					res.Result(results.CRITICAL, bugType,
 						"channel", []results.ResultElem{arg1}, "", []results.ResultElem{})
 				}
 			}
@@ -616,8 +628,19 @@ func CheckForLeakCond(co *TraceElementCond) {
 		"cond", []results.ResultElem{arg}, "", []results.ResultElem{})
 }
 
-func checkForStuckRoutine() {
-	for routine, trace := range traces {
+/*
+ * Check for routines that have not terminated, but whose last operation
+ * is not blocked.
+ * Args:
+ *   res (*results.Collector): The collector for the results
+ */
+func checkForStuckRoutine(res *results.Collector) {
+	for _, routine := range utils.SortedKeys(traces) {
+		if analysisCanceled() {
+			return
+		}
+
+		trace := traces[routine]
 		if len(trace) < 1 {
 			continue
 		}
@@ -649,7 +672,7 @@ func checkForStuckRoutine() {
 			ObjType: "GE", File: file, Line: line,
 		}
 
-		results.Result(results.CRITICAL, results.LWithoutBlock,
+		res.Result(results.CRITICAL, results.LWithoutBlock,
 			"fork", []results.ResultElem{arg}, "", []results.ResultElem{})
 	}
 }
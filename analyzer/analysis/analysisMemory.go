@@ -0,0 +1,190 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: analysisMemory.go
+// Brief: Degrade the analysis if the available memory gets low
+//
+// Author: Erik Kassubek
+// Created: 2024-11-21
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/memory"
+	"log"
+	"os"
+)
+
+// scenarios that are disabled if the memory gets low
+var expensiveScenarios = []string{"concurrentRecv", "selectWithoutPartner",
+	"cyclicDeadlock", "mixedDeadlock"}
+
+// number of processed elements between two spills
+const spillInterval = 1000
+
+var (
+	atomicsDropped    = false
+	expensiveDisabled = false
+	processedElements = 0
+	spillDir          = ""
+	spillFiles        = make(map[int]*clock.SpillFile) // routine -> file
+	spilledUpTo       = make(map[int]int)              // routine -> number of spilled elements
+	degradedScenarios = make([]string, 0)
+)
+
+/*
+ * Apply the degradation steps requested by the memory supervisor.
+ * Must be called from the routine running the analysis, between the
+ * processing of two elements.
+ * Returns:
+ *   bool: true if the analysis was canceled and must be stopped
+ */
+func applyMemoryDegradation() bool {
+	if memory.WasCanceled() {
+		analysisIncomplete = true
+		return true
+	}
+
+	if memory.GetLevel() == memory.LevelNone {
+		return false
+	}
+
+	if memory.DropAtomics() && !atomicsDropped {
+		dropAtomics()
+	}
+
+	processedElements++
+	if memory.Spill() && processedElements%spillInterval == 0 {
+		spillProcessed()
+	}
+
+	if memory.NoExpensive() && !expensiveDisabled {
+		disableExpensiveScenarios()
+	}
+
+	return false
+}
+
+/*
+ * Remove all atomic operations from the trace, like with -a.
+ * The index of the next element of each routine is kept valid.
+ */
+func dropAtomics() {
+	log.Print("Low memory: remove atomic operations from trace")
+	atomicsDropped = true
+
+	for routine, trace := range traces {
+		index := currentIndex[routine]
+		newIndex := -1
+
+		result := make([]TraceElement, 0, len(trace))
+		for i, elem := range trace {
+			if _, ok := elem.(*TraceElementAtomic); ok {
+				continue
+			}
+
+			// first remaining element that has not been processed yet
+			if index != -1 && i >= index && newIndex == -1 {
+				newIndex = len(result)
+			}
+
+			result = append(result, elem)
+		}
+
+		traces[routine] = result
+		currentIndex[routine] = newIndex
+	}
+
+	lw = make(map[int]clock.VectorClock)
+	traceChanged()
+}
+
+/*
+ * Write the vector clocks of all elements that have already been processed
+ * to disk. Each routine is written into its own file.
+ */
+func spillProcessed() {
+	if spillDir == "" {
+		dir, err := os.MkdirTemp("", "advocate_spill_")
+		if err != nil {
+			log.Print("Could not create folder for spilled vector clocks: ", err.Error())
+			return
+		}
+		spillDir = dir
+	}
+
+	for routine, trace := range traces {
+		end := currentIndex[routine]
+		if end == -1 {
+			end = len(trace)
+		}
+
+		if spilledUpTo[routine] >= end {
+			continue
+		}
+
+		if _, ok := spillFiles[routine]; !ok {
+			file, err := clock.NewSpillFile(spillDir)
+			if err != nil {
+				log.Print("Could not create spill file: ", err.Error())
+				return
+			}
+			spillFiles[routine] = file
+		}
+
+		for i := spilledUpTo[routine]; i < end; i++ {
+			vc, err := spillFiles[routine].Spill(trace[i].GetVC())
+			if err != nil {
+				log.Print("Could not spill vector clock: ", err.Error())
+				return
+			}
+			trace[i].setVC(vc)
+		}
+		spilledUpTo[routine] = end
+	}
+}
+
+/*
+ * Disable the scenarios, that keep large amounts of data
+ */
+func disableExpensiveScenarios() {
+	log.Print("Low memory: disable expensive scenarios")
+	expensiveDisabled = true
+
+	for _, scenario := range expensiveScenarios {
+		if analysisCases[scenario] {
+			analysisCases[scenario] = false
+			degradedScenarios = append(degradedScenarios, scenario)
+		}
+	}
+
+	lastRecvRoutine = make(map[int]map[int]VectorClockTID)
+}
+
+/*
+ * Get the scenarios that have been disabled because of low memory
+ * Returns:
+ *   []string: The disabled scenarios
+ */
+func GetDegradedScenarios() []string {
+	return degradedScenarios
+}
+
+/*
+ * Remove all files with spilled vector clocks. Must only be called
+ * when the trace is not used any more.
+ */
+func RemoveSpillFiles() {
+	for _, file := range spillFiles {
+		file.Remove()
+	}
+	spillFiles = make(map[int]*clock.SpillFile)
+	spilledUpTo = make(map[int]int)
+
+	if spillDir != "" {
+		os.RemoveAll(spillDir)
+		spillDir = ""
+	}
+}
@@ -0,0 +1,72 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: analysisParallel.go
+// Brief: Run independent parts of the analysis on a worker pool
+//
+// Author: Erik Kassubek
+// Created: 2024-11-22
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/results"
+	timemeasurement "analyzer/timeMeasurement"
+	"analyzer/utils"
+	"time"
+)
+
+// number of workers for the parallel parts of the analysis, 0 for the number of cpus
+var numberOfWorkers = 0
+
+/*
+ * A part of the analysis that is independent from all other parts in the
+ * same call of runTasks. The task must not change the trace or data that
+ * is read by other tasks and must only report results over res.
+ * Fields:
+ *   counter (string): The time measurement counter the runtime is added to
+ *   run (func(res *results.Collector)): The function to run
+ */
+type analysisTask struct {
+	counter string
+	run     func(res *results.Collector)
+}
+
+/*
+ * Set the number of workers used for the parallel parts of the analysis
+ * Args:
+ *   n (int): The number of workers, 0 for the number of cpus, 1 to run sequentially
+ */
+func SetNumberOfWorkers(n int) {
+	numberOfWorkers = n
+}
+
+/*
+ * Run the tasks on a worker pool. The results of the tasks are added
+ * in the order of the tasks, independent of the order in which the tasks
+ * finish. If the analysis is canceled by the timeout or the memory
+ * supervisor, tasks that have not started yet are skipped.
+ * Args:
+ *   tasks ([]analysisTask): The tasks to run
+ */
+func runTasks(tasks []analysisTask) {
+	collectors := make([]*results.Collector, len(tasks))
+	for i := range collectors {
+		collectors[i] = results.NewCollector()
+	}
+
+	utils.RunParallel(numberOfWorkers, len(tasks), func(i int) {
+		if analysisCanceled() {
+			return
+		}
+
+		start := time.Now()
+		tasks[i].run(collectors[i])
+		timemeasurement.Add(tasks[i].counter, time.Since(start))
+	})
+
+	for _, collector := range collectors {
+		collector.Flush()
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: analysisParallel_test.go
+// Brief: Tests for analysisParallel.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-22
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/results"
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunTasks(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		workers  int
+		nrTasks  int
+		expected int32
+	}{
+		{"sequential", context.Background(), 1, 10, 10},
+		{"parallel", context.Background(), 4, 100, 100},
+		{"no tasks", context.Background(), 4, 0, 0},
+		{"canceled", canceled, 4, 10, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetNumberOfWorkers(test.workers)
+
+			var counter int32
+			tasks := make([]analysisTask, test.nrTasks)
+			for i := range tasks {
+				tasks[i] = analysisTask{"other", func(res *results.Collector) {
+					atomic.AddInt32(&counter, 1)
+				}}
+			}
+
+			analysisContext = test.ctx
+			scenarioCanceled.Store(false)
+			runTasks(tasks)
+
+			if counter != test.expected {
+				t.Errorf("Incorrect number of executed tasks. Expected %d. Got %d.", test.expected, counter)
+			}
+
+			canceled := test.ctx.Err() != nil && test.nrTasks > 0
+			if IsAnalysisIncomplete() != canceled {
+				t.Errorf("Incorrect incomplete flag. Expected %v. Got %v.", canceled, IsAnalysisIncomplete())
+			}
+		})
+	}
+
+	analysisContext = context.Background()
+	scenarioCanceled.Store(false)
+	SetNumberOfWorkers(0)
+}
@@ -13,6 +13,7 @@ package analysis
 import (
 	"analyzer/clock"
 	"analyzer/results"
+	"analyzer/utils"
 	"log"
 	"strconv"
 	"strings"
@@ -21,10 +22,16 @@ import (
 /*
 * CheckForSelectCaseWithoutPartner checks for select cases without a valid
 * partner. Call when all elements have been processed.
+* Args:
+*   res (*results.Collector): The collector for the results
  */
-func CheckForSelectCaseWithoutPartner() {
+func CheckForSelectCaseWithoutPartner(res *results.Collector) {
 	// check if not selected cases could be partners
 	for i, c1 := range selectCases {
+		if analysisCanceled() {
+			return
+		}
+
 		for j := i + 1; j < len(selectCases); j++ {
 			c2 := selectCases[j]
 
@@ -130,7 +137,7 @@ func CheckForSelectCaseWithoutPartner() {
 				continue
 			}
 
-			results.Result(results.INFORMATION, results.SNotExecutedWithPartner,
+			res.Result(results.INFORMATION, results.SNotExecutedWithPartner,
 				"select", []results.ResultElem{sel, ca}, "partner", partnerResult)
 			continue
 		}
@@ -151,7 +158,8 @@ func CheckForSelectCaseWithoutPartner() {
 		casesWithoutPartner[c.vcTID.TID] = append(casesWithoutPartner[c.vcTID.TID], arg2)
 	}
 
-	for tID, cases := range casesWithoutPartner {
+	for _, tID := range utils.SortedKeys(casesWithoutPartner) {
+		cases := casesWithoutPartner[tID]
 		if len(cases) == 0 {
 			continue
 		}
@@ -177,7 +185,7 @@ func CheckForSelectCaseWithoutPartner() {
 			Line:      line,
 		}
 
-		results.Result(results.WARNING, results.ASelCaseWithoutPartner,
+		res.Result(results.WARNING, results.ASelCaseWithoutPartner,
 			"select", []results.ResultElem{arg1}, "case", cases)
 	}
 }
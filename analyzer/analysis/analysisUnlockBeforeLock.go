@@ -47,110 +47,131 @@ func checkForUnlockBeforeLockUnlock(mu *TraceElementMutex) {
 
 /*
  * Check if we can get a unlock of a not locked mutex
- * For each done operation, build a bipartite st graph.
- * Use the Ford-Fulkerson algorithm to find the maximum flow.
- * If the maximum flow is smaller than the number of unlock operations, a unlock before lock is possible.
+ * The mutexes are independent of each other, so each mutex is checked in
+ * its own task.
+ * Returns:
+ *   []analysisTask: One task for each mutex
  */
-func checkForUnlockBeforeLock() {
-
+func checkForUnlockBeforeLock() []analysisTask {
 	fmt.Println("Check for unlock before lock")
-	defer fmt.Println("Finished check for unlock before lock")
-	for id := range allUnlocks { // for all mutex ids
+
+	tasks := make([]analysisTask, 0, len(allUnlocks))
+	for _, id := range utils.SortedKeys(allUnlocks) { // for all mutex ids
 		// if a lock and the corresponding unlock is always in the same routine, this cannot happen
 		if sameRoutine(allLocks[id], allUnlocks[id]) {
 			continue
 		}
 
-		graph := buildResidualGraph(allLocks[id], allUnlocks[id])
+		id := id
+		tasks = append(tasks, analysisTask{"panic", func(res *results.Collector) {
+			checkForUnlockBeforeLockMutex(id, res)
+		}})
+	}
+	return tasks
+}
 
-		maxFlow, graph, err := calculateMaxFlow(graph)
-		if err != nil {
-			fmt.Println("Could not check for unlock before lock: ", err)
-		}
+/*
+ * Check if we can get a unlock of a not locked mutex for one mutex
+ * For each done operation, build a bipartite st graph.
+ * Use the Ford-Fulkerson algorithm to find the maximum flow.
+ * If the maximum flow is smaller than the number of unlock operations, a unlock before lock is possible.
+ * Args:
+ *   id (int): The id of the mutex
+ *   res (*results.Collector): The collector for the results
+ */
+func checkForUnlockBeforeLockMutex(id int, res *results.Collector) {
+	graph := buildResidualGraph(allLocks[id], allUnlocks[id])
 
-		nrUnlock := len(allUnlocks)
+	maxFlow, graph, err := calculateMaxFlow(graph)
+	if analysisCanceled() {
+		return
+	}
+	if err != nil {
+		fmt.Println("Could not check for unlock before lock: ", err)
+	}
 
-		locks := []TraceElement{}
-		unlocks := []TraceElement{}
+	nrUnlock := len(allUnlocks)
 
-		if maxFlow < nrUnlock {
-			for _, l := range allLocks[id] {
-				if !utils.ContainsString(graph["t"], l.GetTID()) {
-					locks = append(locks, l)
-				}
+	locks := []TraceElement{}
+	unlocks := []TraceElement{}
+
+	if maxFlow < nrUnlock {
+		for _, l := range allLocks[id] {
+			if !utils.ContainsString(graph["t"], l.GetTID()) {
+				locks = append(locks, l)
 			}
+		}
 
-			for _, u := range graph["s"] {
-				unlockTId, err := getUnlockElemFromTID(id, u)
-				if err != nil {
-					log.Print(err.Error())
-				} else {
-					unlocks = append(unlocks, unlockTId)
-				}
+		for _, u := range graph["s"] {
+			unlockTId, err := getUnlockElemFromTID(id, u)
+			if err != nil {
+				log.Print(err.Error())
+			} else {
+				unlocks = append(unlocks, unlockTId)
 			}
+		}
 
-			locksSorted := make([]TraceElement, 0)
-			unlockSorted := make([]TraceElement, 0)
-
-			for i := 0; i < len(locks); i++ {
-				for j := 0; j < len(unlocks); j++ {
-					if clock.GetHappensBefore(locks[i].GetVC(), unlocks[j].GetVC()) == clock.Concurrent {
-						locksSorted = append(locksSorted, locks[i])
-						unlockSorted = append(unlockSorted, unlocks[i])
-						locks = append(locks[:i], locks[i+1:]...)
-						unlocks = append(unlocks[:j], unlocks[j+1:]...)
-						i--
-						j = 0
-					}
+		locksSorted := make([]TraceElement, 0)
+		unlockSorted := make([]TraceElement, 0)
+
+		for i := 0; i < len(locks); i++ {
+			for j := 0; j < len(unlocks); j++ {
+				if clock.GetHappensBefore(locks[i].GetVC(), unlocks[j].GetVC()) == clock.Concurrent {
+					locksSorted = append(locksSorted, locks[i])
+					unlockSorted = append(unlockSorted, unlocks[i])
+					locks = append(locks[:i], locks[i+1:]...)
+					unlocks = append(unlocks[:j], unlocks[j+1:]...)
+					i--
+					j = 0
 				}
 			}
+		}
 
-			args1 := []results.ResultElem{} // unlocks
-			args2 := []results.ResultElem{} // locks
+		args1 := []results.ResultElem{} // unlocks
+		args2 := []results.ResultElem{} // locks
 
-			for _, u := range unlockSorted {
-				if u.GetTID() == "\n" {
-					continue
-				}
-				file, line, tPre, err := infoFromTID(u.GetTID())
-				if err != nil {
-					log.Print(err.Error())
-					continue
-				}
-
-				args1 = append(args1, results.TraceElementResult{
-					RoutineID: u.GetRoutine(),
-					ObjID:     id,
-					TPre:      tPre,
-					ObjType:   u.GetObjType(),
-					File:      file,
-					Line:      line,
-				})
+		for _, u := range unlockSorted {
+			if u.GetTID() == "\n" {
+				continue
+			}
+			file, line, tPre, err := infoFromTID(u.GetTID())
+			if err != nil {
+				log.Print(err.Error())
+				continue
 			}
 
-			for _, l := range locksSorted {
-				if l.GetTID() == "\n" {
-					continue
-				}
-				file, line, tPre, err := infoFromTID(l.GetTID())
-				if err != nil {
-					log.Print(err.Error())
-					continue
-				}
+			args1 = append(args1, results.TraceElementResult{
+				RoutineID: u.GetRoutine(),
+				ObjID:     id,
+				TPre:      tPre,
+				ObjType:   u.GetObjType(),
+				File:      file,
+				Line:      line,
+			})
+		}
 
-				args2 = append(args2, results.TraceElementResult{
-					RoutineID: l.GetRoutine(),
-					ObjID:     id,
-					TPre:      tPre,
-					ObjType:   l.GetObjType(),
-					File:      file,
-					Line:      line,
-				})
+		for _, l := range locksSorted {
+			if l.GetTID() == "\n" {
+				continue
+			}
+			file, line, tPre, err := infoFromTID(l.GetTID())
+			if err != nil {
+				log.Print(err.Error())
+				continue
 			}
 
-			results.Result(results.CRITICAL, results.PUnlockBeforeLock, "unlock",
-				args1, "lock", args2)
+			args2 = append(args2, results.TraceElementResult{
+				RoutineID: l.GetRoutine(),
+				ObjID:     id,
+				TPre:      tPre,
+				ObjType:   l.GetObjType(),
+				File:      file,
+				Line:      line,
+			})
 		}
+
+		res.Result(results.CRITICAL, results.PUnlockBeforeLock, "unlock",
+			args1, "lock", args2)
 	}
 }
 
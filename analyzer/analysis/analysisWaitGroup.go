@@ -69,111 +69,132 @@ func checkForDoneBeforeAddDone(wa *TraceElementWait) {
 
 /*
  * Check if a wait group counter could become negative
+ * The wait groups are independent of each other, so each wait group is
+ * checked in its own task.
+ * Returns:
+ *   []analysisTask: One task for each wait group
+ */
+func checkForDoneBeforeAdd() []analysisTask {
+	fmt.Println("Check for done before add")
+
+	tasks := make([]analysisTask, 0, len(wgAdd))
+	for _, id := range utils.SortedKeys(wgAdd) { // for all waitgroups
+		id := id
+		tasks = append(tasks, analysisTask{"panic", func(res *results.Collector) {
+			checkForDoneBeforeAddWaitGroup(id, res)
+		}})
+	}
+	return tasks
+}
+
+/*
+ * Check if the counter of one wait group could become negative
  * For each done operation, build a bipartite st graph.
  * Use the Ford-Fulkerson algorithm to find the maximum flow.
  * If the maximum flow is smaller than the number of done operations, a negative wait group counter is possible.
+ * Args:
+ *   id (int): The id of the wait group
+ *   res (*results.Collector): The collector for the results
  */
-func checkForDoneBeforeAdd() {
-	fmt.Println("Check for done before add")
-	defer fmt.Println("Finished check for done before add")
-	for id := range wgAdd { // for all waitgroups
-
-		graph := buildResidualGraph(wgAdd[id], wgDone[id])
+func checkForDoneBeforeAddWaitGroup(id int, res *results.Collector) {
+	graph := buildResidualGraph(wgAdd[id], wgDone[id])
 
-		maxFlow, graph, err := calculateMaxFlow(graph)
-		if err != nil {
-			fmt.Println("Could not check for done before add: ", err)
-		}
-		nrDone := len(wgDone[id])
+	maxFlow, graph, err := calculateMaxFlow(graph)
+	if analysisCanceled() {
+		return
+	}
+	if err != nil {
+		fmt.Println("Could not check for done before add: ", err)
+	}
+	nrDone := len(wgDone[id])
 
-		addsNegWg := []TraceElement{}
-		donesNegWg := []TraceElement{}
+	addsNegWg := []TraceElement{}
+	donesNegWg := []TraceElement{}
 
-		if maxFlow < nrDone {
-			// sort the adds and dones, that do not have a partner is such a way,
-			// that the i-th add in the result message is concurrent with the
-			// i-th done in the result message
+	if maxFlow < nrDone {
+		// sort the adds and dones, that do not have a partner is such a way,
+		// that the i-th add in the result message is concurrent with the
+		// i-th done in the result message
 
-			for _, add := range wgAdd[id] {
-				if !utils.ContainsString(graph["t"], add.GetTID()) {
-					addsNegWg = append(addsNegWg, add)
-				}
+		for _, add := range wgAdd[id] {
+			if !utils.ContainsString(graph["t"], add.GetTID()) {
+				addsNegWg = append(addsNegWg, add)
 			}
+		}
 
-			for _, dones := range graph["s"] {
-				doneVcTID, err := getDoneElemFromTID(id, dones)
-				if err != nil {
-					log.Print(err.Error())
-				} else {
-					donesNegWg = append(donesNegWg, doneVcTID)
-				}
+		for _, dones := range graph["s"] {
+			doneVcTID, err := getDoneElemFromTID(id, dones)
+			if err != nil {
+				log.Print(err.Error())
+			} else {
+				donesNegWg = append(donesNegWg, doneVcTID)
 			}
+		}
 
-			addsNegWgSorted := make([]TraceElement, 0)
-			donesNEgWgSorted := make([]TraceElement, 0)
-
-			for i := 0; i < len(addsNegWg); i++ {
-				for j := 0; j < len(donesNegWg); j++ {
-					if clock.GetHappensBefore(addsNegWg[i].GetVC(), donesNegWg[j].GetVC()) == clock.Concurrent {
-						addsNegWgSorted = append(addsNegWgSorted, addsNegWg[i])
-						donesNEgWgSorted = append(donesNEgWgSorted, donesNegWg[j])
-						// remove the element from the list
-						addsNegWg = append(addsNegWg[:i], addsNegWg[i+1:]...)
-						donesNegWg = append(donesNegWg[:j], donesNegWg[j+1:]...)
-						// fix the index
-						i--
-						j = 0
-					}
+		addsNegWgSorted := make([]TraceElement, 0)
+		donesNEgWgSorted := make([]TraceElement, 0)
+
+		for i := 0; i < len(addsNegWg); i++ {
+			for j := 0; j < len(donesNegWg); j++ {
+				if clock.GetHappensBefore(addsNegWg[i].GetVC(), donesNegWg[j].GetVC()) == clock.Concurrent {
+					addsNegWgSorted = append(addsNegWgSorted, addsNegWg[i])
+					donesNEgWgSorted = append(donesNEgWgSorted, donesNegWg[j])
+					// remove the element from the list
+					addsNegWg = append(addsNegWg[:i], addsNegWg[i+1:]...)
+					donesNegWg = append(donesNegWg[:j], donesNegWg[j+1:]...)
+					// fix the index
+					i--
+					j = 0
 				}
 			}
+		}
 
-			args1 := []results.ResultElem{} // dones
-			args2 := []results.ResultElem{} // adds
+		args1 := []results.ResultElem{} // dones
+		args2 := []results.ResultElem{} // adds
 
-			for _, done := range donesNEgWgSorted {
-				if done.GetTID() == "\n" {
-					continue
-				}
-				file, line, tPre, err := infoFromTID(done.GetTID())
-				if err != nil {
-					log.Print(err.Error())
-					return
-				}
-
-				args1 = append(args1, results.TraceElementResult{
-					RoutineID: done.GetRoutine(),
-					ObjID:     id,
-					TPre:      tPre,
-					ObjType:   "WD",
-					File:      file,
-					Line:      line,
-				})
+		for _, done := range donesNEgWgSorted {
+			if done.GetTID() == "\n" {
+				continue
+			}
+			file, line, tPre, err := infoFromTID(done.GetTID())
+			if err != nil {
+				log.Print(err.Error())
+				return
 			}
 
-			for _, add := range addsNegWgSorted {
-				if add.GetTID() == "\n" {
-					continue
-				}
-				file, line, tPre, err := infoFromTID(add.GetTID())
-				if err != nil {
-					log.Print(err.Error())
-					continue
-				}
-
-				args2 = append(args2, results.TraceElementResult{
-					RoutineID: add.GetRoutine(),
-					ObjID:     id,
-					TPre:      tPre,
-					ObjType:   "WA",
-					File:      file,
-					Line:      line,
-				})
+			args1 = append(args1, results.TraceElementResult{
+				RoutineID: done.GetRoutine(),
+				ObjID:     id,
+				TPre:      tPre,
+				ObjType:   "WD",
+				File:      file,
+				Line:      line,
+			})
+		}
 
+		for _, add := range addsNegWgSorted {
+			if add.GetTID() == "\n" {
+				continue
 			}
+			file, line, tPre, err := infoFromTID(add.GetTID())
+			if err != nil {
+				log.Print(err.Error())
+				continue
+			}
+
+			args2 = append(args2, results.TraceElementResult{
+				RoutineID: add.GetRoutine(),
+				ObjID:     id,
+				TPre:      tPre,
+				ObjType:   "WA",
+				File:      file,
+				Line:      line,
+			})
 
-			results.Result(results.CRITICAL, results.PNegWG,
-				"done", args1, "add", args2)
 		}
+
+		res.Result(results.CRITICAL, results.PNegWG,
+			"done", args1, "add", args2)
 	}
 }
 
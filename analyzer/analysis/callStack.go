@@ -0,0 +1,69 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: callStack.go
+// Brief: Call stacks of the trace elements
+//
+// Author: Erik Kassubek
+// Created: 2024-11-19
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/results"
+	"strings"
+)
+
+// call stacks of the elements, only set if the trace was recorded with a
+// stack depth > 1. The innermost frame is the position of the element.
+var callStacks = make(map[int][]string) // tPre -> stack
+
+/*
+ * Split the position field of a trace element into the position and the
+ * call stack. If the trace was recorded with a stack depth > 1,
+ * the frames are separated by ;, starting with the innermost frame.
+ * Args:
+ *   pos (string): the position field of the element
+ * Returns:
+ *   string: the position of the element (innermost frame)
+ *   []string: the call stack, nil if the field only contains the position
+ */
+func SplitPosStack(pos string) (string, []string) {
+	if !strings.Contains(pos, ";") {
+		return pos, nil
+	}
+
+	stack := strings.Split(pos, ";")
+	return stack[0], stack
+}
+
+/*
+ * Set the call stack of an element
+ * Args:
+ *   tPre (int): the tPre of the element
+ *   stack ([]string): the call stack, starting with the innermost frame
+ */
+func SetStack(tPre int, stack []string) {
+	if len(stack) <= 1 {
+		return
+	}
+
+	callStacks[tPre] = stack
+	results.SetStack(tPre, stack)
+}
+
+/*
+ * Get the call stack of an element. If no stack was recorded,
+ * the stack only contains the position of the element.
+ * Args:
+ *   elem (TraceElement): the element
+ * Returns:
+ *   []string: the call stack, starting with the innermost frame
+ */
+func GetStack(elem TraceElement) []string {
+	if stack, ok := callStacks[elem.GetTPre()]; ok {
+		return stack
+	}
+	return []string{elem.GetPos()}
+}
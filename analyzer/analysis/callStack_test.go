@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: callStack_test.go
+// Brief: Tests for callStack
+//
+// Author: Erik Kassubek
+// Created: 2024-11-19
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitPosStack(t *testing.T) {
+	var tests = []struct {
+		name     string
+		pos      string
+		expPos   string
+		expStack []string
+	}{
+		{"Only position", "testfile.go:12", "testfile.go:12", nil},
+		{"Stack", "helper.go:12;server.go:88;main.go:5", "helper.go:12",
+			[]string{"helper.go:12", "server.go:88", "main.go:5"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pos, stack := SplitPosStack(test.pos)
+
+			if pos != test.expPos {
+				t.Errorf("Incorrect result for SplitPosStack(%s) pos. Expected %s. Got %s.",
+					test.pos, test.expPos, pos)
+			}
+
+			if !slices.Equal(stack, test.expStack) {
+				t.Errorf("Incorrect result for SplitPosStack(%s) stack. Expected %v. Got %v.",
+					test.pos, test.expStack, stack)
+			}
+		})
+	}
+}
+
+func TestGetStack(t *testing.T) {
+	ClearTrace()
+	callStacks = make(map[int][]string)
+
+	AddTraceElementChannel(1, "2", "4", "5", "S", "f", "1", "0", "helper.go:12")
+	AddTraceElementChannel(1, "6", "8", "5", "S", "f", "2", "0", "helper.go:12")
+	SetStack(2, []string{"helper.go:12", "server.go:88"})
+
+	exp := [][]string{{"helper.go:12", "server.go:88"}, {"helper.go:12"}}
+	for i, elem := range traces[1] {
+		if stack := GetStack(elem); !slices.Equal(stack, exp[i]) {
+			t.Errorf("Incorrect result for GetStack of element %d. Expected %v. Got %v.",
+				i, exp[i], stack)
+		}
+	}
+}
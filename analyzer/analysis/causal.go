@@ -0,0 +1,286 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: causal.go
+// Brief: Explain found bugs by the vector clocks of the involved elements
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/results"
+	"fmt"
+	"strings"
+)
+
+// operations on the objects for the explanation
+var causalOps = map[string]string{
+	"CS": "send on channel",
+	"CR": "receive on channel",
+	"CC": "close of channel",
+	"ML": "lock of mutex",
+	"MR": "rlock of mutex",
+	"MT": "trylock of mutex",
+	"MY": "tryrlock of mutex",
+	"MU": "unlock of mutex",
+	"MN": "runlock of mutex",
+	"WA": "add on wait group",
+	"WD": "done on wait group",
+	"WW": "wait on wait group",
+	"SS": "select",
+	"NW": "wait on cond",
+	"NB": "broadcast on cond",
+	"NS": "signal on cond",
+	"OE": "once",
+	"ON": "once",
+	"GF": "go statement",
+}
+
+/*
+ * Get the causal explanation for a result of the analysis.
+ * For P01 and P03 it explains, why the two operations are concurrent,
+ * for leaks, where the routine is blocked and, if it exists, why the
+ * possible partner is concurrent to it.
+ * Must be called after the analysis, while the trace with the vector clocks
+ * is still available.
+ * Args:
+ *   result (string): the result in the machine readable format
+ * Returns:
+ *   *results.Causal: the explanation, nil if not available for the result
+ */
+func ExplainResult(result string) *results.Causal {
+	fields := strings.Split(result, ",")
+	resType := fields[0]
+
+	if resType != string(results.PSendOnClosed) && resType != string(results.PNegWG) &&
+		!strings.HasPrefix(resType, "L") {
+		return nil
+	}
+
+	// routine end elements are not in the trace, the argument points
+	// to the last element of the routine instead
+	notTerminated := false
+
+	args := make([][]TraceElement, 0)
+	for _, field := range fields[1:] {
+		elems := make([]TraceElement, 0)
+		for _, arg := range strings.Split(field, ";") {
+			if strings.Contains(arg, ":GE:") {
+				notTerminated = true
+			}
+			elem, err := GetTraceElementFromBugArg(arg)
+			if err != nil || elem.GetVC().IsNil() {
+				continue
+			}
+			elems = append(elems, elem)
+		}
+		args = append(args, elems)
+	}
+
+	if len(args) == 0 || len(args[0]) == 0 {
+		return nil
+	}
+
+	causal := &results.Causal{
+		Elems:     make([]results.CausalElem, 0),
+		Narrative: make([]string, 0),
+	}
+
+	for _, arg := range args {
+		for _, elem := range arg {
+			causal.Elems = append(causal.Elems, results.CausalElem{
+				Routine: elem.GetRoutine(),
+				TPre:    elem.GetTPre(),
+				Op:      causalOp(elem),
+				Pos:     elem.GetPos(),
+				VC:      elem.GetVC().ToString(),
+			})
+		}
+	}
+
+	if len(args) < 2 || len(args[1]) == 0 {
+		causal.Narrative = explainBlocked(args[0][0], notTerminated)
+		return causal
+	}
+
+	// explain the first pair of concurrent elements
+	a, b := args[0][0], args[1][0]
+	found := false
+	for _, elemA := range args[0] {
+		for _, elemB := range args[1] {
+			if clock.GetHappensBefore(elemA.GetVC(), elemB.GetVC()) == clock.Concurrent {
+				a, b = elemA, elemB
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	if strings.HasPrefix(resType, "L") {
+		causal.Narrative = explainBlocked(a, false)
+		causal.Narrative = append(causal.Narrative, explainPair(b, a)...)
+	} else {
+		causal.Narrative = explainPair(a, b)
+	}
+
+	return causal
+}
+
+/*
+ * Get the description of the operation of an element
+ * Args:
+ *   elem (TraceElement): the element
+ * Returns:
+ *   string: the description, e.g. send on channel 3
+ */
+func causalOp(elem TraceElement) string {
+	objType := elem.GetObjType()
+	if strings.HasPrefix(objType, "A") {
+		return "atomic operation"
+	}
+
+	op, ok := causalOps[objType]
+	if !ok {
+		return "operation"
+	}
+
+	if objType == "GF" {
+		return op
+	}
+	return fmt.Sprintf("%s %d", op, elem.GetID())
+}
+
+/*
+ * Get the description of an element with position and tPre
+ * Args:
+ *   elem (TraceElement): the element
+ * Returns:
+ *   string: the description
+ */
+func causalDesc(elem TraceElement) string {
+	return fmt.Sprintf("the %s at %s (tPre %d)", causalOp(elem), elem.GetPos(), elem.GetTPre())
+}
+
+/*
+ * Explain the relation of two elements
+ * Args:
+ *   a (TraceElement): the first element
+ *   b (TraceElement): the second element
+ * Returns:
+ *   []string: the explanation
+ */
+func explainPair(a TraceElement, b TraceElement) []string {
+	ra, rb := a.GetRoutine(), b.GetRoutine()
+	vcA, vcB := a.GetVC(), b.GetVC()
+
+	res := []string{
+		fmt.Sprintf("Routine %d executes %s with vector clock %s.", ra, causalDesc(a), vcA.ToString()),
+		fmt.Sprintf("Routine %d executes %s with vector clock %s.", rb, causalDesc(b), vcB.ToString()),
+	}
+
+	switch clock.GetHappensBefore(vcA, vcB) {
+	case clock.Before:
+		res = append(res, fmt.Sprintf("The %s happens before the %s, so they cannot be reordered.",
+			causalOp(a), causalOp(b)))
+		return res
+	case clock.After:
+		res = append(res, fmt.Sprintf("The %s happens before the %s, so they cannot be reordered.",
+			causalOp(b), causalOp(a)))
+		return res
+	case clock.None:
+		return res
+	}
+
+	res = append(res,
+		fmt.Sprintf("The %s in routine %d is not ordered after the %s: its vector clock only knows %d operation(s) of routine %d, but the %s is at %d.",
+			causalOp(b), rb, causalOp(a), vcB.GetValue(ra), ra, causalOp(a), vcA.GetValue(ra)),
+		fmt.Sprintf("The %s in routine %d is not ordered after the %s: its vector clock only knows %d operation(s) of routine %d, but the %s is at %d.",
+			causalOp(a), ra, causalOp(b), vcA.GetValue(rb), rb, causalOp(b), vcB.GetValue(rb)),
+		"No channel, lock, wait group or other synchronization edge connects them, so they can be executed in either order.",
+		nearestSyncPoint(a, b),
+		nearestSyncPoint(b, a),
+	)
+
+	return res
+}
+
+/*
+ * Explain where a routine is blocked
+ * Args:
+ *   elem (TraceElement): the blocked element
+ *   notTerminated (bool): true if elem is the last element of a routine that did not terminate
+ * Returns:
+ *   []string: the explanation
+ */
+func explainBlocked(elem TraceElement, notTerminated bool) []string {
+	routine := elem.GetRoutine()
+	res := make([]string, 0)
+
+	if notTerminated {
+		res = append(res, fmt.Sprintf("Routine %d did not terminate before the end of the program. Its last operation was %s with vector clock %s.",
+			routine, causalDesc(elem), elem.GetVC().ToString()))
+		return res
+	}
+
+	res = append(res, fmt.Sprintf("Routine %d is blocked at %s with vector clock %s until the end of the program.",
+		routine, causalDesc(elem), elem.GetVC().ToString()))
+
+	if prev := previousInRoutine(elem); prev != nil {
+		res = append(res, fmt.Sprintf("The last operation before it in routine %d was %s.", routine, causalDesc(prev)))
+	} else {
+		res = append(res, fmt.Sprintf("It is the first recorded operation of routine %d.", routine))
+	}
+
+	return res
+}
+
+/*
+ * Get the last element in the routine of a that happens before b
+ * Args:
+ *   a (TraceElement): the element, whose routine is searched
+ *   b (TraceElement): the element the result must happen before
+ * Returns:
+ *   string: description of the nearest sync point
+ */
+func nearestSyncPoint(a TraceElement, b TraceElement) string {
+	ra := a.GetRoutine()
+	trace := GetTraceFromId(ra)
+
+	for i := len(trace) - 1; i >= 0; i-- {
+		elem := trace[i]
+		if elem.GetTPre() >= a.GetTPre() || elem.GetVC().IsNil() {
+			continue
+		}
+		if clock.GetHappensBefore(elem.GetVC(), b.GetVC()) == clock.Before {
+			return fmt.Sprintf("The nearest sync point of routine %d before the %s is %s.",
+				ra, causalOp(b), causalDesc(elem))
+		}
+	}
+
+	return fmt.Sprintf("No operation of routine %d happens before the %s.", ra, causalOp(b))
+}
+
+/*
+ * Get the element before an element in its routine
+ * Args:
+ *   elem (TraceElement): the element
+ * Returns:
+ *   TraceElement: the previous element, nil if it does not exist
+ */
+func previousInRoutine(elem TraceElement) TraceElement {
+	var prev TraceElement
+	for _, e := range GetTraceFromId(elem.GetRoutine()) {
+		if e.GetTPre() >= elem.GetTPre() {
+			break
+		}
+		prev = e
+	}
+	return prev
+}
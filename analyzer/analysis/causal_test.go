@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: causal_test.go
+// Brief: Tests for causal.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"strings"
+	"testing"
+)
+
+func setCausalTestTrace() {
+	ClearTrace()
+	AddElementToTrace(&TraceElementChannel{routine: 1, tPre: 1, tPost: 1, id: 4, opC: SendOp, pos: "a.go:1",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1})})
+	AddElementToTrace(&TraceElementChannel{routine: 2, tPre: 2, tPost: 2, id: 4, opC: RecvOp, pos: "b.go:2",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1, 2: 1})})
+	AddElementToTrace(&TraceElementChannel{routine: 1, tPre: 3, tPost: 3, id: 3, opC: SendOp, pos: "a.go:3",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 2})})
+	AddElementToTrace(&TraceElementChannel{routine: 2, tPre: 4, tPost: 4, id: 3, opC: CloseOp, pos: "b.go:4",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1, 2: 2})})
+}
+
+func TestExplainResult(t *testing.T) {
+	var tests = []struct {
+		name      string
+		result    string
+		nil       bool
+		elems     int
+		narrative []string
+	}{
+		{"Send on closed", "P01,T:1:3:3:CS:a.go:3,T:2:3:4:CC:b.go:4", false, 2, []string{
+			"Routine 1 executes the send on channel 3 at a.go:3 (tPre 3) with vector clock [2, 0].",
+			"The close of channel 3 in routine 2 is not ordered after the send on channel 3: its vector clock only knows 1 operation(s) of routine 1, but the send on channel 3 is at 2.",
+			"The nearest sync point of routine 1 before the close of channel 3 is the send on channel 4 at a.go:1 (tPre 1).",
+			"No operation of routine 2 happens before the send on channel 3.",
+		}},
+		{"Leak without partner", "L02,T:1:3:3:CS:a.go:3,", false, 1, []string{
+			"Routine 1 is blocked at the send on channel 3 at a.go:3 (tPre 3) with vector clock [2, 0] until the end of the program.",
+			"The last operation before it in routine 1 was the send on channel 4 at a.go:1 (tPre 1).",
+		}},
+		{"Not terminated", "L00,T:2:-1:4:GE:b.go:4", false, 1, []string{
+			"Routine 2 did not terminate before the end of the program. Its last operation was the close of channel 3 at b.go:4 (tPre 4) with vector clock [1, 2].",
+		}},
+		{"Ordered", "P01,T:1:4:1:CS:a.go:1,T:2:3:4:CC:b.go:4", false, 2, []string{
+			"The send on channel 4 happens before the close of channel 3, so they cannot be reordered.",
+		}},
+		{"Other type", "P04,T:1:3:3:CS:a.go:3", true, 0, nil},
+		{"Missing element", "P01,T:1:3:9:CS:a.go:9,T:2:3:4:CC:b.go:4", true, 0, nil},
+	}
+
+	setCausalTestTrace()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			causal := ExplainResult(test.result)
+			if test.nil {
+				if causal != nil {
+					t.Errorf("Expected no explanation. Got %v", causal)
+				}
+				return
+			}
+
+			if causal == nil {
+				t.Fatalf("Expected explanation. Got nil")
+			}
+
+			if len(causal.Elems) != test.elems {
+				t.Errorf("Incorrect number of elements. Expected %d. Got %d.", test.elems, len(causal.Elems))
+			}
+
+			narrative := strings.Join(causal.Narrative, "\n")
+			for _, sentence := range test.narrative {
+				if !strings.Contains(narrative, sentence) {
+					t.Errorf("Missing sentence %q in %q", sentence, narrative)
+				}
+			}
+		})
+	}
+
+	ClearTrace()
+}
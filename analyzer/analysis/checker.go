@@ -0,0 +1,217 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: checker.go
+// Brief: Interface and registration of custom checkers
+//
+// Author: Erik Kassubek
+// Created: 2024-11-24
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/results"
+	"errors"
+	"fmt"
+)
+
+/*
+ * Vector clocks of the routine of an element, directly after the
+ * element was processed by the analysis. The clocks are used by the
+ * analysis and must not be changed. Use Copy() to store them.
+ * Fields:
+ *   HB (clock.VectorClock): The happens before vector clock
+ *   WMHB (clock.VectorClock): The weak must happens before vector clock
+ */
+type CheckerClocks struct {
+	HB   clock.VectorClock
+	WMHB clock.VectorClock
+}
+
+/*
+ * Interface for custom checkers. A checker is registered with
+ * RegisterChecker and run if it is selected with -s [name].
+ * For each element of the trace, the method for the type of the element is
+ * called in the order in which the elements are analyzed. Results found
+ * while processing the elements can be reported with results.Result.
+ * Finish is called after all elements have been processed. Finish can run
+ * concurrently to other scenarios and must therefore only report results
+ * over res.
+ * Embed CheckerBase to only implement the methods that are needed.
+ */
+type Checker interface {
+	Name() string
+	Init()
+	Atomic(e *TraceElementAtomic, clocks CheckerClocks)
+	Channel(e *TraceElementChannel, clocks CheckerClocks)
+	Select(e *TraceElementSelect, clocks CheckerClocks)
+	Mutex(e *TraceElementMutex, clocks CheckerClocks)
+	Wait(e *TraceElementWait, clocks CheckerClocks)
+	Cond(e *TraceElementCond, clocks CheckerClocks)
+	Once(e *TraceElementOnce, clocks CheckerClocks)
+	Fork(e *TraceElementFork, clocks CheckerClocks)
+	New(e *TraceElementNew, clocks CheckerClocks)
+	RoutineEnd(e *TraceElementRoutineEnd, clocks CheckerClocks)
+	Finish(res *results.Collector)
+}
+
+/*
+ * Implementation of all methods of Checker that does nothing
+ */
+type CheckerBase struct{}
+
+func (CheckerBase) Init()                                                      {}
+func (CheckerBase) Atomic(e *TraceElementAtomic, clocks CheckerClocks)         {}
+func (CheckerBase) Channel(e *TraceElementChannel, clocks CheckerClocks)       {}
+func (CheckerBase) Select(e *TraceElementSelect, clocks CheckerClocks)         {}
+func (CheckerBase) Mutex(e *TraceElementMutex, clocks CheckerClocks)           {}
+func (CheckerBase) Wait(e *TraceElementWait, clocks CheckerClocks)             {}
+func (CheckerBase) Cond(e *TraceElementCond, clocks CheckerClocks)             {}
+func (CheckerBase) Once(e *TraceElementOnce, clocks CheckerClocks)             {}
+func (CheckerBase) Fork(e *TraceElementFork, clocks CheckerClocks)             {}
+func (CheckerBase) New(e *TraceElementNew, clocks CheckerClocks)               {}
+func (CheckerBase) RoutineEnd(e *TraceElementRoutineEnd, clocks CheckerClocks) {}
+func (CheckerBase) Finish(res *results.Collector)                              {}
+
+// registered checkers in the order of registration
+var checkers = make([]Checker, 0)
+
+/*
+ * Register a custom checker. Must be called before the analysis is started,
+ * e.g. in the init function of the package of the checker.
+ * Args:
+ *   checker (Checker): The checker
+ * Returns:
+ *   error: If the name of the checker is empty or already registered
+ */
+func RegisterChecker(checker Checker) error {
+	name := checker.Name()
+	if name == "" {
+		return errors.New("Name of checker is empty")
+	}
+
+	if GetChecker(name) != nil {
+		return fmt.Errorf("Checker %s already registered", name)
+	}
+
+	checkers = append(checkers, checker)
+	return nil
+}
+
+/*
+ * Get the registered checker with the given name
+ * Args:
+ *   name (string): The name of the checker
+ * Returns:
+ *   Checker: The checker, nil if no checker with the name is registered
+ */
+func GetChecker(name string) Checker {
+	for _, checker := range checkers {
+		if checker.Name() == name {
+			return checker
+		}
+	}
+	return nil
+}
+
+/*
+ * Get all registered checkers
+ * Returns:
+ *   []Checker: The checkers in the order of registration
+ */
+func GetCheckers() []Checker {
+	return checkers
+}
+
+/*
+ * Get the registered checkers that are enabled in the analysis cases
+ * Returns:
+ *   []Checker: The enabled checkers
+ */
+func enabledCheckers() []Checker {
+	res := make([]Checker, 0)
+	for _, checker := range checkers {
+		if analysisCases[checker.Name()] {
+			res = append(res, checker)
+		}
+	}
+	return res
+}
+
+/*
+ * Call the method of the checkers for the type of the element
+ * Args:
+ *   active ([]Checker): The checkers to run
+ *   elem (TraceElement): The element that was processed
+ */
+func runCheckers(active []Checker, elem TraceElement) {
+	routine := elem.GetRoutine()
+	clocks := CheckerClocks{HB: currentVCHb[routine], WMHB: currentVCWmhb[routine]}
+
+	for _, checker := range active {
+		switch e := elem.(type) {
+		case *TraceElementAtomic:
+			checker.Atomic(e, clocks)
+		case *TraceElementChannel:
+			checker.Channel(e, clocks)
+		case *TraceElementSelect:
+			checker.Select(e, clocks)
+		case *TraceElementMutex:
+			checker.Mutex(e, clocks)
+		case *TraceElementWait:
+			checker.Wait(e, clocks)
+		case *TraceElementCond:
+			checker.Cond(e, clocks)
+		case *TraceElementOnce:
+			checker.Once(e, clocks)
+		case *TraceElementFork:
+			checker.Fork(e, clocks)
+		case *TraceElementNew:
+			checker.New(e, clocks)
+		case *TraceElementRoutineEnd:
+			checker.RoutineEnd(e, clocks)
+		}
+	}
+}
+
+/*
+ * Get the tasks to run the Finish methods of the checkers
+ * Args:
+ *   active ([]Checker): The checkers to run
+ * Returns:
+ *   []analysisTask: One task for each checker
+ */
+func finishCheckers(active []Checker) []analysisTask {
+	tasks := make([]analysisTask, 0, len(active))
+	for _, checker := range active {
+		tasks = append(tasks, analysisTask{"other", checker.Finish})
+	}
+	return tasks
+}
+
+/*
+ * Create the result element for an element of the trace, e.g. to report
+ * the element with results.Result
+ * Args:
+ *   elem (TraceElement): The element
+ * Returns:
+ *   results.TraceElementResult: The result element
+ *   error: If the position of the element could not be parsed
+ */
+func ResultElem(elem TraceElement) (results.TraceElementResult, error) {
+	file, line, tPre, err := infoFromTID(elem.GetTID())
+	if err != nil {
+		return results.TraceElementResult{}, err
+	}
+
+	return results.TraceElementResult{
+		RoutineID: elem.GetRoutine(),
+		ObjID:     elem.GetID(),
+		TPre:      tPre,
+		ObjType:   elem.GetObjType(),
+		File:      file,
+		Line:      line,
+	}, nil
+}
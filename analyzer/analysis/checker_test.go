@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: checker_test.go
+// Brief: Tests for checker.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-24
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"testing"
+)
+
+type testChecker struct {
+	CheckerBase
+	name     string
+	channels []int
+	forks    int
+	hb       clock.VectorClock
+}
+
+func (c *testChecker) Name() string {
+	return c.name
+}
+
+func (c *testChecker) Channel(e *TraceElementChannel, clocks CheckerClocks) {
+	c.channels = append(c.channels, e.GetTPre())
+	c.hb = clocks.HB
+}
+
+func (c *testChecker) Fork(e *TraceElementFork, clocks CheckerClocks) {
+	c.forks++
+}
+
+func TestRegisterChecker(t *testing.T) {
+	defer func() { checkers = make([]Checker, 0) }()
+	checkers = make([]Checker, 0)
+
+	var tests = []struct {
+		name    string
+		checker string
+		err     bool
+	}{
+		{"Valid", "first", false},
+		{"Second", "second", false},
+		{"Empty", "", true},
+		{"Duplicate", "first", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RegisterChecker(&testChecker{name: test.checker})
+			if test.err != (err != nil) {
+				t.Errorf("Incorrect error for %s. Expected error: %t. Got: %v",
+					test.checker, test.err, err)
+			}
+		})
+	}
+
+	if len(GetCheckers()) != 2 {
+		t.Errorf("Incorrect number of checkers. Expected 2. Got %d", len(GetCheckers()))
+	}
+
+	if GetChecker("second") == nil || GetChecker("third") != nil {
+		t.Errorf("Incorrect result of GetChecker")
+	}
+}
+
+func TestRunCheckers(t *testing.T) {
+	defer func() {
+		checkers = make([]Checker, 0)
+		analysisCases = make(map[string]bool)
+	}()
+	checkers = make([]Checker, 0)
+
+	enabled := &testChecker{name: "enabled"}
+	disabled := &testChecker{name: "disabled"}
+	_ = RegisterChecker(enabled)
+	_ = RegisterChecker(disabled)
+
+	analysisCases = map[string]bool{"enabled": true, "disabled": false}
+	active := enabledCheckers()
+	if len(active) != 1 || active[0] != enabled {
+		t.Fatalf("Incorrect enabled checkers: %v", active)
+	}
+
+	vc := clock.NewVectorClockSet(2, map[int]int{1: 1, 2: 3})
+	currentVCHb = map[int]clock.VectorClock{2: vc}
+	currentVCWmhb = map[int]clock.VectorClock{2: vc.Copy()}
+
+	runCheckers(active, &TraceElementChannel{routine: 2, tPre: 4, id: 1, opC: CloseOp})
+	runCheckers(active, &TraceElementFork{routine: 2, tPost: 5, id: 3})
+	runCheckers(active, &TraceElementChannel{routine: 2, tPre: 6, id: 1, opC: RecvOp})
+
+	if len(enabled.channels) != 2 || enabled.channels[0] != 4 || enabled.channels[1] != 6 {
+		t.Errorf("Incorrect channel calls: %v", enabled.channels)
+	}
+
+	if enabled.forks != 1 {
+		t.Errorf("Incorrect number of fork calls. Expected 1. Got %d", enabled.forks)
+	}
+
+	if enabled.hb.GetValue(2) != 3 {
+		t.Errorf("Incorrect vector clock: %s", enabled.hb.ToString())
+	}
+
+	if len(disabled.channels) != 0 || disabled.forks != 0 {
+		t.Errorf("Disabled checker was run")
+	}
+
+	if len(finishCheckers(active)) != 1 {
+		t.Errorf("Incorrect number of finish tasks")
+	}
+}
@@ -12,14 +12,19 @@ package analysis
 
 import (
 	"analyzer/clock"
+	"analyzer/memory"
+	"analyzer/results"
 	timemeasurement "analyzer/timeMeasurement"
 	"analyzer/utils"
+	"container/heap"
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 var (
@@ -38,6 +43,16 @@ var (
 	numberOfRoutines = 0
 	fifo             bool
 	result           string
+
+	// true if the analysis was stopped before all elements were analyzed
+	analysisIncomplete = false
+
+	// true if a scenario was stopped or skipped because the analysis was
+	// canceled. Set by the scenarios, that can run concurrently
+	scenarioCanceled atomic.Bool
+
+	// context of the current analysis, checked by long running scenarios
+	analysisContext = context.Background()
 )
 
 /*
@@ -51,12 +66,14 @@ var (
 func AddElementToTrace(element TraceElement) error {
 	routine := element.GetRoutine()
 	traces[routine] = append(traces[routine], element)
+	traceChanged()
 	return nil
 }
 
 func ClearTrace() {
 	traces = make(map[int][]TraceElement)
 	currentIndex = make(map[int]int)
+	traceChanged()
 }
 
 /*
@@ -98,6 +115,7 @@ func Sort() {
 	for routine, trace := range traces {
 		traces[routine] = sortTrace(trace)
 	}
+	traceChanged()
 }
 
 /*
@@ -134,14 +152,12 @@ func GetTraceElementFromTID(tID string) (*TraceElement, error) {
 		return nil, errors.New("tID is empty")
 	}
 
-	for routine, trace := range traces {
-		for index, elem := range trace {
-			if elem.GetTID() == tID {
-				return &traces[routine][index], nil
-			}
-		}
+	positions := mainView().findTID(tID)
+	if len(positions) == 0 {
+		return nil, errors.New("Element " + tID + " does not exist")
 	}
-	return nil, errors.New("Element " + tID + " does not exist")
+
+	return &traces[positions[0].routine][positions[0].index], nil
 }
 
 /*
@@ -174,81 +190,12 @@ func GetTraceElementFromBugArg(bugArg string) (TraceElement, error) {
 		return nil, errors.New("Could not parse tPre from bug argument: " + bugArg)
 	}
 
-	for index, elem := range traces[routine] {
-		if elem.GetTPre() == tPre {
-			return traces[routine][index], nil
-		}
-	}
-
-	for routine, trace := range traces {
-		for index, elem := range trace {
-			if elem.GetTPre() == tPre {
-				return traces[routine][index], nil
-			}
-		}
+	pos, ok := mainView().findTPre(routine, tPre)
+	if !ok {
+		return nil, errors.New("Element " + bugArg + " does not exist")
 	}
 
-	return nil, errors.New("Element " + bugArg + " does not exist")
-}
-
-/*
- * Shorten the trace by removing all elements after the given time
- * Args:
- *   time (int): The time to shorten the trace to
- *   incl (bool): True if an element with the same time should stay included in the trace
- */
-func ShortenTrace(time int, incl bool) {
-	for routine, trace := range traces {
-		for index, elem := range trace {
-			if incl && elem.GetTSort() > time {
-				traces[routine] = traces[routine][:index]
-				break
-			}
-			if !incl && elem.GetTSort() >= time {
-				traces[routine] = traces[routine][:index]
-				break
-			}
-		}
-	}
-}
-
-/*
- * Remove the element with the given tID from the trace
- * Args:
- *   tID (string): The tID of the element to remove
- */
-func RemoveElementFromTrace(tID string) {
-	for routine, trace := range traces {
-		for index, elem := range trace {
-			if elem.GetTID() == tID {
-				traces[routine] = append(traces[routine][:index], traces[routine][index+1:]...)
-				break
-			}
-		}
-	}
-}
-
-/*
- * Shorten the trace of the given routine by removing all elements after and equal the given time
- * Args:
- *   routine (int): The routine to shorten
- *   time (int): The time to shorten the trace to
- */
-func ShortenRoutine(routine int, time int) {
-	for index, elem := range traces[routine] {
-		if elem.GetTSort() >= time {
-			traces[routine] = traces[routine][:index]
-			break
-		}
-	}
-}
-
-func ShortenRoutineIndex(routine int, index int, incl bool) {
-	if incl {
-		traces[routine] = traces[routine][:index+1]
-	} else {
-		traces[routine] = traces[routine][:index]
-	}
+	return traces[pos.routine][pos.index], nil
 }
 
 /*
@@ -264,16 +211,20 @@ func SetNumberOfRoutines(n int) {
 * Calculate vector clocks
 * MARK: run analysis
 * Args:
-*   assume_fifo (bool): True to assume fifo ordering in buffered channels
+*   ctx (context.Context): If the context is canceled, e.g. by a timeout, the
+*     analysis stops after the current element or in the current scenario.
+*     All results found until then are kept
+*   assumeFifo (bool): True to assume fifo ordering in buffered channels
 *   ignoreCriticalSections (bool): True to ignore critical sections when updating
 *   	vector clocks
 *   analysisCasesMap (map[string]bool): The analysis cases to run
  */
-func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap map[string]bool) string {
+func RunAnalysis(ctx context.Context, assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap map[string]bool) string {
 
 	log.Print("Analyze the trace")
 
 	fifo = assumeFifo
+	analysisContext = ctx
 
 	analysisCases = analysisCasesMap
 	InitAnalysis(analysisCases)
@@ -286,7 +237,21 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 	currentVCHb[1] = currentVCHb[1].Inc(1)
 	currentVCWmhb[1] = currentVCWmhb[1].Inc(1)
 
-	for elem := getNextElement(); elem != nil; elem = getNextElement() {
+	activeCheckers := enabledCheckers()
+	for _, checker := range activeCheckers {
+		checker.Init()
+	}
+
+	for {
+		if stopAnalysis(ctx) {
+			return result
+		}
+
+		elem := getNextElement()
+		if elem == nil {
+			break
+		}
+
 		switch e := elem.(type) {
 		case *TraceElementAtomic:
 			if ignoreCriticalSections {
@@ -323,6 +288,10 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 			e.updateVectorClock()
 		case *TraceElementCond:
 			e.updateVectorClock()
+		case *TraceElementNew:
+			e.updateVectorClock()
+		case *TraceElementSyncEdge:
+			e.updateVectorClock()
 		}
 
 		// check for leak
@@ -361,26 +330,56 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 			timemeasurement.End("leak")
 		}
 
+		if len(activeCheckers) > 0 {
+			timemeasurement.Start("other")
+			runCheckers(activeCheckers, elem)
+			timemeasurement.End("other")
+		}
+	}
+
+	if stopAnalysis(ctx) {
+		return result
 	}
 
 	if analysisCases["selectWithoutPartner"] {
 		timemeasurement.Start("other")
 		rerunCheckForSelectCaseWithoutPartnerChannel()
-		CheckForSelectCaseWithoutPartner()
 		timemeasurement.End("other")
 	}
 
+	if stopAnalysis(ctx) {
+		return result
+	}
+
+	// the following scenarios are independent of each other and are run
+	// concurrently. The results are added in the order of the tasks
+	tasks := make([]analysisTask, 0)
+
+	if analysisCases["selectWithoutPartner"] {
+		tasks = append(tasks, analysisTask{"other", CheckForSelectCaseWithoutPartner})
+	}
+
 	if analysisCases["leak"] {
-		timemeasurement.Start("leak")
-		checkForLeak()
-		checkForStuckRoutine()
-		timemeasurement.End("leak")
+		tasks = append(tasks, analysisTask{"leak", func(res *results.Collector) {
+			checkForLeak(res)
+			checkForStuckRoutine(res)
+		}})
 	}
 
 	if analysisCases["doneBeforeAdd"] {
-		timemeasurement.Start("panic")
-		checkForDoneBeforeAdd()
-		timemeasurement.End("panic")
+		tasks = append(tasks, checkForDoneBeforeAdd()...)
+	}
+
+	if analysisCases["unlockBeforeLock"] {
+		tasks = append(tasks, checkForUnlockBeforeLock()...)
+	}
+
+	tasks = append(tasks, finishCheckers(activeCheckers)...)
+
+	runTasks(tasks)
+
+	if stopAnalysis(ctx) {
+		return result
 	}
 
 	if analysisCases["cyclicDeadlock"] {
@@ -389,274 +388,228 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 		timemeasurement.End("other")
 	}
 
-	if analysisCases["unlockBeforeLock"] {
-		timemeasurement.Start("panic")
-		checkForUnlockBeforeLock()
-		timemeasurement.End("panic")
-	}
-
 	log.Print("Finished analyzing trace")
 
 	return result
 }
 
 /*
- * Rerun the CheckForSelectCaseWithoutPartnerChannel for all channel. This
- * is needed to find potential communication partners for not executed
- * select cases, if the select was executed after the channel
+ * Check if the analysis must be stopped, either because the context was
+ * canceled or because not enough memory is available. Also applies the
+ * degradation steps for low memory.
+ * The function is only called between the processing of elements and
+ * between the scenarios, so that the results found so far are consistent.
+ * Args:
+ *   ctx (context.Context): The context of the analysis
+ * Returns:
+ *   bool: true if the analysis must be stopped
  */
-func rerunCheckForSelectCaseWithoutPartnerChannel() {
-	for _, trace := range traces {
-		for _, elem := range trace {
-			if e, ok := elem.(*TraceElementChannel); ok {
-				CheckForSelectCaseWithoutPartnerChannel(e, e.GetVC(),
-					e.Operation() == SendOp, e.IsBuffered())
-			}
-		}
+func stopAnalysis(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		log.Print("Analysis canceled: ", ctx.Err().Error())
+		analysisIncomplete = true
+		return true
+	default:
 	}
-}
 
-func getNextElement() TraceElement {
-	// find the local trace, where the element on which currentIndex points to
-	// has the smallest tpost
-	var minTSort = -1
-	var minRoutine = -1
-	for routine, trace := range traces {
-		// no more elements in the routine trace
-		if currentIndex[routine] == -1 {
-			continue
-		}
-		// ignore non executed operations
-		if trace[currentIndex[routine]].GetTSort() == 0 {
-			continue
-		}
-		if minTSort == -1 || trace[currentIndex[routine]].GetTSort() < minTSort {
-			minTSort = trace[currentIndex[routine]].GetTSort()
-			minRoutine = routine
-		}
+	if applyMemoryDegradation() {
+		log.Print("Analysis canceled because of low memory")
+		return true
 	}
 
-	// all elements have been processed
-	if minRoutine == -1 {
-		return nil
-	}
+	return false
+}
 
-	// return the element and increase the index
-	element := traces[minRoutine][currentIndex[minRoutine]]
-	increaseIndex(minRoutine)
+/*
+ * Check in a long running scenario if it must be stopped, because the
+ * analysis was canceled by the timeout or because of low memory.
+ * Can be called concurrently by scenarios that run in parallel.
+ * Returns:
+ *   bool: true if the scenario must be stopped
+ */
+func analysisCanceled() bool {
+	if analysisContext.Err() == nil && !memory.WasCanceled() {
+		return false
+	}
 
-	return element
+	scenarioCanceled.Store(true)
+	return true
 }
 
-func increaseIndex(routine int) {
-	currentIndex[routine]++
-	if currentIndex[routine] >= len(traces[routine]) {
-		currentIndex[routine] = -1
-	}
+/*
+ * Mark the analysis as incomplete, e.g. if the reading of the trace was
+ * canceled by the timeout
+ */
+func SetAnalysisIncomplete() {
+	analysisIncomplete = true
 }
 
 /*
- * For a given waitgroup id, get the number of add and done operations that were
- * executed before a given time.
- * Args:
- *   wgID (int): The id of the waitgroup
- *   waitTime (int): The time to check
+ * Check if the analysis was stopped before all elements were analyzed
+ * or before all scenarios finished
  * Returns:
- *   int: The number of add operations
- *   int: The number of done operations
+ *   bool: true if the analysis is incomplete
  */
-func GetNrAddDoneBeforeTime(wgID int, waitTime int) (int, int) {
-	nrAdd := 0
-	nrDone := 0
-
-	for _, trace := range traces {
-		for _, elem := range trace {
-			switch e := elem.(type) {
-			case *TraceElementWait:
-				if e.GetID() == wgID {
-					if e.GetTPre() < waitTime {
-						delta := e.GetDelta()
-						if delta > 0 {
-							nrAdd++
-						} else if delta < 0 {
-							nrDone++
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return nrAdd, nrDone
+func IsAnalysisIncomplete() bool {
+	return analysisIncomplete || scenarioCanceled.Load()
 }
 
-// MARK: Shift
-
 /*
- * Shift all elements with time greater or equal to startTSort by shift
- * Only shift forward
- * Args:
- *   startTPre (int): The time to start shifting
- *   shift (int): The shift
+ * Get the reason why the analysis is incomplete
+ * Returns:
+ *   string: results.IncompleteMemory or results.IncompleteTimeout, empty if
+ *     the analysis is complete
  */
-func ShiftTrace(startTPre int, shift int) bool {
-	if shift <= 0 {
-		return false
+func GetIncompleteReason() string {
+	if !IsAnalysisIncomplete() {
+		return ""
 	}
 
-	for routine, trace := range traces {
-		for index, elem := range trace {
-			if elem.GetTPre() >= startTPre {
-				traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
-			}
-		}
+	if memory.WasCanceled() {
+		return results.IncompleteMemory
 	}
-
-	return true
+	return results.IncompleteTimeout
 }
 
 /*
- * Shift all elements that are concurrent or HB-later than the element such
- * that they are after the element without changeing the order of these elements
- * Args:
- *   element (traceElement): The element
+ * Rerun the CheckForSelectCaseWithoutPartnerChannel for all channel. This
+ * is needed to find potential communication partners for not executed
+ * select cases, if the select was executed after the channel
  */
-func ShiftConcurrentOrAfterToAfter(element TraceElement) {
-	elemsToShift := make([]TraceElement, 0)
-	minTime := -1
-
+func rerunCheckForSelectCaseWithoutPartnerChannel() {
 	for _, trace := range traces {
-		for _, elem := range trace {
-			if elem.GetTID() == element.GetTID() {
-				continue
-			}
+		if analysisCanceled() {
+			return
+		}
 
-			if !(clock.GetHappensBefore(elem.GetVC(), element.GetVC()) == clock.Before) {
-				elemsToShift = append(elemsToShift, elem)
-				if minTime == -1 || elem.GetTPre() < minTime {
-					minTime = elem.GetTPre()
-				}
+		for _, elem := range trace {
+			if e, ok := elem.(*TraceElementChannel); ok {
+				CheckForSelectCaseWithoutPartnerChannel(e, e.GetVC(),
+					e.Operation() == SendOp, e.IsBuffered())
 			}
 		}
 	}
+}
 
-	distance := element.GetTPre() - minTime + 1
-
-	for _, elem := range elemsToShift {
-		tSort := elem.GetTPre()
-		elem.SetT(tSort + distance)
-	}
+/*
+ * Next element of a routine in the queue of the analysis
+ * Fields:
+ *   tSort (int): The tSort of the element
+ *   routine (int): The routine of the element
+ *   index (int): The index of the element in the routine trace
+ */
+type nextElement struct {
+	tSort   int
+	routine int
+	index   int
 }
 
 /*
- * Shift all elements that are concurrent or HB-later than the element such
- * that they are after the element without changeing the order of these elements
- * Only shift elements that are after start
- * Args:
- *   element (traceElement): The element
- *   start (traceElement): The time to start shifting (not including)
+ * Min heap of the next elements of all routines, ordered by tSort and
+ * routine. An entry is outdated if the index of the routine was advanced
+ * since the entry was added.
  */
-func ShiftConcurrentOrAfterToAfterStartingFromElement(element TraceElement, start int) {
-	elemsToShift := make([]TraceElement, 0)
-	minTime := -1
-	maxNotMoved := 0
+type nextElementQueue []nextElement
 
-	for _, trace := range traces {
-		for _, elem := range trace {
-			if elem.GetTID() == element.GetTID() {
-				continue
-			}
+func (q nextElementQueue) Len() int { return len(q) }
+func (q nextElementQueue) Less(i, j int) bool {
+	if q[i].tSort != q[j].tSort {
+		return q[i].tSort < q[j].tSort
+	}
+	return q[i].routine < q[j].routine
+}
+func (q nextElementQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *nextElementQueue) Push(x any)   { *q = append(*q, x.(nextElement)) }
+func (q *nextElementQueue) Pop() any {
+	old := *q
+	elem := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return elem
+}
 
-			if !(clock.GetHappensBefore(elem.GetVC(), element.GetVC()) == clock.Before) {
-				if elem.GetTPre() <= start {
-					continue
-				}
+var (
+	// queue of the next elements of all routines
+	nextElements nextElementQueue
 
-				elemsToShift = append(elemsToShift, elem)
-				if minTime == -1 || elem.GetTPre() < minTime {
-					minTime = elem.GetTPre()
-				}
-			} else {
-				if maxNotMoved == 0 || elem.GetTPre() > maxNotMoved {
-					maxNotMoved = elem.GetTPre()
-				}
-			}
-		}
-	}
+	// trace version the queue was build for
+	nextElementsVersion = -1
+)
 
-	if element.getTpost() == 0 {
-		element.SetT(maxNotMoved + 1)
+/*
+ * Add the element the index of the routine points to to the queue.
+ * Non executed operations are not added. They and all following elements
+ * of the routine are not analyzed.
+ * Args:
+ *   routine (int): The routine
+ */
+func pushNextElement(routine int) {
+	index := currentIndex[routine]
+	if index == -1 || index >= len(traces[routine]) {
+		return
 	}
 
-	distance := element.GetTPre() - minTime + 1
-
-	for _, elem := range elemsToShift {
-		tSort := elem.GetTPre()
-		elem.SetT(tSort + distance)
+	tSort := traces[routine][index].GetTSort()
+	if tSort == 0 {
+		return
 	}
 
+	heap.Push(&nextElements, nextElement{tSort, routine, index})
 }
 
 /*
- * Shift the element to be after all elements, that are concurrent to it
- * Args:
- *   element (traceElement): The element
+ * Build the queue of the next elements of all routines
  */
-func ShiftConcurrentToBefore(element TraceElement) {
-	ShiftConcurrentOrAfterToAfterStartingFromElement(element, 0)
+func buildNextElements() {
+	nextElements = make(nextElementQueue, 0, len(traces))
+	for routine := range traces {
+		pushNextElement(routine)
+	}
+	nextElementsVersion = traceVersion
 }
 
 /*
- * Remove all elements that are concurrent to the element and have time greater or equal to tmin
- * Args:
- *   element (traceElement): The element
+ * Get the next element to analyze, that is the element with the smallest
+ * tSort, that the index of its routine points to
+ * Returns:
+ *   TraceElement: The element, nil if all elements have been processed
  */
-func RemoveConcurrent(element TraceElement, tmin int) {
-	for routine, trace := range traces {
-		result := make([]TraceElement, 0)
-		for _, elem := range trace {
-			if elem.GetTSort() < tmin {
-				result = append(result, elem)
-				continue
-			}
+func getNextElement() TraceElement {
+	if nextElementsVersion != traceVersion {
+		buildNextElements()
+	}
 
-			if elem.GetTID() == element.GetTID() {
-				result = append(result, elem)
-				continue
-			}
+	for nextElements.Len() > 0 {
+		next := heap.Pop(&nextElements).(nextElement)
 
-			if clock.GetHappensBefore(elem.GetVC(), element.GetVC()) != clock.Concurrent {
-				result = append(result, elem)
-			}
+		// the routine was advanced since the element was added, e.g.
+		// because the element was the partner of an unbuffered channel
+		if currentIndex[next.routine] != next.index {
+			continue
 		}
-		traces[routine] = result
+
+		element := traces[next.routine][next.index]
+		increaseIndex(next.routine)
+		return element
 	}
+
+	// all elements have been processed
+	return nil
 }
 
 /*
- * Remove all elements that are concurrent to the element or must happen after the element
+ * Advance the index of a routine to its next element
  * Args:
- *   element (traceElement): The element
+ *   routine (int): The routine
  */
-func RemoveConcurrentOrAfter(element TraceElement, tmin int) {
-	for routine, trace := range traces {
-		result := make([]TraceElement, 0)
-		for _, elem := range trace {
-			if elem.GetTSort() < tmin {
-				result = append(result, elem)
-				continue
-			}
-
-			if elem.GetTID() == element.GetTID() {
-				result = append(result, elem)
-				continue
-			}
+func increaseIndex(routine int) {
+	currentIndex[routine]++
+	if currentIndex[routine] >= len(traces[routine]) {
+		currentIndex[routine] = -1
+	}
 
-			if clock.GetHappensBefore(elem.GetVC(), element.GetVC()) != clock.Before {
-				result = append(result, elem)
-			}
-		}
-		traces[routine] = result
+	if nextElementsVersion == traceVersion {
+		pushNextElement(routine)
 	}
 }
 
@@ -683,31 +636,6 @@ func GetConcurrentEarliest(element *TraceElement) map[int]*TraceElement {
 	return concurrent
 }
 
-/*
- * Shift all elements with time greater or equal to startTSort by shift
- * Only shift back
- * Args:
- *   routine (int): The routine to shift
- *   startTSort (int): The time to start shifting
- *   shift (int): The shift
- * Returns:
- *   bool: True if the shift was successful, false otherwise (shift <= 0)
- * TODO: is this allowed or will it create problems?
- */
-func ShiftRoutine(routine int, startTSort int, shift int) bool {
-	if shift <= 0 {
-		return false
-	}
-
-	for index, elem := range traces[routine] {
-		if elem.GetTPre() >= startTSort {
-			traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
-		}
-	}
-
-	return true
-}
-
 /*
  * Get the partial trace of all element between startTime and endTime incluseve.
  * Args:
@@ -775,6 +703,7 @@ func copyTraceRoutine(trace []TraceElement) []TraceElement {
 func SetTrace(trace map[int][]TraceElement) {
 	traces = make(map[int][]TraceElement)
 	traces = CopyTrace(trace)
+	traceChanged()
 }
 
 /*
@@ -169,6 +169,15 @@ func (at *TraceElementAtomic) GetVC() clock.VectorClock {
 	return at.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (at *TraceElementAtomic) setVC(vc clock.VectorClock) {
+	at.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
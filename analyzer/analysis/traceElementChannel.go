@@ -261,6 +261,15 @@ func (ch *TraceElementChannel) GetVC() clock.VectorClock {
 	return ch.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (ch *TraceElementChannel) setVC(vc clock.VectorClock) {
+	ch.vc = vc
+}
+
 /*
  * Get the tpost of the element
  * Returns:
@@ -565,14 +574,15 @@ func (ch *TraceElementChannel) findPartner() int {
 		return -1
 	}
 
-	for routine, trace := range traces {
-		if currentIndex[routine] == -1 {
+	// the partner is the next element of its routine
+	view := mainView()
+	for _, pos := range view.getChannelOp(ch.id, ch.oID) {
+		if !view.validPos(pos) || currentIndex[pos.routine] != pos.index {
 			continue
 		}
-		// if routine == ch.routine {
-		// 	continue
-		// }
-		elem := trace[currentIndex[routine]]
+
+		routine := pos.routine
+		elem := traces[routine][pos.index]
 
 		if elem.ToString() == ch.ToString() {
 			continue
@@ -593,10 +603,9 @@ func (ch *TraceElementChannel) findPartner() int {
 				e.chosenCase.partner = ch
 				return routine
 			}
-		default:
-			continue
 		}
 	}
+
 	return -1
 }
 
@@ -187,6 +187,15 @@ func (co *TraceElementCond) GetVC() clock.VectorClock {
 	return co.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (co *TraceElementCond) setVC(vc clock.VectorClock) {
+	co.vc = vc
+}
+
 /*
  * Get all to element concurrent wait, broadcast and signal operations on the same condition variable
  * Args:
@@ -195,12 +204,12 @@ func (co *TraceElementCond) GetVC() clock.VectorClock {
  * Returns:
  *   []*traceElement: The concurrent elements
  */
-func GetConcurrentWaitgroups(element TraceElement) map[string][]TraceElement {
+func (v *TraceView) GetConcurrentWaitgroups(element TraceElement) map[string][]TraceElement {
 	res := make(map[string][]TraceElement)
 	res["broadcast"] = make([]TraceElement, 0)
 	res["signal"] = make([]TraceElement, 0)
 	res["wait"] = make([]TraceElement, 0)
-	for _, trace := range traces {
+	for _, trace := range v.traces {
 		for _, elem := range trace {
 			switch elem.(type) {
 			case *TraceElementCond:
@@ -136,6 +136,15 @@ func (fo *TraceElementFork) GetVC() clock.VectorClock {
 	return fo.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (fo *TraceElementFork) setVC(vc clock.VectorClock) {
+	fo.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
@@ -227,6 +227,15 @@ func (mu *TraceElementMutex) GetVC() clock.VectorClock {
 	return mu.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (mu *TraceElementMutex) setVC(vc clock.VectorClock) {
+	mu.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
@@ -0,0 +1,327 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: traceElementNew.go
+// Brief: Struct and functions for the creation of objects in the trace
+//
+// Author: Erik Kassubek
+// Created: 2024-11-18
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/results"
+	"analyzer/utils"
+	"errors"
+	"strconv"
+)
+
+// enum for the object type
+type ObjectType string
+
+const (
+	ObjChannel   ObjectType = "C"
+	ObjMutex     ObjectType = "M"
+	ObjRWMutex   ObjectType = "R"
+	ObjWaitGroup ObjectType = "W"
+	ObjOnce      ObjectType = "O"
+	ObjCond      ObjectType = "N"
+)
+
+// creation of all objects
+var objectCreations = make(map[int]*TraceElementNew) // id -> creation
+
+/*
+* TraceElementNew is a trace element for the creation of a channel or the
+* first use of a sync object
+* MARK: Struct
+* Fields:
+*   routine (int): The routine id of the creating routine
+*   tPost (int): The timestamp of the event
+*   id (int): The id of the created object
+*   objType (ObjectType): The type of the object
+*   elemType (string): The element type of the object, e.g. *Request for chan *Request
+*   qSize (int): The capacity of a channel, 0 for all other objects
+*   pos (string): The position of the creation in the code
+ */
+type TraceElementNew struct {
+	routine  int
+	tPost    int
+	id       int
+	objType  ObjectType
+	elemType string
+	qSize    int
+	pos      string
+	vc       clock.VectorClock
+}
+
+/*
+ * Create a new creation trace element
+ * MARK: New
+ * Args:
+ *   routine (int): The routine id
+ *   tPost (string): The timestamp of the event
+ *   id (string): The id of the new object
+ *   objType (string): The type of the object
+ *   elemType (string): The element type of the object, escaped with utils.EscapeField
+ *   qSize (string): The capacity of the channel
+ *   pos (string): The position of the trace element in the file
+ */
+func AddTraceElementNew(routine int, tPost string, id string, objType string,
+	elemType string, qSize string, pos string) error {
+	tPostInt, err := strconv.Atoi(tPost)
+	if err != nil {
+		return errors.New("tPost is not an integer")
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.New("id is not an integer")
+	}
+
+	qSizeInt, err := strconv.Atoi(qSize)
+	if err != nil {
+		return errors.New("qSize is not an integer")
+	}
+
+	var objTypeVal ObjectType
+	switch objType {
+	case "C", "M", "R", "W", "O", "N":
+		objTypeVal = ObjectType(objType)
+	default:
+		return errors.New("objType is not a valid object type")
+	}
+
+	elemTypeVal, err := utils.UnescapeField(elemType)
+	if err != nil {
+		return errors.New("elemType is not correctly escaped")
+	}
+
+	elem := TraceElementNew{
+		routine:  routine,
+		tPost:    tPostInt,
+		id:       idInt,
+		objType:  objTypeVal,
+		elemType: elemTypeVal,
+		qSize:    qSizeInt,
+		pos:      pos,
+	}
+
+	objectCreations[idInt] = &elem
+	results.SetObjectInfo(idInt, elem.Description())
+
+	return AddElementToTrace(&elem)
+}
+
+/*
+ * Get the creation element of an object
+ * Args:
+ *   id (int): The id of the object
+ * Returns:
+ *   *TraceElementNew: The creation, nil if the creation was not recorded
+ */
+func GetObjectCreation(id int) *TraceElementNew {
+	return objectCreations[id]
+}
+
+// MARK: Getter
+
+/*
+ * Get the id of the element
+ * Returns:
+ *   int: The id of the element
+ */
+func (n *TraceElementNew) GetID() int {
+	return n.id
+}
+
+/*
+ * Get the routine of the element
+ * Returns:
+ *   int: The routine of the element
+ */
+func (n *TraceElementNew) GetRoutine() int {
+	return n.routine
+}
+
+/*
+ * Get the tpre of the element. For creations, tpre and tpost are the same
+ * Returns:
+ *   int: The tpre of the element
+ */
+func (n *TraceElementNew) GetTPre() int {
+	return n.tPost
+}
+
+/*
+ * Get the tpost of the element. For creations, tpre and tpost are the same
+ * Returns:
+ *   int: The tpost of the element
+ */
+func (n *TraceElementNew) getTpost() int {
+	return n.tPost
+}
+
+/*
+ * Get the timer, that is used for the sorting of the trace
+ * Returns:
+ *   int: The timer of the element
+ */
+func (n *TraceElementNew) GetTSort() int {
+	return n.tPost
+}
+
+/*
+ * Get the position of the operation.
+ * Returns:
+ *   string: The position of the element
+ */
+func (n *TraceElementNew) GetPos() string {
+	return n.pos
+}
+
+/*
+ * Get the tID of the element.
+ * Returns:
+ *   string: The tID of the element
+ */
+func (n *TraceElementNew) GetTID() string {
+	return n.pos + "@" + strconv.Itoa(n.tPost)
+}
+
+/*
+ * Get the vector clock of the element
+ * Returns:
+ *   VectorClock: The vector clock of the element
+ */
+func (n *TraceElementNew) GetVC() clock.VectorClock {
+	return n.vc
+}
+
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (n *TraceElementNew) setVC(vc clock.VectorClock) {
+	n.vc = vc
+}
+
+/*
+ * Get the string representation of the object type
+ */
+func (n *TraceElementNew) GetObjType() string {
+	return "I" + string(n.objType)
+}
+
+/*
+ * Get the element type of the created object
+ * Returns:
+ *   string: The element type
+ */
+func (n *TraceElementNew) GetElemType() string {
+	return n.elemType
+}
+
+/*
+ * Get the capacity of the created channel
+ * Returns:
+ *   int: The capacity, 0 for all other objects
+ */
+func (n *TraceElementNew) GetQSize() int {
+	return n.qSize
+}
+
+/*
+ * Get a human readable description of the created object, e.g.
+ * channel created at server.go:88 (chan *Request, cap 0)
+ * Returns:
+ *   string: The description
+ */
+func (n *TraceElementNew) Description() string {
+	return results.ObjectDescription(string(n.objType), n.elemType, n.qSize, n.pos)
+}
+
+// MARK: Setter
+
+/*
+ * Set the tPre and tPost of the element
+ * Args:
+ *   time (int): The tPre and tPost of the element
+ */
+func (n *TraceElementNew) SetT(time int) {
+	n.tPost = time
+}
+
+/*
+ * Set the tpre of the element.
+ * Args:
+ *   tPre (int): The tpre of the element
+ */
+func (n *TraceElementNew) SetTPre(tPre int) {
+	n.tPost = tPre
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (n *TraceElementNew) SetTSort(tpost int) {
+	n.SetTPre(tpost)
+	n.tPost = tpost
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace, only if the original
+ * value was not 0
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (n *TraceElementNew) SetTWithoutNotExecuted(tSort int) {
+	n.SetTPre(tSort)
+	if n.tPost != 0 {
+		n.tPost = tSort
+	}
+}
+
+/*
+ * Get the simple string representation of the element
+ * MARK: ToString
+ * Returns:
+ *   string: The simple string representation of the element
+ */
+func (n *TraceElementNew) ToString() string {
+	return "I," + strconv.Itoa(n.tPost) + "," + strconv.Itoa(n.id) + "," +
+		string(n.objType) + "," + utils.EscapeField(n.elemType) + "," + strconv.Itoa(n.qSize) +
+		"," + n.pos
+}
+
+/*
+ * Update and calculate the vector clock of the element.
+ * The creation of an object does not create a happens before relation.
+ * MARK: VectorClock
+ */
+func (n *TraceElementNew) updateVectorClock() {
+	n.vc = currentVCHb[n.routine].Copy()
+}
+
+/*
+ * Copy the element
+ * Returns:
+ *   TraceElement: The copy of the element
+ */
+func (n *TraceElementNew) Copy() TraceElement {
+	return &TraceElementNew{
+		routine:  n.routine,
+		tPost:    n.tPost,
+		id:       n.id,
+		objType:  n.objType,
+		elemType: n.elemType,
+		qSize:    n.qSize,
+		pos:      n.pos,
+		vc:       n.vc.Copy(),
+	}
+}
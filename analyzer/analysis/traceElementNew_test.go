@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: traceElementNew_test.go
+// Brief: Tests for traceElementNew
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/utils"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTraceElementNewNew(t *testing.T) {
+	var tests = []struct {
+		name        string
+		routine     int
+		tPost       string
+		id          string
+		objType     string
+		elemType    string
+		qSize       string
+		position    string
+		expObjType  ObjectType
+		expElemType string
+		expQSize    int
+		expError    error
+	}{
+		{"Valid channel", 1, "12", "3", "C", "*main.Request", "2", "server.go:88", ObjChannel, "*main.Request", 2, nil},
+		{"Valid mutex", 1, "13", "4", "M", "sync.Mutex", "0", "server.go:90", ObjMutex, "sync.Mutex", 0, nil},
+		{"Escaped type", 1, "14", "5", "C", "func(int%2C int)", "0", "server.go:92", ObjChannel, "func(int, int)", 0, nil},
+		{"Invalid tPost", 1, "ABC", "5", "C", "int", "0", "server.go:92", ObjChannel, "", 0, errors.New("tPost is not an integer")},
+		{"Invalid id", 1, "14", "ABC", "C", "int", "0", "server.go:92", ObjChannel, "", 0, errors.New("id is not an integer")},
+		{"Invalid qSize", 1, "14", "5", "C", "int", "ABC", "server.go:92", ObjChannel, "", 0, errors.New("qSize is not an integer")},
+		{"Invalid object type", 1, "14", "5", "Q", "int", "0", "server.go:92", ObjChannel, "", 0, errors.New("objType is not a valid object type")},
+		{"Invalid escape", 1, "14", "5", "C", "int%2", "0", "server.go:92", ObjChannel, "", 0, errors.New("elemType is not correctly escaped")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := AddTraceElementNew(test.routine, test.tPost, test.id, test.objType,
+				test.elemType, test.qSize, test.position)
+
+			if res := utils.GetErrorDiff(test.expError, err); res != nil {
+				t.Errorf(res.Error())
+			}
+
+			if err != nil {
+				return
+			}
+
+			trace := GetTraceFromId(test.routine)
+			elem := trace[len(trace)-1].(*TraceElementNew)
+
+			if elem.objType != test.expObjType {
+				t.Errorf("Incorrect object type. Expected %s. Got %s.", test.expObjType, elem.objType)
+			}
+
+			if elem.GetElemType() != test.expElemType {
+				t.Errorf("Incorrect element type. Expected %s. Got %s.", test.expElemType, elem.GetElemType())
+			}
+
+			if elem.GetQSize() != test.expQSize {
+				t.Errorf("Incorrect qSize. Expected %d. Got %d.", test.expQSize, elem.GetQSize())
+			}
+
+			if GetObjectCreation(elem.GetID()) != elem {
+				t.Errorf("Creation of object %d not stored", elem.GetID())
+			}
+		})
+	}
+}
+
+func TestTraceElementNewToString(t *testing.T) {
+	var tests = []struct {
+		name     string
+		elemType string
+		expected string
+	}{
+		{"Simple type", "*main.Request", "I,12,3,C,*main.Request,2,server.go:88"},
+		{"Type with comma", "func(int, int)", "I,12,3,C,func(int%2C int),2,server.go:88"},
+		{"Type with semicolon and percent", "a;b%", "I,12,3,C,a%3Bb%25,2,server.go:88"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			elem := TraceElementNew{routine: 1, tPost: 12, id: 3, objType: ObjChannel,
+				elemType: test.elemType, qSize: 2, pos: "server.go:88"}
+
+			res := elem.ToString()
+			if res != test.expected {
+				t.Errorf("Incorrect string. Expected %s. Got %s.", test.expected, res)
+			}
+
+			// reading the string must result in the same element type
+			fields := strings.Split(res, ",")
+			if len(fields) != 7 {
+				t.Fatalf("Incorrect number of fields in %s", res)
+			}
+
+			err := AddTraceElementNew(1, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6])
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+
+			if GetObjectCreation(3).GetElemType() != test.elemType {
+				t.Errorf("Incorrect element type after reading. Expected %s. Got %s.",
+					test.elemType, GetObjectCreation(3).GetElemType())
+			}
+		})
+	}
+}
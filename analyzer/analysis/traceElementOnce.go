@@ -162,6 +162,15 @@ func (on *TraceElementOnce) GetVC() clock.VectorClock {
 	return on.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (on *TraceElementOnce) setVC(vc clock.VectorClock) {
+	on.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
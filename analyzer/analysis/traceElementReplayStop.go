@@ -30,21 +30,22 @@ type TraceElementReplay struct {
 }
 
 /*
- * Create a new atomic trace element
+ * Create a new replay trace element and add it to the view
  * MARK: New
  * Args:
  *   t (string): The timestamp of the event
  *   exitCode (int): The exit code of the event
  *   lastElemT (int): TPre of the
  */
-func AddTraceElementReplay(t int, exitCode int, lastElemTPre int) error {
+func (v *TraceView) AddTraceElementReplay(t int, exitCode int, lastElemTPre int) error {
 	elem := TraceElementReplay{
 		tPost:        t,
 		exitCode:     exitCode,
 		lastElemTPre: lastElemTPre,
 	}
 
-	return AddElementToTrace(&elem)
+	v.AddElement(&elem)
+	return nil
 }
 
 // MARK: Getter
@@ -120,6 +121,15 @@ func (at *TraceElementReplay) GetVC() clock.VectorClock {
 	return clock.VectorClock{}
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (at *TraceElementReplay) setVC(vc clock.VectorClock) {
+	// the element has no vector clock
+}
+
 /*
  * Get the string representation of the object type
  */
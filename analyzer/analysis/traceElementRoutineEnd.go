@@ -125,6 +125,15 @@ func (fo *TraceElementRoutineEnd) GetVC() clock.VectorClock {
 	return fo.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (fo *TraceElementRoutineEnd) setVC(vc clock.VectorClock) {
+	fo.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
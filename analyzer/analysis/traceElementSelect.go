@@ -282,6 +282,15 @@ func (se *TraceElementSelect) GetVC() clock.VectorClock {
 	return se.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (se *TraceElementSelect) setVC(vc clock.VectorClock) {
+	se.vc = vc
+}
+
 /*
  * Get the communication partner of the select
  * Returns:
@@ -602,7 +611,7 @@ func (se *TraceElementSelect) Copy() TraceElement {
 
 	chosenCase := *se.chosenCase.Copy().(*TraceElementChannel)
 
-	return &TraceElementSelect{
+	newSe := TraceElementSelect{
 		routine:         se.routine,
 		tPre:            se.tPre,
 		tPost:           se.tPost,
@@ -615,4 +624,13 @@ func (se *TraceElementSelect) Copy() TraceElement {
 		pos:             se.pos,
 		vc:              se.vc.Copy(),
 	}
+
+	// the cases of the copy must refer to the copy, otherwise changes to
+	// the cases would change the original select
+	for i := range newSe.cases {
+		newSe.cases[i].sel = &newSe
+	}
+	newSe.chosenCase.sel = &newSe
+
+	return &newSe
 }
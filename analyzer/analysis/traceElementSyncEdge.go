@@ -0,0 +1,327 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: traceElementSyncEdge.go
+// Brief: Struct and functions for synthetic sync edges in the trace
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// enum for opH
+type opSyncEdge int
+
+const (
+	ReleaseOp opSyncEdge = iota
+	AcquireOp
+	ReadReleaseOp
+	ReadAcquireOp
+)
+
+/*
+* TraceElementSyncEdge is a synthetic trace element, that replaces operations
+* that have been filtered out of the trace or that is created by a happens
+* before annotation of the user (advocate.HappensBefore, ...). It does not
+* represent an operation itself, but keeps the happens before relation
+* created by the filtered or annotated operation.
+* MARK: Struct
+* Fields:
+*   routine (int): The routine id
+*   tPre (int): The timestamp at the start of the event
+*   tPost (int): The timestamp at the end of the event, 0 if the acquire never finished
+*   id (int): The id of the object the edge is created over
+*   opH (opSyncEdge): Release or acquire, for annotated rw mutexes also read release or read acquire
+*   pos (string): The position, empty for summarized operations
+ */
+type TraceElementSyncEdge struct {
+	routine int
+	tPre    int
+	tPost   int
+	id      int
+	opH     opSyncEdge
+	pos     string
+	vc      clock.VectorClock
+}
+
+/*
+ * Create a new sync edge trace element
+ * MARK: New
+ * Args:
+ *   routine (int): The routine id
+ *   tPre (string): The timestamp at the start of the event
+ *   tPost (string): The timestamp at the end of the event
+ *   id (string): The id of the object
+ *   opH (string): The operation, R for release, A for acquire, RR for read release, RA for read acquire
+ *   pos (string): The position, can be empty
+ */
+func AddTraceElementSyncEdge(routine int, tPre string, tPost string, id string,
+	opH string, pos string) error {
+	tPreInt, err := strconv.Atoi(tPre)
+	if err != nil {
+		return errors.New("tPre is not an integer")
+	}
+
+	tPostInt, err := strconv.Atoi(tPost)
+	if err != nil {
+		return errors.New("tPost is not an integer")
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.New("id is not an integer")
+	}
+
+	var op opSyncEdge
+	switch opH {
+	case "R":
+		op = ReleaseOp
+	case "A":
+		op = AcquireOp
+	case "RR":
+		op = ReadReleaseOp
+	case "RA":
+		op = ReadAcquireOp
+	default:
+		return errors.New("op is not a valid operation")
+	}
+
+	elem := TraceElementSyncEdge{
+		routine: routine,
+		tPre:    tPreInt,
+		tPost:   tPostInt,
+		id:      idInt,
+		opH:     op,
+		pos:     pos,
+	}
+
+	return AddElementToTrace(&elem)
+}
+
+// MARK: Getter
+
+/*
+ * Get the id of the element
+ * Returns:
+ *   int: The id of the element
+ */
+func (h *TraceElementSyncEdge) GetID() int {
+	return h.id
+}
+
+/*
+ * Get the routine of the element
+ * Returns:
+ *   int: The routine of the element
+ */
+func (h *TraceElementSyncEdge) GetRoutine() int {
+	return h.routine
+}
+
+/*
+ * Get the timestamp at the start of the event
+ * Returns:
+ *   int: The timestamp at the start of the event
+ */
+func (h *TraceElementSyncEdge) GetTPre() int {
+	return h.tPre
+}
+
+/*
+ * Get the timestamp at the end of the event
+ * Returns:
+ *   int: The timestamp at the end of the event
+ */
+func (h *TraceElementSyncEdge) getTpost() int {
+	return h.tPost
+}
+
+/*
+ * Get the timer, that is used for the sorting of the trace
+ * Returns:
+ *   int: The timer of the element
+ */
+func (h *TraceElementSyncEdge) GetTSort() int {
+	if h.tPost == 0 {
+		// add at the end of the trace
+		return math.MaxInt
+	}
+	return h.tPost
+}
+
+/*
+ * Get the position of the operation.
+ * Returns:
+ *   string: The position of the element
+ */
+func (h *TraceElementSyncEdge) GetPos() string {
+	return h.pos
+}
+
+/*
+ * Get the tID of the element.
+ * Returns:
+ *   string: The tID of the element
+ */
+func (h *TraceElementSyncEdge) GetTID() string {
+	return h.pos + "@" + strconv.Itoa(h.tPre)
+}
+
+/*
+ * Get if the edge is a release
+ * Returns:
+ *   bool: true for a (read) release, false for a (read) acquire
+ */
+func (h *TraceElementSyncEdge) IsRelease() bool {
+	return h.opH == ReleaseOp || h.opH == ReadReleaseOp
+}
+
+/*
+ * Get the vector clock of the element
+ * Returns:
+ *   VectorClock: The vector clock of the element
+ */
+func (h *TraceElementSyncEdge) GetVC() clock.VectorClock {
+	return h.vc
+}
+
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (h *TraceElementSyncEdge) setVC(vc clock.VectorClock) {
+	h.vc = vc
+}
+
+/*
+ * Get the string representation of the object type
+ */
+func (h *TraceElementSyncEdge) GetObjType() string {
+	return "H" + h.opString()
+}
+
+/*
+ * Get the string representation of the operation
+ * Returns:
+ *   string: R, A, RR or RA
+ */
+func (h *TraceElementSyncEdge) opString() string {
+	switch h.opH {
+	case ReleaseOp:
+		return "R"
+	case AcquireOp:
+		return "A"
+	case ReadReleaseOp:
+		return "RR"
+	default:
+		return "RA"
+	}
+}
+
+// MARK: Setter
+
+/*
+ * Set the tPre and tPost of the element
+ * Args:
+ *   time (int): The tPre and tPost of the element
+ */
+func (h *TraceElementSyncEdge) SetT(time int) {
+	h.tPre = time
+	h.tPost = time
+}
+
+/*
+ * Set the tpre of the element.
+ * Args:
+ *   tPre (int): The tpre of the element
+ */
+func (h *TraceElementSyncEdge) SetTPre(tPre int) {
+	h.tPre = tPre
+	if h.tPost != 0 && h.tPost < tPre {
+		h.tPost = tPre
+	}
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (h *TraceElementSyncEdge) SetTSort(tSort int) {
+	h.SetTPre(tSort)
+	h.tPost = tSort
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace, only if the original
+ * value was not 0
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (h *TraceElementSyncEdge) SetTWithoutNotExecuted(tSort int) {
+	h.SetTPre(tSort)
+	if h.tPost != 0 {
+		h.tPost = tSort
+	}
+}
+
+/*
+ * Get the simple string representation of the element
+ * MARK: ToString
+ * Returns:
+ *   string: The simple string representation of the element
+ */
+func (h *TraceElementSyncEdge) ToString() string {
+	return "H," + strconv.Itoa(h.tPre) + "," + strconv.Itoa(h.tPost) + "," +
+		strconv.Itoa(h.id) + "," + h.opString() + "," + h.pos
+}
+
+/*
+ * Update and calculate the vector clock of the element
+ * MARK: VectorClock
+ */
+func (h *TraceElementSyncEdge) updateVectorClock() {
+	h.vc = currentVCHb[h.routine].Copy()
+
+	// acquire that never finished
+	if h.tPost == 0 {
+		return
+	}
+
+	switch h.opH {
+	case ReleaseOp:
+		Release(h, currentVCHb)
+	case AcquireOp:
+		Acquire(h, currentVCHb)
+	case ReadReleaseOp:
+		ReadRelease(h, currentVCHb)
+	case ReadAcquireOp:
+		ReadAcquire(h, currentVCHb)
+	}
+}
+
+/*
+ * Copy the element
+ * Returns:
+ *   TraceElement: The copy of the element
+ */
+func (h *TraceElementSyncEdge) Copy() TraceElement {
+	return &TraceElementSyncEdge{
+		routine: h.routine,
+		tPre:    h.tPre,
+		tPost:   h.tPost,
+		id:      h.id,
+		opH:     h.opH,
+		pos:     h.pos,
+		vc:      h.vc.Copy(),
+	}
+}
@@ -204,6 +204,15 @@ func (wa *TraceElementWait) GetVC() clock.VectorClock {
 	return wa.vc
 }
 
+/*
+ * Set the vector clock of the element
+ * Args:
+ *   vc (VectorClock): The vector clock
+ */
+func (wa *TraceElementWait) setVC(vc clock.VectorClock) {
+	wa.vc = vc
+}
+
 /*
  * Get the string representation of the object type
  */
@@ -29,5 +29,6 @@ type TraceElement interface {
 	ToString() string
 	updateVectorClock()
 	GetVC() clock.VectorClock
+	setVC(vc clock.VectorClock)
 	Copy() TraceElement
 }
@@ -0,0 +1,339 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: traceIndex.go
+// Brief: Indexes into the trace to avoid scanning all elements for lookups
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package analysis
+
+/*
+ * Position of an element in the trace
+ * Fields:
+ *   routine (int): The routine of the element
+ *   index (int): The index of the element in the routine trace
+ */
+type tracePos struct {
+	routine int
+	index   int
+}
+
+/*
+ * Indexes into the trace
+ * Fields:
+ *   version (int): The trace version the index was build for
+ *   tID (map[string][]tracePos): tID -> positions of elements with this tID
+ *   routineTPre (map[int]map[int]int): routine -> tPre -> index of the first element
+ *   tPre (map[int]tracePos): tPre -> position of an element with this tPre
+ *   object (map[int][]tracePos): object id -> positions of the elements on this object
+ *   channelOp (map[chanOpKey][]tracePos): channel operation -> positions of
+ *     the channel elements and selects with this operation as chosen case
+ */
+type traceIndex struct {
+	version     int
+	tID         map[string][]tracePos
+	routineTPre map[int]map[int]int
+	tPre        map[int]tracePos
+	object      map[int][]tracePos
+	channelOp   map[chanOpKey][]tracePos
+}
+
+/*
+ * Key of a channel operation. The send and the receive of the same
+ * message have the same key.
+ * Fields:
+ *   id (int): The id of the channel
+ *   oID (int): The operation id of the message
+ */
+type chanOpKey struct {
+	id  int
+	oID int
+}
+
+var (
+	// version of the trace, increased each time elements are added, removed
+	// or reordered. The index is only valid for the version it was build for
+	traceVersion = 0
+
+	// view used for lookups in the trace of the analysis
+	mainTrace = TraceView{index: traceIndex{version: -1}}
+)
+
+/*
+ * Mark the trace as changed. The index will be rebuild the next time it is used.
+ * Must be called by every function that adds, removes or reorders elements
+ * in traces, or changes the tPre of elements without updating the index.
+ */
+func traceChanged() {
+	traceVersion++
+}
+
+/*
+ * Get the view used for lookups in the trace of the analysis. The view
+ * must not be changed, changes to the trace are made on traces directly.
+ * Returns:
+ *   *TraceView: The view on traces
+ */
+func mainView() *TraceView {
+	mainTrace.traces = traces
+	mainTrace.version = traceVersion
+	return &mainTrace
+}
+
+/*
+ * Get the index for the trace of the view, build it if the trace has changed
+ * Returns:
+ *   *traceIndex: The index
+ *   bool: true if the index was (re)build by this call
+ */
+func (v *TraceView) getTraceIndex() (*traceIndex, bool) {
+	if v.index.version == v.version {
+		return &v.index, false
+	}
+
+	v.buildTraceIndex()
+	return &v.index, true
+}
+
+/*
+ * Build the index for the trace of the view
+ */
+func (v *TraceView) buildTraceIndex() {
+	v.index = traceIndex{
+		version:     v.version,
+		tID:         make(map[string][]tracePos),
+		routineTPre: make(map[int]map[int]int),
+		tPre:        make(map[int]tracePos),
+		object:      make(map[int][]tracePos),
+		channelOp:   make(map[chanOpKey][]tracePos),
+	}
+
+	for routine, trace := range v.traces {
+		v.index.routineTPre[routine] = make(map[int]int)
+		for index, elem := range trace {
+			pos := tracePos{routine, index}
+			v.index.add(pos, elem.GetTID(), elem.GetTPre())
+
+			id := elem.GetID()
+			v.index.object[id] = append(v.index.object[id], pos)
+
+			var key chanOpKey
+			switch e := elem.(type) {
+			case *TraceElementChannel:
+				key = chanOpKey{e.id, e.oID}
+			case *TraceElementSelect:
+				key = chanOpKey{e.chosenCase.id, e.chosenCase.oID}
+			default:
+				continue
+			}
+			v.index.channelOp[key] = append(v.index.channelOp[key], pos)
+		}
+	}
+}
+
+/*
+ * Add the tID and tPre of an element to the index
+ * Args:
+ *   pos (tracePos): The position of the element
+ *   tID (string): The tID of the element
+ *   tPre (int): The tPre of the element
+ */
+func (ti *traceIndex) add(pos tracePos, tID string, tPre int) {
+	ti.tID[tID] = append(ti.tID[tID], pos)
+
+	if _, ok := ti.routineTPre[pos.routine]; !ok {
+		ti.routineTPre[pos.routine] = make(map[int]int)
+	}
+	if index, ok := ti.routineTPre[pos.routine][tPre]; !ok || pos.index < index {
+		ti.routineTPre[pos.routine][tPre] = pos.index
+	}
+
+	if _, ok := ti.tPre[tPre]; !ok {
+		ti.tPre[tPre] = pos
+	}
+}
+
+/*
+ * Update the index after the tPre, and therefore the tID, of an element
+ * was changed. The position of the element in the trace must not have
+ * changed. If the index is not up to date, nothing is done.
+ * Args:
+ *   pos (tracePos): The position of the element
+ *   oldTID (string): The tID before the change
+ *   oldTPre (int): The tPre before the change
+ */
+func (v *TraceView) indexMoved(pos tracePos, oldTID string, oldTPre int) {
+	if v.index.version != v.version {
+		return
+	}
+
+	elem := v.traces[pos.routine][pos.index]
+
+	positions := v.index.tID[oldTID]
+	for i, p := range positions {
+		if p == pos {
+			v.index.tID[oldTID] = append(positions[:i], positions[i+1:]...)
+			break
+		}
+	}
+	if len(v.index.tID[oldTID]) == 0 {
+		delete(v.index.tID, oldTID)
+	}
+
+	if index, ok := v.index.routineTPre[pos.routine][oldTPre]; ok && index == pos.index {
+		delete(v.index.routineTPre[pos.routine], oldTPre)
+	}
+
+	if p, ok := v.index.tPre[oldTPre]; ok && p == pos {
+		delete(v.index.tPre, oldTPre)
+	}
+
+	v.index.add(pos, elem.GetTID(), elem.GetTPre())
+}
+
+/*
+ * Check if a position is still valid in the current trace
+ * Args:
+ *   pos (tracePos): The position
+ * Returns:
+ *   bool: true if the position points to an element
+ */
+func (v *TraceView) validPos(pos tracePos) bool {
+	return pos.index >= 0 && pos.index < len(v.traces[pos.routine])
+}
+
+/*
+ * Get the positions of all elements with the given tID. For each routine,
+ * at most one position is returned.
+ * The elements can change their tID without the index being informed,
+ * e.g. if the rewriter calls SetT on an element. For this reason all
+ * found elements are checked and the index is rebuild once, if it
+ * does not contain a valid element.
+ * Args:
+ *   tID (string): The tID
+ * Returns:
+ *   []tracePos: The positions, empty if no element with the tID exists
+ */
+func (v *TraceView) findTID(tID string) []tracePos {
+	index, fresh := v.getTraceIndex()
+
+	res := v.findTIDInIndex(index, tID)
+	if len(res) == 0 && !fresh {
+		v.buildTraceIndex()
+		res = v.findTIDInIndex(&v.index, tID)
+	}
+
+	return res
+}
+
+/*
+ * Get the positions of all elements with the given tID from the index.
+ * Positions that are no longer correct are ignored
+ * Args:
+ *   index (*traceIndex): The index
+ *   tID (string): The tID
+ * Returns:
+ *   []tracePos: The valid positions
+ */
+func (v *TraceView) findTIDInIndex(index *traceIndex, tID string) []tracePos {
+	res := make([]tracePos, 0)
+	routines := make(map[int]struct{})
+
+	for _, pos := range index.tID[tID] {
+		if _, ok := routines[pos.routine]; ok {
+			continue
+		}
+		if !v.validPos(pos) || v.traces[pos.routine][pos.index].GetTID() != tID {
+			continue
+		}
+		routines[pos.routine] = struct{}{}
+		res = append(res, pos)
+	}
+
+	return res
+}
+
+/*
+ * Get the position of an element with the given tPre. If routine is
+ * not -1, first search in the given routine.
+ * Args:
+ *   routine (int): The routine to search first, -1 for any routine
+ *   tPre (int): The tPre
+ * Returns:
+ *   tracePos: The position
+ *   bool: true if an element was found
+ */
+func (v *TraceView) findTPre(routine int, tPre int) (tracePos, bool) {
+	index, fresh := v.getTraceIndex()
+
+	pos, ok := v.findTPreInIndex(index, routine, tPre)
+	if !ok && !fresh {
+		v.buildTraceIndex()
+		pos, ok = v.findTPreInIndex(&v.index, routine, tPre)
+	}
+
+	return pos, ok
+}
+
+/*
+ * Get the position of an element with the given tPre from the index.
+ * Args:
+ *   index (*traceIndex): The index
+ *   routine (int): The routine to search first, -1 for any routine
+ *   tPre (int): The tPre
+ * Returns:
+ *   tracePos: The position
+ *   bool: true if a valid element was found
+ */
+func (v *TraceView) findTPreInIndex(index *traceIndex, routine int, tPre int) (tracePos, bool) {
+	if routine != -1 {
+		if i, ok := index.routineTPre[routine][tPre]; ok {
+			pos := tracePos{routine, i}
+			if v.validPos(pos) && v.traces[routine][i].GetTPre() == tPre {
+				return pos, true
+			}
+		}
+	}
+
+	if pos, ok := index.tPre[tPre]; ok {
+		if v.validPos(pos) && v.traces[pos.routine][pos.index].GetTPre() == tPre {
+			return pos, true
+		}
+	}
+
+	return tracePos{}, false
+}
+
+/*
+ * Get all elements on the object with the given id
+ * Args:
+ *   id (int): The id of the object
+ * Returns:
+ *   []TraceElement: The elements
+ */
+func (v *TraceView) getElementsOfObject(id int) []TraceElement {
+	index, _ := v.getTraceIndex()
+
+	res := make([]TraceElement, 0, len(index.object[id]))
+	for _, pos := range index.object[id] {
+		res = append(res, v.traces[pos.routine][pos.index])
+	}
+	return res
+}
+
+/*
+ * Get the positions of all channel elements and selects with the given
+ * channel operation as chosen case
+ * Args:
+ *   id (int): The id of the channel
+ *   oID (int): The operation id of the message
+ * Returns:
+ *   []tracePos: The positions
+ */
+func (v *TraceView) getChannelOp(id int, oID int) []tracePos {
+	index, _ := v.getTraceIndex()
+	return index.channelOp[chanOpKey{id, oID}]
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: traceIndex_test.go
+// Brief: Tests for traceIndex.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"testing"
+)
+
+/*
+ * Create a trace with two routines and a wait group:
+ * routine 1: add (t=1), wait (t=5)
+ * routine 2: done (t=3), done (t=7)
+ */
+func setIndexTestTrace() {
+	ClearTrace()
+	AddElementToTrace(&TraceElementWait{routine: 1, tPre: 1, tPost: 1, id: 9, opW: ChangeOp, delta: 2, pos: "a.go:1",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1})})
+	AddElementToTrace(&TraceElementWait{routine: 1, tPre: 5, tPost: 5, id: 9, opW: WaitOp, pos: "a.go:5",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 2, 2: 2})})
+	AddElementToTrace(&TraceElementWait{routine: 2, tPre: 3, tPost: 3, id: 9, opW: ChangeOp, delta: -1, pos: "b.go:3",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1, 2: 1})})
+	AddElementToTrace(&TraceElementWait{routine: 2, tPre: 7, tPost: 7, id: 9, opW: ChangeOp, delta: -1, pos: "b.go:7",
+		vc: clock.NewVectorClockSet(2, map[int]int{1: 1, 2: 3})})
+}
+
+func TestGetTraceElementFromTIDIndex(t *testing.T) {
+	var tests = []struct {
+		name    string
+		tID     string
+		routine int
+		err     bool
+	}{
+		{"Routine 1", "a.go:5@5", 1, false},
+		{"Routine 2", "b.go:3@3", 2, false},
+		{"Missing", "c.go:1@4", 0, true},
+		{"Empty", "", 0, true},
+	}
+
+	setIndexTestTrace()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			elem, err := GetTraceElementFromTID(test.tID)
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error for %s", test.tID)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+
+			if (*elem).GetTID() != test.tID || (*elem).GetRoutine() != test.routine {
+				t.Errorf("Incorrect element. Expected %s in %d. Got %s in %d.",
+					test.tID, test.routine, (*elem).GetTID(), (*elem).GetRoutine())
+			}
+		})
+	}
+}
+
+func TestTraceIndexChangedElement(t *testing.T) {
+	setIndexTestTrace()
+
+	elem, err := GetTraceElementFromTID("b.go:7@7")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	// change the tID without informing the index
+	(*elem).SetT(11)
+
+	if _, err := GetTraceElementFromTID("b.go:7@7"); err == nil {
+		t.Errorf("Found element with old tID")
+	}
+
+	if _, err := GetTraceElementFromTID("b.go:7@11"); err != nil {
+		t.Errorf("Could not find element with new tID: %s", err.Error())
+	}
+
+	bugElem, err := GetTraceElementFromBugArg("T:2:9:11:WA:b.go:7")
+	if err != nil || bugElem.GetTID() != "b.go:7@11" {
+		t.Errorf("Could not find element from bug arg")
+	}
+}
+
+func TestRemoveElementFromTraceIndex(t *testing.T) {
+	setIndexTestTrace()
+	view := NewTraceView()
+
+	view.RemoveElementFromTrace("b.go:3@3")
+
+	if len(view.traces[2]) != 1 || view.traces[2][0].GetTID() != "b.go:7@7" {
+		t.Errorf("Element was not removed")
+	}
+
+	if len(view.findTID("b.go:7@7")) != 1 {
+		t.Errorf("Could not find element after remove")
+	}
+}
+
+func TestGetNrAddDoneBeforeTimeIndex(t *testing.T) {
+	var tests = []struct {
+		name     string
+		time     int
+		expAdd   int
+		expDone  int
+		removeID string
+	}{
+		{"All", 10, 1, 2, ""},
+		{"Before wait", 5, 1, 1, ""},
+		{"Removed", 10, 1, 1, "b.go:3@3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setIndexTestTrace()
+			view := NewTraceView()
+			if test.removeID != "" {
+				view.RemoveElementFromTrace(test.removeID)
+			}
+
+			nrAdd, nrDone := view.GetNrAddDoneBeforeTime(9, test.time)
+			if nrAdd != test.expAdd || nrDone != test.expDone {
+				t.Errorf("Incorrect number of add/done. Expected %d/%d. Got %d/%d.",
+					test.expAdd, test.expDone, nrAdd, nrDone)
+			}
+		})
+	}
+}
+
+func TestShiftConcurrentOrAfterToAfterIndex(t *testing.T) {
+	setIndexTestTrace()
+	view := NewTraceView()
+	view.getTraceIndex()
+	version := view.index.version
+
+	pos, ok := view.findTPre(1, 5)
+	if !ok {
+		t.Fatalf("Could not find wait")
+	}
+
+	// b.go:7 is concurrent to the wait and is moved to 6
+	view.ShiftConcurrentOrAfterToAfter(view.traces[pos.routine][pos.index])
+
+	if view.index.version != version {
+		t.Errorf("Index was rebuild after shift")
+	}
+
+	if _, ok := view.index.tID["b.go:7@6"]; !ok {
+		t.Errorf("Index was not updated after shift")
+	}
+
+	if len(view.findTID("b.go:7@6")) != 1 {
+		t.Errorf("Could not find shifted element")
+	}
+}
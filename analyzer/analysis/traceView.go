@@ -0,0 +1,576 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: traceView.go
+// Brief: Copy-on-write views on the trace, used to rewrite the trace
+//
+// Author: Erik Kassubek
+// Created: 2024-11-23
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+)
+
+/*
+ * A copy-on-write view on a trace. The view shares the routines and the
+ * elements with the trace it was created from. A routine is only copied when
+ * elements are added to or removed from it, and an element is only copied
+ * when it is changed. The trace the view was created from is never changed
+ * by the view, so that multiple views on the same trace can be used
+ * concurrently. The trace itself must not be changed while views on it exist.
+ * A single view must only be used by one routine at a time.
+ * Fields:
+ *   traces (map[int][]TraceElement): routine -> trace of the view
+ *   owned (map[int]bool): routines whose slice belongs to the view and can be changed in place
+ *   copies (map[TraceElement]TraceElement): element -> copy of the element that belongs
+ *     to the view. Elements that belong to the view are mapped to themselves
+ *   version (int): version of the trace of the view, used for the index
+ *   index (traceIndex): index into the trace of the view
+ */
+type TraceView struct {
+	traces  map[int][]TraceElement
+	owned   map[int]bool
+	copies  map[TraceElement]TraceElement
+	version int
+	index   traceIndex
+}
+
+/*
+ * Create a new view on the current trace
+ * Returns:
+ *   *TraceView: The view
+ */
+func NewTraceView() *TraceView {
+	view := &TraceView{
+		traces: make(map[int][]TraceElement, len(traces)),
+		owned:  make(map[int]bool),
+		copies: make(map[TraceElement]TraceElement),
+		index:  traceIndex{version: -1},
+	}
+
+	for routine, trace := range traces {
+		// limit the capacity, so that appending to the shared slice never
+		// writes into the array of the original trace
+		view.traces[routine] = trace[:len(trace):len(trace)]
+	}
+
+	return view
+}
+
+/*
+ * Mark the trace of the view as changed
+ */
+func (v *TraceView) changed() {
+	v.version++
+}
+
+/*
+ * Make sure, that the slice of the routine belongs to the view
+ * Args:
+ *   routine (int): The routine
+ */
+func (v *TraceView) ownRoutine(routine int) {
+	if v.owned[routine] {
+		return
+	}
+
+	trace := make([]TraceElement, len(v.traces[routine]))
+	copy(trace, v.traces[routine])
+	v.traces[routine] = trace
+	v.owned[routine] = true
+}
+
+/*
+ * Get the element at the given position, such that it can be changed.
+ * If the element is still shared with the original trace, it is replaced
+ * by a copy.
+ * Args:
+ *   pos (tracePos): The position of the element
+ * Returns:
+ *   TraceElement: The element that belongs to the view
+ */
+func (v *TraceView) writable(pos tracePos) TraceElement {
+	elem := v.traces[pos.routine][pos.index]
+	if c, ok := v.copies[elem]; ok && c == elem {
+		return elem
+	}
+
+	v.ownRoutine(pos.routine)
+	c := elem.Copy()
+	v.traces[pos.routine][pos.index] = c
+	v.copies[elem] = c
+	v.copies[c] = c
+	return c
+}
+
+/*
+ * Get the version of the element that belongs to the view. Changes to
+ * the returned element only change the view. Must be used for all
+ * elements that are changed directly, e.g. the elements of a bug or the
+ * partner of an element.
+ * Args:
+ *   elem (TraceElement): The element, either from the view or from the
+ *     trace the view was created from
+ * Returns:
+ *   TraceElement: The element that belongs to the view, nil if elem is nil
+ */
+func (v *TraceView) Element(elem TraceElement) TraceElement {
+	if elem == nil {
+		return nil
+	}
+
+	if c, ok := v.copies[elem]; ok {
+		return c
+	}
+
+	// cases of a select are not in the trace themselves, use the case
+	// of the select that belongs to the view
+	if ch, ok := elem.(*TraceElementChannel); ok && ch.sel != nil {
+		if c := v.selectCase(ch); c != nil {
+			v.copies[elem] = c
+			v.copies[c] = c
+			return c
+		}
+	}
+
+	if pos, ok := v.findElement(elem); ok {
+		return v.writable(pos)
+	}
+
+	// the element is not part of the view, e.g. because it was removed
+	c := elem.Copy()
+	v.copies[elem] = c
+	v.copies[c] = c
+	return c
+}
+
+/*
+ * Get the current version of the element in the view without copying it.
+ * The returned element must not be changed.
+ * Args:
+ *   elem (TraceElement): The element
+ * Returns:
+ *   TraceElement: The copy of the element in the view if it exists, elem otherwise
+ */
+func (v *TraceView) current(elem TraceElement) TraceElement {
+	if c, ok := v.copies[elem]; ok {
+		return c
+	}
+	return elem
+}
+
+/*
+ * Get the case of the select in the view that corresponds to the given case
+ * Args:
+ *   ch (*TraceElementChannel): The case, either the chosen case or one of
+ *     the cases of its select
+ * Returns:
+ *   *TraceElementChannel: The case in the view, nil if ch is not a case of its select
+ */
+func (v *TraceView) selectCase(ch *TraceElementChannel) *TraceElementChannel {
+	sel := v.Element(ch.sel).(*TraceElementSelect)
+
+	if ch == &ch.sel.chosenCase {
+		return &sel.chosenCase
+	}
+
+	for i := range ch.sel.cases {
+		if ch == &ch.sel.cases[i] && i < len(sel.cases) {
+			return &sel.cases[i]
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Find the position of the element in the view
+ * Args:
+ *   elem (TraceElement): The element
+ * Returns:
+ *   tracePos: The position
+ *   bool: true if the element is in the view
+ */
+func (v *TraceView) findElement(elem TraceElement) (tracePos, bool) {
+	routine := elem.GetRoutine()
+
+	if pos, ok := v.findTPre(routine, elem.GetTPre()); ok && v.traces[pos.routine][pos.index] == elem {
+		return pos, true
+	}
+
+	for index, e := range v.traces[routine] {
+		if e == elem {
+			return tracePos{routine, index}, true
+		}
+	}
+
+	return tracePos{}, false
+}
+
+/*
+ * Get the traces of the view. The returned traces must not be changed
+ * Returns:
+ *   map[int][]traceElement: The traces
+ */
+func (v *TraceView) GetTraces() map[int][]TraceElement {
+	return v.traces
+}
+
+/*
+ * Get the trace of the given routine. The returned trace must not be changed
+ * Args:
+ *   id (int): The id of the routine
+ * Returns:
+ *   []traceElement: The trace of the routine
+ */
+func (v *TraceView) GetTraceFromId(id int) []TraceElement {
+	return v.traces[id]
+}
+
+/*
+ * Add an element to the view. The element belongs to the view afterwards
+ * Args:
+ *   element (TraceElement): The element to add
+ */
+func (v *TraceView) AddElement(element TraceElement) {
+	routine := element.GetRoutine()
+	v.ownRoutine(routine)
+	v.traces[routine] = append(v.traces[routine], element)
+	v.copies[element] = element
+	v.changed()
+}
+
+/*
+ * Shorten the trace by removing all elements after the given time
+ * Args:
+ *   time (int): The time to shorten the trace to
+ *   incl (bool): True if an element with the same time should stay included in the trace
+ */
+func (v *TraceView) ShortenTrace(time int, incl bool) {
+	for routine, trace := range v.traces {
+		for index, elem := range trace {
+			if incl && elem.GetTSort() > time {
+				v.traces[routine] = trace[:index:index]
+				break
+			}
+			if !incl && elem.GetTSort() >= time {
+				v.traces[routine] = trace[:index:index]
+				break
+			}
+		}
+	}
+	v.changed()
+}
+
+/*
+ * Remove the element with the given tID from the trace
+ * Args:
+ *   tID (string): The tID of the element to remove
+ */
+func (v *TraceView) RemoveElementFromTrace(tID string) {
+	positions := v.findTID(tID)
+	if len(positions) == 0 {
+		return
+	}
+
+	for _, pos := range positions {
+		v.ownRoutine(pos.routine)
+		v.traces[pos.routine] = append(v.traces[pos.routine][:pos.index], v.traces[pos.routine][pos.index+1:]...)
+	}
+	v.changed()
+}
+
+/*
+ * Shorten the trace of the given routine by removing all elements after and equal the given time
+ * Args:
+ *   routine (int): The routine to shorten
+ *   time (int): The time to shorten the trace to
+ */
+func (v *TraceView) ShortenRoutine(routine int, time int) {
+	for index, elem := range v.traces[routine] {
+		if elem.GetTSort() >= time {
+			v.traces[routine] = v.traces[routine][:index:index]
+			break
+		}
+	}
+	v.changed()
+}
+
+/*
+ * Shorten the trace of the given routine to the given index
+ * Args:
+ *   routine (int): The routine to shorten
+ *   index (int): The index to shorten the trace to
+ *   incl (bool): True if the element at index should stay in the trace
+ */
+func (v *TraceView) ShortenRoutineIndex(routine int, index int, incl bool) {
+	if incl {
+		index++
+	}
+	v.traces[routine] = v.traces[routine][:index:index]
+	v.changed()
+}
+
+/*
+ * Get the number of add and done operations that were executed before a
+ * given time for a given wait group id
+ * Args:
+ *   wgID (int): The id of the waitgroup
+ *   waitTime (int): The time to check
+ * Returns:
+ *   int: The number of add operations
+ *   int: The number of done operations
+ */
+func (v *TraceView) GetNrAddDoneBeforeTime(wgID int, waitTime int) (int, int) {
+	nrAdd := 0
+	nrDone := 0
+
+	for _, elem := range v.getElementsOfObject(wgID) {
+		switch e := elem.(type) {
+		case *TraceElementWait:
+			if e.GetTPre() < waitTime {
+				delta := e.GetDelta()
+				if delta > 0 {
+					nrAdd++
+				} else if delta < 0 {
+					nrDone++
+				}
+			}
+		}
+	}
+
+	return nrAdd, nrDone
+}
+
+// MARK: Shift
+
+/*
+ * Shift all elements with time greater or equal to startTSort by shift
+ * Only shift forward
+ * Args:
+ *   startTPre (int): The time to start shifting
+ *   shift (int): The shift
+ */
+func (v *TraceView) ShiftTrace(startTPre int, shift int) bool {
+	if shift <= 0 {
+		return false
+	}
+
+	for routine, trace := range v.traces {
+		for index, elem := range trace {
+			if elem.GetTPre() >= startTPre {
+				v.writable(tracePos{routine, index}).SetTWithoutNotExecuted(elem.GetTSort() + shift)
+			}
+		}
+	}
+	v.changed()
+
+	return true
+}
+
+/*
+ * Shift all elements that are concurrent or HB-later than the element such
+ * that they are after the element without changeing the order of these elements
+ * Args:
+ *   element (traceElement): The element
+ */
+func (v *TraceView) ShiftConcurrentOrAfterToAfter(element TraceElement) {
+	element = v.current(element)
+
+	elemsToShift := make([]tracePos, 0)
+	minTime := -1
+
+	tID := element.GetTID()
+	tPre := element.GetTPre()
+	vc := element.GetVC()
+
+	for routine, trace := range v.traces {
+		for index, elem := range trace {
+			if elem.GetTPre() == tPre && elem.GetTID() == tID {
+				continue
+			}
+
+			if !(clock.GetHappensBefore(elem.GetVC(), vc) == clock.Before) {
+				elemsToShift = append(elemsToShift, tracePos{routine, index})
+				if minTime == -1 || elem.GetTPre() < minTime {
+					minTime = elem.GetTPre()
+				}
+			}
+		}
+	}
+
+	distance := element.GetTPre() - minTime + 1
+
+	v.shiftElements(elemsToShift, distance)
+}
+
+/*
+ * Shift the elements at the given positions by distance and update the
+ * index for the shifted elements
+ * Args:
+ *   positions ([]tracePos): The positions of the elements to shift
+ *   distance (int): The distance to shift
+ */
+func (v *TraceView) shiftElements(positions []tracePos, distance int) {
+	for _, pos := range positions {
+		elem := v.writable(pos)
+		oldTID := elem.GetTID()
+		tSort := elem.GetTPre()
+		elem.SetT(tSort + distance)
+		v.indexMoved(pos, oldTID, tSort)
+	}
+}
+
+/*
+ * Shift all elements that are concurrent or HB-later than the element such
+ * that they are after the element without changeing the order of these elements
+ * Only shift elements that are after start
+ * Args:
+ *   element (traceElement): The element
+ *   start (traceElement): The time to start shifting (not including)
+ */
+func (v *TraceView) ShiftConcurrentOrAfterToAfterStartingFromElement(element TraceElement, start int) {
+	element = v.current(element)
+
+	elemsToShift := make([]tracePos, 0)
+	minTime := -1
+	maxNotMoved := 0
+
+	tID := element.GetTID()
+	tPre := element.GetTPre()
+	vc := element.GetVC()
+
+	for routine, trace := range v.traces {
+		for index, elem := range trace {
+			if elem.GetTPre() == tPre && elem.GetTID() == tID {
+				continue
+			}
+
+			if !(clock.GetHappensBefore(elem.GetVC(), vc) == clock.Before) {
+				if elem.GetTPre() <= start {
+					continue
+				}
+
+				elemsToShift = append(elemsToShift, tracePos{routine, index})
+				if minTime == -1 || elem.GetTPre() < minTime {
+					minTime = elem.GetTPre()
+				}
+			} else {
+				if maxNotMoved == 0 || elem.GetTPre() > maxNotMoved {
+					maxNotMoved = elem.GetTPre()
+				}
+			}
+		}
+	}
+
+	if element.getTpost() == 0 {
+		element = v.Element(element)
+		positions := v.findTID(tID)
+		element.SetT(maxNotMoved + 1)
+		for _, pos := range positions {
+			if v.traces[pos.routine][pos.index] == element {
+				v.indexMoved(pos, tID, tPre)
+			}
+		}
+	}
+
+	distance := element.GetTPre() - minTime + 1
+
+	v.shiftElements(elemsToShift, distance)
+}
+
+/*
+ * Shift the element to be after all elements, that are concurrent to it
+ * Args:
+ *   element (traceElement): The element
+ */
+func (v *TraceView) ShiftConcurrentToBefore(element TraceElement) {
+	v.ShiftConcurrentOrAfterToAfterStartingFromElement(element, 0)
+}
+
+/*
+ * Remove all elements that are concurrent to the element and have time greater or equal to tmin
+ * Args:
+ *   element (traceElement): The element
+ */
+func (v *TraceView) RemoveConcurrent(element TraceElement, tmin int) {
+	for routine, trace := range v.traces {
+		result := make([]TraceElement, 0)
+		for _, elem := range trace {
+			if elem.GetTSort() < tmin {
+				result = append(result, elem)
+				continue
+			}
+
+			if elem.GetTID() == element.GetTID() {
+				result = append(result, elem)
+				continue
+			}
+
+			if clock.GetHappensBefore(elem.GetVC(), element.GetVC()) != clock.Concurrent {
+				result = append(result, elem)
+			}
+		}
+		v.traces[routine] = result
+		v.owned[routine] = true
+	}
+	v.changed()
+}
+
+/*
+ * Remove all elements that are concurrent to the element or must happen after the element
+ * Args:
+ *   element (traceElement): The element
+ */
+func (v *TraceView) RemoveConcurrentOrAfter(element TraceElement, tmin int) {
+	for routine, trace := range v.traces {
+		result := make([]TraceElement, 0)
+		for _, elem := range trace {
+			if elem.GetTSort() < tmin {
+				result = append(result, elem)
+				continue
+			}
+
+			if elem.GetTID() == element.GetTID() {
+				result = append(result, elem)
+				continue
+			}
+
+			if clock.GetHappensBefore(elem.GetVC(), element.GetVC()) != clock.Before {
+				result = append(result, elem)
+			}
+		}
+		v.traces[routine] = result
+		v.owned[routine] = true
+	}
+	v.changed()
+}
+
+/*
+ * Shift all elements with time greater or equal to startTSort by shift
+ * Only shift back
+ * Args:
+ *   routine (int): The routine to shift
+ *   startTSort (int): The time to start shifting
+ *   shift (int): The shift
+ * Returns:
+ *   bool: True if the shift was successful, false otherwise (shift <= 0)
+ * TODO: is this allowed or will it create problems?
+ */
+func (v *TraceView) ShiftRoutine(routine int, startTSort int, shift int) bool {
+	if shift <= 0 {
+		return false
+	}
+
+	for index, elem := range v.traces[routine] {
+		if elem.GetTPre() >= startTSort {
+			v.writable(tracePos{routine, index}).SetTWithoutNotExecuted(elem.GetTSort() + shift)
+		}
+	}
+	v.changed()
+
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: traceView_test.go
+// Brief: Tests for traceView.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-23
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"testing"
+)
+
+func TestTraceViewDoesNotChangeTrace(t *testing.T) {
+	var tests = []struct {
+		name   string
+		change func(view *TraceView)
+	}{
+		{"Remove", func(view *TraceView) { view.RemoveElementFromTrace("b.go:3@3") }},
+		{"Shorten", func(view *TraceView) { view.ShortenTrace(3, false) }},
+		{"Shift", func(view *TraceView) { view.ShiftTrace(3, 10) }},
+		{"Add", func(view *TraceView) { view.AddTraceElementReplay(20, 0, 7) }},
+		{"Element", func(view *TraceView) { view.Element(traces[1][1]).SetT(12) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setIndexTestTrace()
+			original := []string{traces[1][0].GetTID(), traces[1][1].GetTID(), traces[2][0].GetTID(), traces[2][1].GetTID()}
+
+			view := NewTraceView()
+			test.change(view)
+
+			if len(traces[1]) != 2 || len(traces[2]) != 2 {
+				t.Fatalf("Number of elements in trace changed")
+			}
+
+			current := []string{traces[1][0].GetTID(), traces[1][1].GetTID(), traces[2][0].GetTID(), traces[2][1].GetTID()}
+			for i := range original {
+				if original[i] != current[i] {
+					t.Errorf("Element changed. Expected %s. Got %s.", original[i], current[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTraceViewIndependent(t *testing.T) {
+	setIndexTestTrace()
+
+	view1 := NewTraceView()
+	view2 := NewTraceView()
+
+	view1.Element(traces[2][1]).SetT(12)
+	view2.RemoveElementFromTrace("b.go:7@7")
+
+	if len(view1.findTID("b.go:7@12")) != 1 {
+		t.Errorf("Element was not changed in first view")
+	}
+
+	if len(view2.traces[2]) != 1 || len(view2.findTID("b.go:7@12")) != 0 {
+		t.Errorf("Change of first view is visible in second view")
+	}
+
+	if len(view1.traces[2]) != 2 {
+		t.Errorf("Change of second view is visible in first view")
+	}
+
+	if view1.Element(traces[2][1]) != view1.traces[2][1] {
+		t.Errorf("Element did not return the element of the view")
+	}
+}
+
+func TestTraceViewSelectCase(t *testing.T) {
+	ClearTrace()
+	sel := TraceElementSelect{routine: 1, tPre: 2, tPost: 3, id: 4, pos: "a.go:2",
+		vc: clock.NewVectorClockSet(1, map[int]int{1: 1})}
+	sel.cases = []TraceElementChannel{{routine: 1, tPre: 2, tPost: 3, id: 5, opC: RecvOp, sel: &sel,
+		vc: clock.NewVectorClockSet(1, map[int]int{1: 1})}}
+	sel.chosenCase = sel.cases[0]
+	AddElementToTrace(&sel)
+
+	view := NewTraceView()
+	view.Element(&sel.chosenCase).(*TraceElementChannel).SetTPost(0)
+
+	if sel.tPost != 3 || sel.chosenCase.tPost != 3 {
+		t.Errorf("Changing the case in the view changed the select of the trace")
+	}
+
+	viewSel := view.traces[1][0].(*TraceElementSelect)
+	if viewSel.tPost != 0 || viewSel.chosenCase.tPost != 0 {
+		t.Errorf("Changing the case did not change the select in the view")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: trace_test.go
+// Brief: Tests for trace.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-22
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunAnalysisContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		wantIncomplete bool
+		wantIndex      int
+	}{
+		{"not canceled", context.Background(), false, -1},
+		{"canceled", canceled, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setIndexTestTrace()
+			SetNumberOfRoutines(2)
+			analysisIncomplete = false
+
+			RunAnalysis(test.ctx, false, false, map[string]bool{})
+
+			if IsAnalysisIncomplete() != test.wantIncomplete {
+				t.Errorf("Incorrect incomplete flag. Expected %v. Got %v.", test.wantIncomplete, IsAnalysisIncomplete())
+			}
+
+			for routine := 1; routine <= 2; routine++ {
+				if currentIndex[routine] != test.wantIndex {
+					t.Errorf("Incorrect index for routine %d. Expected %d. Got %d.", routine, test.wantIndex, currentIndex[routine])
+				}
+			}
+		})
+	}
+
+	analysisIncomplete = false
+	SetNumberOfRoutines(0)
+	ClearTrace()
+}
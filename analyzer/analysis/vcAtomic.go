@@ -53,6 +53,7 @@ func Read(at *TraceElementAtomic, vc map[int]clock.VectorClock, sync bool) {
 	newLw(at.id, vc[at.id].GetSize())
 	if sync {
 		vc[at.routine] = vc[at.routine].Sync(lw[at.id])
+		acquireSyncEdges(at.id, at.routine, vc, false)
 	}
 	vc[at.routine] = vc[at.routine].Inc(at.routine)
 }
@@ -67,6 +67,9 @@ func Unbuffered(sender TraceElement, recv TraceElement, vc map[int]clock.VectorC
 		hasReceived[sender.GetID()] = true
 		mostRecentReceive[recv.GetRoutine()][sender.GetID()] = VectorClockTID3{recv, mostRecentReceive[recv.GetRoutine()][sender.GetID()].Vc.Sync(vc[recv.GetRoutine()]).Copy(), sender.GetID()}
 
+		releaseObj(sender.GetID(), sender.GetRoutine(), vc)
+		acquireSyncEdges(sender.GetID(), recv.GetRoutine(), vc, false)
+
 		vc[recv.GetRoutine()] = vc[recv.GetRoutine()].Sync(vc[sender.GetRoutine()])
 		vc[sender.GetRoutine()] = vc[recv.GetRoutine()].Copy()
 		vc[sender.GetRoutine()] = vc[sender.GetRoutine()].Inc(sender.GetRoutine())
@@ -162,6 +165,7 @@ func Send(ch *TraceElementChannel, vc map[int]clock.VectorClock, fifo bool) {
 	hasSend[ch.id] = true
 	mostRecentSend[ch.routine][ch.id] = VectorClockTID3{ch, mostRecentSend[ch.routine][ch.id].Vc.Sync(vc[ch.routine]), ch.id}
 
+	releaseObj(ch.id, ch.routine, vc)
 	vc[ch.routine] = vc[ch.routine].Inc(ch.routine)
 
 	bufferedVCs[ch.id][count] = bufferedVC{true, ch.oID, vc[ch.routine].Copy(), ch.routine, ch.GetTID()}
@@ -251,6 +255,7 @@ func Recv(ch *TraceElementChannel, vc map[int]clock.VectorClock, fifo bool) {
 	tIDSend := bufferedVCs[ch.id][0].tID
 
 	vc[ch.routine] = vc[ch.routine].Sync(v)
+	acquireSyncEdges(ch.id, ch.routine, vc, false)
 
 	if fifo {
 		vc[ch.routine] = vc[ch.routine].Sync(mostRecentReceive[ch.routine][ch.id].Vc)
@@ -319,6 +324,7 @@ func Close(ch *TraceElementChannel, vc map[int]clock.VectorClock) {
 		timemeasurement.End("other")
 	}
 
+	releaseObj(ch.id, ch.routine, vc)
 	vc[ch.routine] = vc[ch.routine].Inc(ch.routine)
 
 	closeData[ch.id] = ch
@@ -367,6 +373,7 @@ func RecvC(ch *TraceElementChannel, vc map[int]clock.VectorClock, buffered bool)
 	if _, ok := closeData[ch.id]; ok {
 		vc[ch.routine] = vc[ch.routine].Sync(closeData[ch.id].vc)
 	}
+	acquireSyncEdges(ch.id, ch.routine, vc, false)
 	vc[ch.routine] = vc[ch.routine].Inc(ch.routine)
 
 	timemeasurement.Start("other")
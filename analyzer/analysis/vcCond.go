@@ -24,12 +24,13 @@ var currentlyWaiting = make(map[int][]int) // -> id -> []routine
  */
 func CondWait(co *TraceElementCond, vc map[int]clock.VectorClock) {
 	if co.tPost != 0 { // not leak
+		acquireSyncEdges(co.id, co.routine, vc, false)
 		if _, ok := currentlyWaiting[co.id]; !ok {
 			currentlyWaiting[co.id] = make([]int, 0)
 		}
 		currentlyWaiting[co.id] = append(currentlyWaiting[co.id], co.routine)
 	}
-	vc[co.routine].Inc(co.routine)
+	vc[co.routine] = vc[co.routine].Inc(co.routine)
 }
 
 /*
@@ -44,7 +45,8 @@ func CondSignal(co *TraceElementCond, vc map[int]clock.VectorClock) {
 		currentlyWaiting[co.id] = currentlyWaiting[co.id][1:]
 		vc[tWait] = vc[tWait].Sync(vc[co.routine])
 	}
-	vc[co.routine].Inc(co.routine)
+	releaseObj(co.id, co.routine, vc)
+	vc[co.routine] = vc[co.routine].Inc(co.routine)
 }
 
 /*
@@ -59,5 +61,6 @@ func CondBroadcast(co *TraceElementCond, vc map[int]clock.VectorClock) {
 	}
 	currentlyWaiting[co.id] = make([]int, 0)
 
-	vc[co.routine].Inc(co.routine)
+	releaseObj(co.id, co.routine, vc)
+	vc[co.routine] = vc[co.routine].Inc(co.routine)
 }
@@ -47,6 +47,7 @@ func Lock(mu *TraceElementMutex, vc map[int]clock.VectorClock, wVc map[int]clock
 	newRel(mu.id, vc[mu.routine].GetSize())
 	vc[mu.routine] = vc[mu.routine].Sync(relW[mu.id])
 	vc[mu.routine] = vc[mu.routine].Sync(relR[mu.id])
+	acquireSyncEdges(mu.id, mu.routine, vc, false)
 	vc[mu.routine] = vc[mu.routine].Inc(mu.routine)
 
 	if analysisCases["leak"] {
@@ -102,6 +103,7 @@ func RLock(mu *TraceElementMutex, vc map[int]clock.VectorClock, wVc map[int]cloc
 
 	newRel(mu.id, vc[mu.routine].GetSize())
 	vc[mu.routine] = vc[mu.routine].Sync(relW[mu.id])
+	acquireSyncEdges(mu.id, mu.routine, vc, true)
 	vc[mu.routine] = vc[mu.routine].Inc(mu.routine)
 
 	if analysisCases["leak"] {
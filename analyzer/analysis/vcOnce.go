@@ -48,5 +48,6 @@ func DoSuc(on *TraceElementOnce, vc map[int]clock.VectorClock) {
 func DoFail(on *TraceElementOnce, vc map[int]clock.VectorClock) {
 	newOSuc(on.id, vc[on.id].GetSize())
 	vc[on.routine] = vc[on.routine].Sync(oSuc[on.id])
+	acquireSyncEdges(on.id, on.routine, vc, false)
 	vc[on.routine] = vc[on.routine].Inc(on.routine)
 }
@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"analyzer/clock"
+	"context"
 	"reflect"
 	"testing"
 )
@@ -78,7 +79,7 @@ func TestSelectSelect(t *testing.T) {
 		3: clock.NewVectorClockSet(3, map[int]int{1: 5, 2: 6, 3: 9}),
 	}
 
-	RunAnalysis(false, false, make(map[string]bool))
+	RunAnalysis(context.Background(), false, false, make(map[string]bool))
 
 	// remember that runAnalysis will increase the counter on [1][1] by 1
 	expectedVcs := map[int]clock.VectorClock{
@@ -149,7 +150,7 @@ func TestChanSelect(t *testing.T) {
 		3: clock.NewVectorClockSet(3, map[int]int{1: 5, 2: 6, 3: 9}),
 	}
 
-	RunAnalysis(false, false, make(map[string]bool))
+	RunAnalysis(context.Background(), false, false, make(map[string]bool))
 
 	// remember that runAnalysis will increase the counter on [1][1] by 1
 	expectedVcs := map[int]clock.VectorClock{
@@ -219,7 +220,7 @@ func TestSelectChan(t *testing.T) {
 		3: clock.NewVectorClockSet(3, map[int]int{1: 5, 2: 6, 3: 9}),
 	}
 
-	RunAnalysis(false, false, make(map[string]bool))
+	RunAnalysis(context.Background(), false, false, make(map[string]bool))
 
 	// remember that runAnalysis will increase the counter on [1][1] by 1
 	expectedVcs := map[int]clock.VectorClock{
@@ -274,7 +275,7 @@ func TestDefault(t *testing.T) {
 		3: clock.NewVectorClockSet(3, map[int]int{1: 5, 2: 6, 3: 9}),
 	}
 
-	RunAnalysis(false, false, make(map[string]bool))
+	RunAnalysis(context.Background(), false, false, make(map[string]bool))
 
 	// remember that runAnalysis will increase the counter on [1][1] by 1
 	expectedVcs := map[int]clock.VectorClock{
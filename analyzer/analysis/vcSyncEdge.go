@@ -0,0 +1,149 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: vcSyncEdge.go
+// Brief: Update functions for vector clocks from sync edges
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+)
+
+// vector clocks of all releases on an object
+var syncEdges = make(map[int]clock.VectorClock) // id -> vc
+
+// vector clocks of all read releases (annotated RUnlock) on an object
+var syncEdgesRead = make(map[int]clock.VectorClock) // id -> vc
+
+// vector clocks of all recorded releases on channels (send, close) and
+// conds (signal, broadcast). The other objects already store the vector
+// clock of their last release, e.g. relW for mutexes.
+var relObj = make(map[int]clock.VectorClock) // id -> vc
+
+/*
+ * Update the vector clocks for a release sync edge. All later acquires
+ * and read acquires on the same object happen after the release.
+ * Args:
+ *   h (*TraceElementSyncEdge): The sync edge
+ *   vc (map[int]VectorClock): The current vector clocks
+ */
+func Release(h *TraceElementSyncEdge, vc map[int]clock.VectorClock) {
+	if _, ok := syncEdges[h.id]; !ok {
+		syncEdges[h.id] = clock.NewVectorClock(vc[h.routine].GetSize())
+	}
+
+	syncEdges[h.id] = syncEdges[h.id].Sync(vc[h.routine])
+	vc[h.routine] = vc[h.routine].Inc(h.routine)
+}
+
+/*
+ * Update the vector clocks for an acquire sync edge. The acquire happens
+ * after all previous releases and read releases on the same object, by
+ * sync edges and by recorded operations.
+ * Args:
+ *   h (*TraceElementSyncEdge): The sync edge
+ *   vc (map[int]VectorClock): The current vector clocks
+ */
+func Acquire(h *TraceElementSyncEdge, vc map[int]clock.VectorClock) {
+	acquireSyncEdges(h.id, h.routine, vc, false)
+	for _, rel := range recordedReleases(h.id, false) {
+		vc[h.routine] = vc[h.routine].Sync(rel)
+	}
+	vc[h.routine] = vc[h.routine].Inc(h.routine)
+}
+
+/*
+ * Update the vector clocks for a read release sync edge. Only later
+ * acquires on the same object happen after the read release, but not
+ * later read acquires, like with an RUnlock on a rw mutex.
+ * Args:
+ *   h (*TraceElementSyncEdge): The sync edge
+ *   vc (map[int]VectorClock): The current vector clocks
+ */
+func ReadRelease(h *TraceElementSyncEdge, vc map[int]clock.VectorClock) {
+	if _, ok := syncEdgesRead[h.id]; !ok {
+		syncEdgesRead[h.id] = clock.NewVectorClock(vc[h.routine].GetSize())
+	}
+
+	syncEdgesRead[h.id] = syncEdgesRead[h.id].Sync(vc[h.routine])
+	vc[h.routine] = vc[h.routine].Inc(h.routine)
+}
+
+/*
+ * Update the vector clocks for a read acquire sync edge. The read acquire
+ * happens after all previous releases on the same object, but not after
+ * previous read releases, like with an RLock on a rw mutex.
+ * Args:
+ *   h (*TraceElementSyncEdge): The sync edge
+ *   vc (map[int]VectorClock): The current vector clocks
+ */
+func ReadAcquire(h *TraceElementSyncEdge, vc map[int]clock.VectorClock) {
+	acquireSyncEdges(h.id, h.routine, vc, true)
+	for _, rel := range recordedReleases(h.id, true) {
+		vc[h.routine] = vc[h.routine].Sync(rel)
+	}
+	vc[h.routine] = vc[h.routine].Inc(h.routine)
+}
+
+/*
+ * Sync a recorded operation, that acquires an object, with the releases of
+ * the sync edges on the object, e.g. a recorded lock with a filtered unlock.
+ * Must be called before the vector clock of the routine is increased.
+ * Args:
+ *   id (int): The id of the object
+ *   routine (int): The routine of the operation
+ *   vc (map[int]VectorClock): The current vector clocks
+ *   read (bool): If true, read releases are ignored, like for an RLock
+ */
+func acquireSyncEdges(id int, routine int, vc map[int]clock.VectorClock, read bool) {
+	if rel, ok := syncEdges[id]; ok {
+		vc[routine] = vc[routine].Sync(rel)
+	}
+	if rel, ok := syncEdgesRead[id]; ok && !read {
+		vc[routine] = vc[routine].Sync(rel)
+	}
+}
+
+/*
+ * Store the release of a recorded operation on a channel or cond, so that
+ * later acquire sync edges on the object happen after it.
+ * Must be called before the vector clock of the routine is increased.
+ * Args:
+ *   id (int): The id of the object
+ *   routine (int): The routine of the operation
+ *   vc (map[int]VectorClock): The current vector clocks
+ */
+func releaseObj(id int, routine int, vc map[int]clock.VectorClock) {
+	if _, ok := relObj[id]; !ok {
+		relObj[id] = clock.NewVectorClock(vc[routine].GetSize())
+	}
+	relObj[id] = relObj[id].Sync(vc[routine])
+}
+
+/*
+ * Get the vector clocks of the releases of recorded operations on an object
+ * Args:
+ *   id (int): The id of the object
+ *   read (bool): If true, read releases (RUnlock) are ignored
+ * Returns:
+ *   []VectorClock: The vector clocks of the releases
+ */
+func recordedReleases(id int, read bool) []clock.VectorClock {
+	rels := []map[int]clock.VectorClock{relW, lastChangeWG, oSuc, lw, relObj}
+	if !read {
+		rels = append(rels, relR)
+	}
+
+	res := make([]clock.VectorClock, 0)
+	for _, rel := range rels {
+		if vc, ok := rel[id]; ok {
+			res = append(res, vc)
+		}
+	}
+	return res
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: vcSyncEdge_test.go
+// Brief: Tests for vcSyncEdge.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package analysis
+
+import (
+	"analyzer/clock"
+	"reflect"
+	"testing"
+)
+
+func TestRelease(t *testing.T) {
+	t.Run("Release", func(t *testing.T) {
+		h := TraceElementSyncEdge{id: 1, routine: 2, opH: ReleaseOp}
+		vc := map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 4, 3: 1})}
+		syncEdges = map[int]clock.VectorClock{1: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 1, 3: 0})}
+
+		expectedEdges := map[int]clock.VectorClock{1: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 4, 3: 1})}
+		expectedVC := map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 5, 3: 1})}
+
+		Release(&h, vc)
+		if !reflect.DeepEqual(syncEdges, expectedEdges) {
+			t.Errorf("Incorrect syncEdges. Expected %v. Got %v.", expectedEdges, syncEdges)
+		}
+
+		if !reflect.DeepEqual(vc, expectedVC) {
+			t.Errorf("Incorrect vc. Expected %v. Got %v.", expectedVC, vc)
+		}
+	})
+}
+
+func TestAcquire(t *testing.T) {
+	var tests = []struct {
+		name       string
+		syncEdges  map[int]clock.VectorClock
+		vc         map[int]clock.VectorClock
+		expectedVC map[int]clock.VectorClock
+	}{
+		{"No release", map[int]clock.VectorClock{},
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 4, 3: 1})},
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 5, 3: 1})}},
+		{"Previous release", map[int]clock.VectorClock{1: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 1, 3: 6})},
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 4, 3: 1})},
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := TraceElementSyncEdge{id: 1, routine: 2, opH: AcquireOp}
+			clearReleases()
+			syncEdges = test.syncEdges
+
+			Acquire(&h, test.vc)
+			if !reflect.DeepEqual(test.vc, test.expectedVC) {
+				t.Errorf("Incorrect vc. Expected %v. Got %v.", test.expectedVC, test.vc)
+			}
+		})
+	}
+}
+
+func TestReadAcquire(t *testing.T) {
+	var tests = []struct {
+		name       string
+		op         opSyncEdge
+		expectedVC map[int]clock.VectorClock
+	}{
+		{"Acquire", AcquireOp,
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})}},
+		{"Read acquire", ReadAcquireOp,
+			map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 1})}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearReleases()
+			syncEdges = map[int]clock.VectorClock{1: clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 1, 3: 0})}
+			vc := map[int]clock.VectorClock{
+				2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 4, 3: 1}),
+				3: clock.NewVectorClockSet(3, map[int]int{1: 0, 2: 0, 3: 6}),
+			}
+
+			// read release in routine 3
+			ReadRelease(&TraceElementSyncEdge{id: 1, routine: 3, opH: ReadReleaseOp}, vc)
+
+			h := TraceElementSyncEdge{id: 1, routine: 2, opH: test.op}
+			if test.op == AcquireOp {
+				Acquire(&h, vc)
+			} else {
+				ReadAcquire(&h, vc)
+			}
+
+			if !reflect.DeepEqual(vc[2], test.expectedVC[2]) {
+				t.Errorf("Incorrect vc. Expected %v. Got %v.", test.expectedVC[2], vc[2])
+			}
+		})
+	}
+	clearReleases()
+}
+
+func TestAcquireRecordedRelease(t *testing.T) {
+	var tests = []struct {
+		name       string
+		op         opSyncEdge
+		rel        *map[int]clock.VectorClock
+		expectedVC clock.VectorClock
+	}{
+		{"Acquire after unlock", AcquireOp, &relW, clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})},
+		{"Acquire after runlock", AcquireOp, &relR, clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})},
+		{"Read acquire after runlock", ReadAcquireOp, &relR, clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 5, 3: 1})},
+		{"Acquire after wait group change", AcquireOp, &lastChangeWG, clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})},
+		{"Acquire after once", AcquireOp, &oSuc, clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})},
+		{"Acquire after send", AcquireOp, &relObj, clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 5, 3: 6})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearReleases()
+			(*test.rel)[1] = clock.NewVectorClockSet(3, map[int]int{1: 3, 2: 1, 3: 6})
+			vc := map[int]clock.VectorClock{2: clock.NewVectorClockSet(3, map[int]int{1: 2, 2: 4, 3: 1})}
+
+			h := TraceElementSyncEdge{id: 1, routine: 2, opH: test.op}
+			if test.op == AcquireOp {
+				Acquire(&h, vc)
+			} else {
+				ReadAcquire(&h, vc)
+			}
+
+			if !reflect.DeepEqual(vc[2], test.expectedVC) {
+				t.Errorf("Incorrect vc. Expected %v. Got %v.", test.expectedVC, vc[2])
+			}
+		})
+	}
+	clearReleases()
+}
+
+func TestRecordedAcquireAfterSyncEdge(t *testing.T) {
+	t.Run("Lock after filtered unlock", func(t *testing.T) {
+		clearReleases()
+		vc := map[int]clock.VectorClock{
+			1: clock.NewVectorClockSet(3, map[int]int{1: 4, 2: 0, 3: 2}),
+			2: clock.NewVectorClockSet(3, map[int]int{1: 0, 2: 3, 3: 0}),
+		}
+
+		// filtered unlock in routine 1, recorded lock in routine 2
+		Release(&TraceElementSyncEdge{id: 1, routine: 1, opH: ReleaseOp}, vc)
+		Lock(&TraceElementMutex{id: 1, routine: 2, tPost: 10}, vc, map[int]clock.VectorClock{})
+
+		expected := clock.NewVectorClockSet(3, map[int]int{1: 4, 2: 4, 3: 2})
+		if !reflect.DeepEqual(vc[2], expected) {
+			t.Errorf("Incorrect vc. Expected %v. Got %v.", expected, vc[2])
+		}
+	})
+	clearReleases()
+}
+
+func clearReleases() {
+	syncEdges = make(map[int]clock.VectorClock)
+	syncEdgesRead = make(map[int]clock.VectorClock)
+	relW = make(map[int]clock.VectorClock)
+	relR = make(map[int]clock.VectorClock)
+	lastChangeWG = make(map[int]clock.VectorClock)
+	oSuc = make(map[int]clock.VectorClock)
+	lw = make(map[int]clock.VectorClock)
+	relObj = make(map[int]clock.VectorClock)
+}
@@ -59,6 +59,7 @@ func Wait(wa *TraceElementWait, vc map[int]clock.VectorClock) {
 	newWg(wa.id, vc[wa.id].GetSize())
 	if wa.tPost != 0 {
 		vc[wa.routine] = vc[wa.routine].Sync(lastChangeWG[wa.id])
+		acquireSyncEdges(wa.id, wa.routine, vc, false)
 		vc[wa.routine] = vc[wa.routine].Inc(wa.routine)
 	}
 }
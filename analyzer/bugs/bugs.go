@@ -12,6 +12,7 @@ package bugs
 
 import (
 	"analyzer/analysis"
+	"analyzer/results"
 	"errors"
 	"sort"
 	"strconv"
@@ -79,14 +80,21 @@ type Bug struct {
 	TraceElement2    []analysis.TraceElement
 }
 
+/*
+ * Get a string representing the bug, used to find duplicates.
+ * If the call stacks have been recorded, the full stacks are used,
+ * otherwise only the positions
+ * Returns:
+ *   string: The bug string
+ */
 func (b Bug) GetBugString() string {
 	paths := make([]string, 0)
 
 	for _, t := range b.TraceElement1 {
-		paths = append(paths, t.GetPos())
+		paths = append(paths, strings.Join(analysis.GetStack(t), "<-"))
 	}
 	for _, t := range b.TraceElement2 {
-		paths = append(paths, t.GetPos())
+		paths = append(paths, strings.Join(analysis.GetStack(t), "<-"))
 	}
 
 	sort.Strings(paths)
@@ -196,7 +204,12 @@ func (b Bug) ToString() string {
 		arg2Str = "partner: "
 
 	default:
-		panic("Unknown bug type in toString: " + string(b.Type))
+		if !results.IsCustomResultType(results.ResultType(b.Type)) {
+			panic("Unknown bug type in toString: " + string(b.Type))
+		}
+		typeStr = results.GetResultTypeDescription(results.ResultType(b.Type))
+		arg1Str = "arg1: "
+		arg2Str = "arg2: "
 	}
 
 	res := typeStr + "\n\t" + arg1Str
@@ -314,7 +327,12 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 		bug.Type = SNotExecutedWithPartner
 		containsArg2 = true
 	default:
-		return actual, bug, errors.New("Unknown bug type in process bug: " + bugStr)
+		if !results.IsCustomResultType(results.ResultType(bugType)) {
+			return actual, bug, errors.New("Unknown bug type in process bug: " + bugStr)
+		}
+		// results of custom checkers are not rewritten
+		bug.Type = ResultType(bugType)
+		actual = true
 	}
 
 	bugArg1 := bugSplit[1]
@@ -0,0 +1,17 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: checkers.go
+// Brief: Custom checkers that are compiled into the analyzer
+//
+// Author: Erik Kassubek
+// Created: 2024-11-24
+//
+// License: BSD-3-Clause
+
+package main
+
+// The packages of custom checkers register their checkers in their init
+// function. To add a checker, add the import of its package here.
+import (
+	_ "analyzer/checkers"
+)
@@ -0,0 +1,95 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: closeOwner.go
+// Brief: Example custom checker for channels closed by a routine that did
+//    not create the channel
+//
+// Author: Erik Kassubek
+// Created: 2024-11-24
+//
+// License: BSD-3-Clause
+
+package checkers
+
+import (
+	"analyzer/analysis"
+	"analyzer/results"
+)
+
+// result type of the checker
+const resCloseNotOwner results.ResultType = "X01"
+
+/*
+ * Checker that reports a close on a channel by a routine that did not
+ * create the channel. Can be enabled with -s closeOwner.
+ * Fields:
+ *   creations (map[int]*analysis.TraceElementNew): id of channel -> creation
+ */
+type closeOwner struct {
+	analysis.CheckerBase
+	creations map[int]*analysis.TraceElementNew
+}
+
+func init() {
+	err := results.RegisterResultType(resCloseNotOwner,
+		"Channel closed by a routine that did not create it:")
+	if err != nil {
+		panic(err)
+	}
+
+	err = analysis.RegisterChecker(&closeOwner{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (c *closeOwner) Name() string {
+	return "closeOwner"
+}
+
+func (c *closeOwner) Init() {
+	c.creations = make(map[int]*analysis.TraceElementNew)
+}
+
+/*
+ * Store the creation of channels
+ * Args:
+ *   e (*analysis.TraceElementNew): The new element
+ *   clocks (analysis.CheckerClocks): The vector clocks of the routine
+ */
+func (c *closeOwner) New(e *analysis.TraceElementNew, clocks analysis.CheckerClocks) {
+	if e.GetObjType() != "IC" {
+		return
+	}
+	c.creations[e.GetID()] = e
+}
+
+/*
+ * Check if a close is executed by the routine that created the channel
+ * Args:
+ *   e (*analysis.TraceElementChannel): The channel element
+ *   clocks (analysis.CheckerClocks): The vector clocks of the routine
+ */
+func (c *closeOwner) Channel(e *analysis.TraceElementChannel, clocks analysis.CheckerClocks) {
+	if e.Operation() != analysis.CloseOp {
+		return
+	}
+
+	creation, ok := c.creations[e.GetID()]
+	if !ok || creation.GetRoutine() == e.GetRoutine() {
+		return
+	}
+
+	arg1, err := analysis.ResultElem(e)
+	if err != nil {
+		return
+	}
+
+	arg2, err := analysis.ResultElem(creation)
+	if err != nil {
+		return
+	}
+
+	results.Result(results.WARNING, resCloseNotOwner,
+		"close", []results.ResultElem{arg1}, "creation", []results.ResultElem{arg2})
+}
@@ -28,15 +28,73 @@ const (
  *   bool: True if vc1 is a cause of vc2, false otherwise
  */
 func isCause(vc1 VectorClock, vc2 VectorClock) bool {
-	atLeastOneSmaller := false
-	for i := 1; i <= vc1.size; i++ {
-		if vc1.clock[i] > vc2.clock[i] {
-			return false
-		} else if vc1.clock[i] < vc2.clock[i] {
-			atLeastOneSmaller = true
+	return compare(vc1, vc2) == Before
+}
+
+/*
+ * Compare two vector clocks of the same size. Subtrees shared by both
+ * clocks are not visited.
+ * Args:
+ *   vc1 (vectorClock): The first vector clock
+ *   vc2 (vectorClock): The second vector clock
+ * Returns:
+ *   happensBefore: Before if vc1 < vc2, After if vc2 < vc1, Concurrent otherwise
+ */
+func compare(vc1 VectorClock, vc2 VectorClock) HappensBefore {
+	a := vc1.getTree()
+	b := vc2.getTree()
+
+	smaller := false
+	greater := false
+	compareNodes(a.root, b.root, a.levels, &smaller, &greater)
+
+	if smaller && !greater {
+		return Before
+	}
+	if greater && !smaller {
+		return After
+	}
+	return Concurrent
+}
+
+/*
+ * Compare two subtrees
+ * Args:
+ *   a (*clockNode): The root of the first subtree
+ *   b (*clockNode): The root of the second subtree
+ *   levels (int): The number of levels of the subtrees
+ *   smaller (*bool): Set to true if a value in a is smaller than in b
+ *   greater (*bool): Set to true if a value in a is greater than in b
+ */
+func compareNodes(a *clockNode, b *clockNode, levels int, smaller *bool, greater *bool) {
+	if a == b || (*smaller && *greater) {
+		return
+	}
+
+	// a node that is not nil contains a value that is not 0
+	if a == nil {
+		*smaller = true
+		return
+	}
+	if b == nil {
+		*greater = true
+		return
+	}
+
+	if levels == 1 {
+		for i, v := range a.values {
+			if v < b.values[i] {
+				*smaller = true
+			} else if v > b.values[i] {
+				*greater = true
+			}
 		}
+		return
+	}
+
+	for i, child := range a.children {
+		compareNodes(child, b.children[i], levels-1, smaller, greater)
 	}
-	return atLeastOneSmaller
 }
 
 /*
@@ -53,11 +111,5 @@ func GetHappensBefore(vc1 VectorClock, vc2 VectorClock) HappensBefore {
 		return None
 	}
 
-	if isCause(vc1, vc2) {
-		return Before
-	}
-	if isCause(vc2, vc1) {
-		return After
-	}
-	return Concurrent
+	return compare(vc1, vc2)
 }
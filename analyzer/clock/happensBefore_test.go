@@ -14,13 +14,13 @@ import "testing"
 
 func TestIsCause(t *testing.T) {
 	v1 := NewVectorClock(3)
-	v1.clock = map[int]int{1: 1, 2: 2, 3: 3}
+	v1.tree = treeFromValues([]int{1, 2, 3})
 
 	v2 := NewVectorClock(3)
-	v2.clock = map[int]int{1: 2, 2: 3, 3: 4}
+	v2.tree = treeFromValues([]int{2, 3, 4})
 
 	v3 := NewVectorClock(3)
-	v3.clock = map[int]int{1: 3, 2: 2, 3: 1}
+	v3.tree = treeFromValues([]int{3, 2, 1})
 
 	var tests = []struct {
 		name     string
@@ -47,13 +47,13 @@ func TestIsCause(t *testing.T) {
 
 func TestGetHappensBefore(t *testing.T) {
 	v1 := NewVectorClock(3)
-	v1.clock = map[int]int{1: 1, 2: 2, 3: 3}
+	v1.tree = treeFromValues([]int{1, 2, 3})
 
 	v2 := NewVectorClock(3)
-	v2.clock = map[int]int{1: 2, 2: 3, 3: 4}
+	v2.tree = treeFromValues([]int{2, 3, 4})
 
 	v3 := NewVectorClock(3)
-	v3.clock = map[int]int{1: 3, 2: 2, 3: 1}
+	v3.tree = treeFromValues([]int{3, 2, 1})
 
 	v4 := NewVectorClock(2)
 
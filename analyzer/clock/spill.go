@@ -0,0 +1,142 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: spill.go
+// Brief: Store vector clocks on disk to reduce the memory usage
+//
+// Author: Erik Kassubek
+// Created: 2024-11-21
+//
+// License: BSD-3-Clause
+
+package clock
+
+import (
+	"encoding/binary"
+	"log"
+	"os"
+	"sync"
+)
+
+/*
+ * SpillFile is a file, vector clocks can be written to. Spilled clocks
+ * are read back from the file each time they are used. They can therefore
+ * not be changed any more, Inc and Sync on a spilled clock return a new
+ * clock in memory.
+ * Fields:
+ *   file (*os.File): The file
+ *   size (int64): The current size of the file
+ *   mutex (sync.Mutex): Lock for the file
+ */
+type SpillFile struct {
+	file  *os.File
+	size  int64
+	mutex sync.Mutex
+}
+
+/*
+ * Position of a spilled vector clock
+ * Fields:
+ *   file (*SpillFile): The file the clock was written to
+ *   offset (int64): The position of the clock in the file
+ */
+type spillRef struct {
+	file   *SpillFile
+	offset int64
+}
+
+/*
+ * Create a new spill file in the given folder
+ * Args:
+ *   dir (string): The folder
+ * Returns:
+ *   *SpillFile: The spill file
+ *   error: An error if the file could not be created
+ */
+func NewSpillFile(dir string) (*SpillFile, error) {
+	file, err := os.CreateTemp(dir, "vc_*.spill")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpillFile{file: file}, nil
+}
+
+/*
+ * Write a vector clock to the spill file
+ * Args:
+ *   vc (VectorClock): The vector clock
+ * Returns:
+ *   VectorClock: The spilled vector clock, that does not hold its values in memory
+ *   error: An error if the clock could not be written
+ */
+func (f *SpillFile) Spill(vc VectorClock) (VectorClock, error) {
+	if vc.spill != nil || vc.tree == nil {
+		return vc, nil
+	}
+
+	buf := make([]byte, 8*vc.size)
+	for i, v := range vc.values() {
+		binary.LittleEndian.PutUint64(buf[8*i:], uint64(v))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	offset := f.size
+	if _, err := f.file.WriteAt(buf, offset); err != nil {
+		return vc, err
+	}
+	f.size += int64(len(buf))
+
+	return VectorClock{
+		size:  vc.size,
+		spill: &spillRef{file: f, offset: offset},
+	}, nil
+}
+
+/*
+ * Close and remove the spill file. All clocks spilled to this file
+ * must not be used any more.
+ */
+func (f *SpillFile) Remove() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.file.Close()
+	os.Remove(f.file.Name())
+}
+
+/*
+ * Read a spilled vector clock
+ * Args:
+ *   size (int): The size of the clock
+ * Returns:
+ *   []int: The values of the clock
+ */
+func (s *spillRef) load(size int) []int {
+	buf := make([]byte, 8*size)
+
+	s.file.mutex.Lock()
+	_, err := s.file.file.ReadAt(buf, s.offset)
+	s.file.mutex.Unlock()
+
+	if err != nil {
+		log.Print("Could not read spilled vector clock: ", err.Error())
+		return make([]int, size)
+	}
+
+	res := make([]int, size)
+	for i := range res {
+		res[i] = int(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return res
+}
+
+/*
+ * Check if the vector clock is stored on disk
+ * Returns:
+ *   bool: True if the clock is spilled
+ */
+func (vc VectorClock) IsSpilled() bool {
+	return vc.spill != nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: spill_test.go
+// Brief: Tests for spill.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-21
+//
+// License: BSD-3-Clause
+
+package clock
+
+import (
+	"testing"
+)
+
+func TestSpill(t *testing.T) {
+	file, err := NewSpillFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("Could not create spill file: %s", err.Error())
+	}
+	defer file.Remove()
+
+	v1 := NewVectorClockSet(3, map[int]int{1: 3, 2: 2, 3: 1})
+	v2 := NewVectorClockSet(3, map[int]int{1: 5, 2: 1})
+
+	s1, err := file.Spill(v1)
+	if err != nil {
+		t.Fatalf("Could not spill clock: %s", err.Error())
+	}
+	s2, err := file.Spill(v2)
+	if err != nil {
+		t.Fatalf("Could not spill clock: %s", err.Error())
+	}
+
+	t.Run("Values", func(t *testing.T) {
+		if !s1.IsSpilled() || s1.tree != nil {
+			t.Errorf("Clock was not spilled")
+		}
+
+		if s1.ToString() != "[3, 2, 1]" || s2.ToString() != "[5, 1, 0]" {
+			t.Errorf("Incorrect spilled values. Got %s and %s.", s1.ToString(), s2.ToString())
+		}
+
+		if !s1.IsEqual(v1) || s1.IsNil() {
+			t.Errorf("Spilled clock is not equal to original clock")
+		}
+	})
+
+	t.Run("Inc and Sync", func(t *testing.T) {
+		inc := s1.Inc(1)
+		if inc.IsSpilled() || inc.ToString() != "[4, 2, 1]" || s1.ToString() != "[3, 2, 1]" {
+			t.Errorf("Incorrect inc of spilled clock. Got %s, spilled %s.", inc.ToString(), s1.ToString())
+		}
+
+		sync := s1.Sync(s2)
+		if sync.IsSpilled() || sync.ToString() != "[5, 2, 1]" {
+			t.Errorf("Incorrect sync of spilled clocks. Got %s.", sync.ToString())
+		}
+	})
+
+	t.Run("Happens before", func(t *testing.T) {
+		s3, _ := file.Spill(v1.Copy().Inc(2))
+		if GetHappensBefore(s1, s3) != Before || GetHappensBefore(s1, s2) != Concurrent {
+			t.Errorf("Incorrect happens before of spilled clocks")
+		}
+	})
+}
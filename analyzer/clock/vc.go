@@ -11,21 +11,62 @@
 package clock
 
 import (
-	"fmt"
 	"log"
 	"runtime"
 	"strconv"
+	"strings"
 )
 
 /*
  * vectorClock is a vector clock
+ * The clock is stored as a persistent tree with fanout clockFanout, whose
+ * leaves hold the values. The value of routine i is stored at index i-1.
+ * Subtrees that only contain zeros are nil, so clocks of traces with many
+ * short-lived routines stay small. Nodes are never changed once they are
+ * part of a tree. Copy therefore only creates a new root that shares all
+ * nodes, Inc copies the path to the changed leaf and Sync and
+ * GetHappensBefore skip subtrees that are shared by both clocks.
+ * Like a slice, the root is shared by all value copies of a clock, Inc
+ * changes the value for all of them. Copy creates an independent clock.
  * Fields:
  *   size (int): The size of the vector clock
- *   clock ([]int): The vector clock
+ *   tree (*clockTree): The root of the tree, nil if the clock is spilled
+ *   spill (*spillRef): If not nil, the clock is stored on disk and tree is nil
  */
 type VectorClock struct {
 	size  int
-	clock map[int]int
+	tree  *clockTree
+	spill *spillRef
+}
+
+// number of bits of the index used on each level of the tree
+const clockBits = 4
+
+// number of children of an inner node and values of a leaf
+const clockFanout = 1 << clockBits
+
+/*
+ * Root of the tree of a vector clock
+ * Fields:
+ *   root (*clockNode): The root node, nil if all values are 0
+ *   levels (int): The number of levels of the tree, 1 if the root is a leaf
+ */
+type clockTree struct {
+	root   *clockNode
+	levels int
+}
+
+/*
+ * Node in the tree of a vector clock. A node is either an inner node with
+ * children or a leaf with values. A node that is not nil contains at
+ * least one value that is not 0.
+ * Fields:
+ *   children ([]*clockNode): The children of an inner node, nil for zero subtrees
+ *   values ([]int): The values of a leaf
+ */
+type clockNode struct {
+	children []*clockNode
+	values   []int
 }
 
 /*
@@ -39,14 +80,10 @@ func NewVectorClock(size int) VectorClock {
 	if size < 0 {
 		size = 0
 	}
-	c := make(map[int]int)
-	for i := 1; i <= size; i++ {
-		c[i] = 0
-	}
 
 	return VectorClock{
-		size:  size,
-		clock: c,
+		size: size,
+		tree: &clockTree{levels: levelsForSize(size)},
 	}
 }
 
@@ -63,19 +100,224 @@ func NewVectorClockSet(size int, cl map[int]int) VectorClock {
 		return clock
 	}
 
-	if size < 0 {
-		size = 0
+	values := make([]int, clock.size)
+	for i := 1; i <= clock.size; i++ {
+		values[i-1] = cl[i]
+	}
+	clock.tree = treeFromValues(values)
+
+	return clock
+}
+
+/*
+ * Get the number of levels of the tree for a clock of the given size
+ * Args:
+ *   size (int): The size of the clock
+ * Returns:
+ *   (int): The number of levels, at least 1
+ */
+func levelsForSize(size int) int {
+	levels := 1
+	for capacity := clockFanout; capacity < size; capacity *= clockFanout {
+		levels++
 	}
+	return levels
+}
+
+/*
+ * Create a tree from the values of a clock
+ * Args:
+ *   values ([]int): The values, the value of routine i is at index i-1
+ * Returns:
+ *   (*clockTree): The tree
+ */
+func treeFromValues(values []int) *clockTree {
+	levels := levelsForSize(len(values))
+	return &clockTree{root: nodeFromValues(values, levels), levels: levels}
+}
 
-	for i := 1; i <= size; i++ {
-		if _, ok := cl[i]; !ok {
-			clock.clock[i] = 0
-		} else {
-			clock.clock[i] = cl[i]
+/*
+ * Create the subtree for the given values
+ * Args:
+ *   values ([]int): The values of the subtree, may be shorter than the subtree
+ *   levels (int): The number of levels of the subtree
+ * Returns:
+ *   (*clockNode): The node, nil if all values are 0
+ */
+func nodeFromValues(values []int, levels int) *clockNode {
+	if levels == 1 {
+		node := &clockNode{values: make([]int, clockFanout)}
+		nonZero := false
+		for i, v := range values {
+			node.values[i] = v
+			nonZero = nonZero || v != 0
 		}
+		if !nonZero {
+			return nil
+		}
+		return node
 	}
 
-	return clock
+	span := 1
+	for i := 1; i < levels; i++ {
+		span *= clockFanout
+	}
+
+	node := &clockNode{children: make([]*clockNode, clockFanout)}
+	nonZero := false
+	for i := 0; i < clockFanout && i*span < len(values); i++ {
+		node.children[i] = nodeFromValues(values[i*span:min(len(values), (i+1)*span)], levels-1)
+		nonZero = nonZero || node.children[i] != nil
+	}
+	if !nonZero {
+		return nil
+	}
+	return node
+}
+
+/*
+ * Write the values of a subtree into a slice
+ * Args:
+ *   node (*clockNode): The root of the subtree
+ *   levels (int): The number of levels of the subtree
+ *   values ([]int): The slice for the values of the subtree
+ */
+func (node *clockNode) writeValues(levels int, values []int) {
+	if node == nil {
+		return
+	}
+
+	if levels == 1 {
+		copy(values, node.values)
+		return
+	}
+
+	span := 1
+	for i := 1; i < levels; i++ {
+		span *= clockFanout
+	}
+	for i, child := range node.children {
+		if i*span >= len(values) {
+			break
+		}
+		child.writeValues(levels-1, values[i*span:min(len(values), (i+1)*span)])
+	}
+}
+
+/*
+ * Get the value at an index of a subtree
+ * Args:
+ *   node (*clockNode): The root of the subtree
+ *   levels (int): The number of levels of the subtree
+ *   index (int): The index
+ * Returns:
+ *   (int): The value
+ */
+func (node *clockNode) get(levels int, index int) int {
+	for ; levels > 1 && node != nil; levels-- {
+		node = node.children[(index>>(clockBits*(levels-1)))&(clockFanout-1)]
+	}
+	if node == nil {
+		return 0
+	}
+	return node.values[index&(clockFanout-1)]
+}
+
+/*
+ * Increment the value at an index of a subtree. The nodes on the path to
+ * the value are copied, the subtree itself is not changed.
+ * Args:
+ *   node (*clockNode): The root of the subtree
+ *   levels (int): The number of levels of the subtree
+ *   index (int): The index
+ * Returns:
+ *   (*clockNode): The root of the new subtree
+ */
+func (node *clockNode) inc(levels int, index int) *clockNode {
+	res := &clockNode{}
+	if levels == 1 {
+		res.values = make([]int, clockFanout)
+		if node != nil {
+			copy(res.values, node.values)
+		}
+		res.values[index&(clockFanout-1)]++
+		return res
+	}
+
+	res.children = make([]*clockNode, clockFanout)
+	if node != nil {
+		copy(res.children, node.children)
+	}
+	i := (index >> (clockBits * (levels - 1))) & (clockFanout - 1)
+	res.children[i] = res.children[i].inc(levels-1, index)
+	return res
+}
+
+/*
+ * Get the element-wise maximum of two subtrees. Shared subtrees are not
+ * visited and if the result is equal to one of the subtrees, this subtree
+ * is returned instead of a new one.
+ * Args:
+ *   a (*clockNode): The root of the first subtree
+ *   b (*clockNode): The root of the second subtree
+ *   levels (int): The number of levels of the subtrees
+ * Returns:
+ *   (*clockNode): The root of the maximum
+ */
+func mergeNodes(a *clockNode, b *clockNode, levels int) *clockNode {
+	if a == b || b == nil {
+		return a
+	}
+	if a == nil {
+		return b
+	}
+
+	if levels == 1 {
+		aIsMax, bIsMax := true, true
+		for i, v := range a.values {
+			if v < b.values[i] {
+				aIsMax = false
+			} else if v > b.values[i] {
+				bIsMax = false
+			}
+		}
+		if aIsMax {
+			return a
+		}
+		if bIsMax {
+			return b
+		}
+
+		res := &clockNode{values: make([]int, clockFanout)}
+		for i, v := range a.values {
+			res.values[i] = max(v, b.values[i])
+		}
+		return res
+	}
+
+	var res *clockNode
+	for i, child := range a.children {
+		merged := mergeNodes(child, b.children[i], levels-1)
+		if merged == child {
+			continue
+		}
+		if res == nil {
+			res = &clockNode{children: make([]*clockNode, clockFanout)}
+			copy(res.children, a.children)
+		}
+		res.children[i] = merged
+	}
+	if res == nil {
+		return a
+	}
+
+	// if all children are taken from b, b is the maximum
+	for i, child := range res.children {
+		if child != b.children[i] {
+			return res
+		}
+	}
+	return b
 }
 
 /*
@@ -88,12 +330,90 @@ func (vc VectorClock) GetSize() int {
 }
 
 /*
- * Get the vector clock
+ * Get the vector clock as a map from routine to value.
+ * The map is created on each call. Use GetValue to access single values.
  * Returns:
- *   (map[int]int): The vector clock
+ *   (map[int]int): The vector clock, nil if the clock was never initialized
  */
 func (vc VectorClock) GetClock() map[int]int {
-	return vc.clock
+	if vc.IsNil() {
+		return nil
+	}
+
+	values := vc.values()
+	res := make(map[int]int, vc.size)
+	for i := 1; i <= vc.size; i++ {
+		res[i] = values[i-1]
+	}
+	return res
+}
+
+/*
+ * Get the values of the clock. For spilled clocks, the values are read from
+ * disk.
+ * Returns:
+ *   ([]int): The values, the value of routine i is at index i-1
+ */
+func (vc VectorClock) values() []int {
+	if vc.spill != nil {
+		return vc.spill.load(vc.size)
+	}
+
+	res := make([]int, vc.size)
+	if vc.tree != nil {
+		vc.tree.root.writeValues(vc.tree.levels, res)
+	}
+	return res
+}
+
+/*
+ * Get the tree of the clock. For spilled clocks, the tree is created from
+ * the values on disk.
+ * Returns:
+ *   (*clockTree): The tree, must not be changed
+ */
+func (vc VectorClock) getTree() *clockTree {
+	if vc.spill != nil {
+		return treeFromValues(vc.values())
+	}
+	if vc.tree == nil {
+		return &clockTree{levels: levelsForSize(vc.size)}
+	}
+	return vc.tree
+}
+
+/*
+ * Get the value of the vector clock for a routine
+ * Args:
+ *   routine (int): The routine
+ * Returns:
+ *   (int): The value of the clock for the routine, 0 if the routine is not
+ *     in the clock
+ */
+func (vc VectorClock) GetValue(routine int) int {
+	if routine < 1 || routine > vc.size {
+		return 0
+	}
+
+	if vc.spill != nil {
+		return vc.values()[routine-1]
+	}
+
+	if vc.tree == nil {
+		return 0
+	}
+
+	return vc.tree.root.get(vc.tree.levels, routine-1)
+}
+
+/*
+ * Check if the vector clock was never initialized, e.g. if it is the
+ * zero value of an unset struct field
+ * Returns:
+ *   (bool): True if the clock was never initialized
+ */
+func (vc VectorClock) IsNil() bool {
+	return vc.tree == nil && vc.spill == nil
 }
 
 /*
@@ -102,34 +422,38 @@ func (vc VectorClock) GetClock() map[int]int {
  *   (string): The string representation of the vector clock
  */
 func (vc VectorClock) ToString() string {
-	str := "["
-	for i := 1; i <= vc.size; i++ {
-		str += fmt.Sprint(vc.clock[i])
-		if i <= vc.size-1 {
-			str += ", "
+	values := vc.values()
+	var str strings.Builder
+	str.WriteString("[")
+	for i, v := range values {
+		str.WriteString(strconv.Itoa(v))
+		if i < len(values)-1 {
+			str.WriteString(", ")
 		}
 	}
-	str += "]"
-	return str
+	str.WriteString("]")
+	return str.String()
 }
 
 /*
  * Increment the vector clock at the given position
+ * The increment is done in place, except for spilled clocks. Copies of the
+ * clock created with Copy are not changed.
  * Args:
  *   routine (int): The routine to increment
  * Returns:
  *   (vectorClock): The vector clock
  */
 func (vc VectorClock) Inc(routine int) VectorClock {
-	if routine > vc.size {
+	if routine < 1 || routine > vc.size {
 		return vc
 	}
 
-	if vc.clock == nil {
-		vc.clock = make(map[int]int)
+	if vc.spill != nil || vc.tree == nil {
+		vc = vc.load()
 	}
 
-	vc.clock[routine]++
+	vc.tree.root = vc.tree.root.inc(vc.tree.levels, routine-1)
 	return vc
 }
 
@@ -154,27 +478,50 @@ func (vc VectorClock) Sync(rec VectorClock) VectorClock {
 		return vc.Copy()
 	}
 
-	copy := rec.Copy()
-	for i := 1; i <= vc.size; i++ {
-		if vc.clock[i] > copy.clock[i] {
-			copy.clock[i] = vc.clock[i]
+	if vc.size != rec.size {
+		// the trees have different shapes, merge the values
+		values := rec.values()
+		for i, v := range vc.values() {
+			if i < len(values) && v > values[i] {
+				values[i] = v
+			}
 		}
+		return VectorClock{size: rec.size, tree: treeFromValues(values)}
 	}
 
-	return copy
+	a := vc.getTree()
+	b := rec.getTree()
+	return VectorClock{
+		size: rec.size,
+		tree: &clockTree{root: mergeNodes(a.root, b.root, a.levels), levels: a.levels},
+	}
 }
 
 /*
  * Create a copy of the vector clock
+ * The copy shares all nodes with the clock. Spilled clocks cannot be
+ * changed, the copy therefore stays on disk.
  * Returns:
  *   (vectorClock): The copy of the vector clock
  */
 func (vc VectorClock) Copy() VectorClock {
-	newVc := NewVectorClock(vc.size)
-	for i := 1; i <= vc.size; i++ {
-		newVc.clock[i] = vc.clock[i]
+	if vc.spill != nil {
+		return vc
+	}
+	return vc.load()
+}
+
+/*
+ * Create a copy of the vector clock, that is stored in memory
+ * Returns:
+ *   (vectorClock): The copy of the vector clock
+ */
+func (vc VectorClock) load() VectorClock {
+	tree := vc.getTree()
+	return VectorClock{
+		size: vc.size,
+		tree: &clockTree{root: tree.root, levels: tree.levels},
 	}
-	return newVc
 }
 
 /*
@@ -185,12 +532,43 @@ func (vc VectorClock) IsEqual(vc2 VectorClock) bool {
 		return false
 	}
 
-	for i := 1; i <= vc.size; i++ {
-		if vc.clock[i] != vc2.clock[i] {
-			return false
+	a := vc.getTree()
+	b := vc2.getTree()
+	return equalNodes(a.root, b.root, a.levels)
+}
+
+/*
+ * Check if two subtrees contain the same values
+ * Args:
+ *   a (*clockNode): The root of the first subtree
+ *   b (*clockNode): The root of the second subtree
+ *   levels (int): The number of levels of the subtrees
+ * Returns:
+ *   (bool): True if the values are equal
+ */
+func equalNodes(a *clockNode, b *clockNode, levels int) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		// a node that is not nil contains a value that is not 0
+		return false
+	}
+
+	if levels == 1 {
+		for i, v := range a.values {
+			if v != b.values[i] {
+				return false
+			}
 		}
+		return true
 	}
 
+	for i, child := range a.children {
+		if !equalNodes(child, b.children[i], levels-1) {
+			return false
+		}
+	}
 	return true
 }
 
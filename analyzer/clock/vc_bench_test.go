@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: vc_bench_test.go
+// Brief: Benchmarks for vc.go and happensBefore.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package clock
+
+import (
+	"strconv"
+	"testing"
+)
+
+var benchSizes = []int{10, 100, 1000, 10000}
+
+/*
+ * Create a vector clock where every entry is set
+ * Args:
+ *   size (int): The size of the vector clock
+ *   offset (int): Added to every value
+ * Returns:
+ *   VectorClock: The vector clock
+ */
+func benchClock(size int, offset int) VectorClock {
+	values := make([]int, size)
+	for i := 1; i <= size; i++ {
+		values[i-1] = (i*7+offset)%13 + offset
+	}
+	return VectorClock{size: size, tree: treeFromValues(values)}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			vc := benchClock(size, 0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = vc.Copy()
+			}
+		})
+	}
+}
+
+func BenchmarkInc(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			vc := benchClock(size, 0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vc = vc.Inc(i%size + 1)
+			}
+		})
+	}
+}
+
+func BenchmarkSync(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			vc1 := benchClock(size, 0)
+			vc2 := benchClock(size, 3)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = vc1.Sync(vc2)
+			}
+		})
+	}
+}
+
+func BenchmarkGetHappensBefore(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run("Before/"+strconv.Itoa(size), func(b *testing.B) {
+			vc1 := benchClock(size, 0)
+			vc2 := vc1.Copy().Inc(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = GetHappensBefore(vc1, vc2)
+			}
+		})
+
+		b.Run("Concurrent/"+strconv.Itoa(size), func(b *testing.B) {
+			vc1 := benchClock(size, 0).Inc(1)
+			vc2 := benchClock(size, 0).Inc(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = GetHappensBefore(vc1, vc2)
+			}
+		})
+	}
+}
@@ -35,8 +35,8 @@ func TestVcNew(t *testing.T) {
 				t.Errorf("Incorrect size. Expected %d. Got %d.", test.sizeExp, v.size)
 			}
 
-			if !reflect.DeepEqual(v.clock, test.clock) {
-				t.Errorf("Incorrect VC. Expected %v. Got %v.", test.clock, v.clock)
+			if !reflect.DeepEqual(v.GetClock(), test.clock) {
+				t.Errorf("Incorrect VC. Expected %v. Got %v.", test.clock, v.GetClock())
 			}
 		})
 	}
@@ -61,8 +61,8 @@ func TestVectorClockSet(t *testing.T) {
 			v := NewVectorClockSet(test.size, test.clock)
 
 			if v.size != test.size {
-				if !reflect.DeepEqual(v.clock, test.expClock) {
-					t.Errorf("Incorrect VC. Expected %v. Got %v.", test.expClock, v.clock)
+				if !reflect.DeepEqual(v.GetClock(), test.expClock) {
+					t.Errorf("Incorrect VC. Expected %v. Got %v.", test.expClock, v.GetClock())
 				}
 			}
 		})
@@ -71,7 +71,7 @@ func TestVectorClockSet(t *testing.T) {
 
 func TestGet(t *testing.T) {
 	v := NewVectorClock(3)
-	v.clock = map[int]int{1: 3, 2: 1, 3: 6}
+	v.tree = treeFromValues([]int{3, 1, 6})
 
 	t.Run("Get string", func(t *testing.T) {
 		expectString := "[3, 1, 6]"
@@ -94,7 +94,7 @@ func TestGet(t *testing.T) {
 
 func TestInc(t *testing.T) {
 	v := NewVectorClock(3)
-	v.clock = map[int]int{1: 3, 2: 2, 3: 1}
+	v.tree = treeFromValues([]int{3, 2, 1})
 	expectString := "[3, 4, 2]"
 
 	t.Run("Increment", func(t *testing.T) {
@@ -119,10 +119,10 @@ func TestInc(t *testing.T) {
 
 func TestSync(t *testing.T) {
 	v1 := NewVectorClock(3)
-	v1.clock = map[int]int{1: 3, 2: 2, 3: 1}
+	v1.tree = treeFromValues([]int{3, 2, 1})
 
 	v2 := NewVectorClock(3)
-	v2.clock = map[int]int{1: 1, 2: 2, 3: 4}
+	v2.tree = treeFromValues([]int{1, 2, 4})
 
 	t.Run("Sync", func(t *testing.T) {
 		v := v1.Sync(v2)
@@ -147,7 +147,7 @@ func TestSync(t *testing.T) {
 
 func TestCopy(t *testing.T) {
 	v := NewVectorClock(3)
-	v.clock = map[int]int{1: 1, 2: 2, 3: 3}
+	v.tree = treeFromValues([]int{1, 2, 3})
 
 	t.Run("Copy", func(t *testing.T) {
 
@@ -180,36 +180,36 @@ func TestIsEqual(t *testing.T) {
 		{
 			name: "Equal vector clocks",
 			vc1: VectorClock{
-				size:  3,
-				clock: map[int]int{1: 1, 2: 2, 3: 3},
+				size: 3,
+				tree: treeFromValues([]int{1, 2, 3}),
 			},
 			vc2: VectorClock{
-				size:  3,
-				clock: map[int]int{1: 1, 2: 2, 3: 3},
+				size: 3,
+				tree: treeFromValues([]int{1, 2, 3}),
 			},
 			expected: true,
 		},
 		{
 			name: "Different sizes",
 			vc1: VectorClock{
-				size:  3,
-				clock: map[int]int{1: 1, 2: 2, 3: 3},
+				size: 3,
+				tree: treeFromValues([]int{1, 2, 3}),
 			},
 			vc2: VectorClock{
-				size:  2,
-				clock: map[int]int{1: 1, 2: 2},
+				size: 2,
+				tree: treeFromValues([]int{1, 2}),
 			},
 			expected: false,
 		},
 		{
 			name: "Different values",
 			vc1: VectorClock{
-				size:  3,
-				clock: map[int]int{1: 1, 2: 2, 3: 3},
+				size: 3,
+				tree: treeFromValues([]int{1, 2, 3}),
 			},
 			vc2: VectorClock{
-				size:  3,
-				clock: map[int]int{1: 1, 2: 2, 3: 4},
+				size: 3,
+				tree: treeFromValues([]int{1, 2, 4}),
 			},
 			expected: false,
 		},
@@ -235,45 +235,45 @@ func TestIsMapVcEqual(t *testing.T) {
 		{
 			name: "Equal maps",
 			v1: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
-				2: {size: 3, clock: map[int]int{1: 4, 2: 5, 3: 6}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
+				2: {size: 3, tree: treeFromValues([]int{4, 5, 6})},
 			},
 			v2: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
-				2: {size: 3, clock: map[int]int{1: 4, 2: 5, 3: 6}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
+				2: {size: 3, tree: treeFromValues([]int{4, 5, 6})},
 			},
 			expected: true,
 		},
 		{
 			name: "Different sizes",
 			v1: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
 			},
 			v2: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
-				2: {size: 3, clock: map[int]int{1: 4, 2: 5, 3: 6}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
+				2: {size: 3, tree: treeFromValues([]int{4, 5, 6})},
 			},
 			expected: false,
 		},
 		{
 			name: "Different values",
 			v1: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
-				2: {size: 3, clock: map[int]int{1: 4, 2: 5, 3: 6}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
+				2: {size: 3, tree: treeFromValues([]int{4, 5, 6})},
 			},
 			v2: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
-				2: {size: 3, clock: map[int]int{1: 4, 2: 5, 3: 7}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
+				2: {size: 3, tree: treeFromValues([]int{4, 5, 7})},
 			},
 			expected: false,
 		},
 		{
 			name: "Different keys",
 			v1: map[int]VectorClock{
-				1: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
+				1: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
 			},
 			v2: map[int]VectorClock{
-				2: {size: 3, clock: map[int]int{1: 1, 2: 2, 3: 3}},
+				2: {size: 3, tree: treeFromValues([]int{1, 2, 3})},
 			},
 			expected: false,
 		},
@@ -288,3 +288,69 @@ func TestIsMapVcEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestLargeClock(t *testing.T) {
+	// the clocks span several levels of the tree, compare them with dense values
+	size := 1000
+	v1 := NewVectorClock(size)
+	v2 := NewVectorClock(size)
+	exp1 := make([]int, size)
+	exp2 := make([]int, size)
+
+	for i := 0; i < 3000; i++ {
+		r := (i*37)%size + 1
+		if i%2 == 0 {
+			v1.Inc(r)
+			exp1[r-1]++
+		} else {
+			v2.Inc(r)
+			exp2[r-1]++
+		}
+	}
+
+	t.Run("Inc", func(t *testing.T) {
+		if !reflect.DeepEqual(v1.values(), exp1) {
+			t.Errorf("Incorrect values after increment")
+		}
+		if v1.GetValue(38) != exp1[37] {
+			t.Errorf("Incorrect value. Expected %d. Got %d.", exp1[37], v1.GetValue(38))
+		}
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		c := v1.Copy()
+		c.Inc(size)
+		if v1.GetValue(size) != exp1[size-1] {
+			t.Errorf("Increment of copy changed the original clock")
+		}
+		if c.GetValue(size) != exp1[size-1]+1 {
+			t.Errorf("Incorrect value of copy. Expected %d. Got %d.", exp1[size-1]+1, c.GetValue(size))
+		}
+		if GetHappensBefore(v1, c) != Before {
+			t.Errorf("Clock should happen before its incremented copy")
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		s := v1.Sync(v2)
+		expSync := make([]int, size)
+		for i := range expSync {
+			expSync[i] = max(exp1[i], exp2[i])
+		}
+		if !reflect.DeepEqual(s.values(), expSync) {
+			t.Errorf("Incorrect values after sync")
+		}
+		if !reflect.DeepEqual(v1.values(), exp1) {
+			t.Errorf("Sync changed the original clock")
+		}
+		if GetHappensBefore(v1, s) != Before || GetHappensBefore(s, v2) != After {
+			t.Errorf("Incorrect happens before relation with the synced clock")
+		}
+		if GetHappensBefore(v1, v2) != Concurrent {
+			t.Errorf("Clocks should be concurrent")
+		}
+		if !s.IsEqual(NewVectorClockSet(size, s.GetClock())) {
+			t.Errorf("Synced clock should be equal to clock with the same values")
+		}
+	})
+}
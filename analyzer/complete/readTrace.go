@@ -76,11 +76,12 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 					continue
 				}
 
-				if field[0] == "A" || field[0] == "X" {
+				if field[0] == "A" || field[0] == "X" || field[0] == "I" || field[0] == "H" {
 					continue
 				}
 
-				pos := strings.Split(field[len(field)-1], ":")
+				// ignore the call stack if recorded
+				pos := strings.Split(strings.Split(field[len(field)-1], ";")[0], ":")
 				if len(pos) != 2 {
 					continue
 				}
@@ -0,0 +1,118 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: config.go
+// Brief: Apply the configuration file advocate.toml to the flags
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"analyzer/config"
+	"analyzer/results"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Setting of the configuration file that corresponds to a flag
+ * Fields:
+ *   flag (string): name of the flag
+ *   section (string): section in the configuration file
+ *   key (string): key in the section
+ *   env (string): environment variable used as default of the flag, if any
+ */
+type configFlag struct {
+	flag    string
+	section string
+	key     string
+	env     string
+}
+
+// settings of the configuration file used by the analyzer
+var configFlags = []configFlag{
+	{"s", "analysis", "scenarios", ""},
+	{"f", "analysis", "fifo", ""},
+	{"c", "analysis", "ignoreCriticalSection", ""},
+	{"S", "analysis", "rewriteAll", ""},
+	{"T", "timeout", "analysis", ""},
+	{"include", "record", "include", "ADVOCATE_INCLUDE"},
+	{"exclude", "record", "exclude", "ADVOCATE_EXCLUDE"},
+	{"baseline", "analysis", "baseline", "ADVOCATE_BASELINE"},
+}
+
+/*
+ * Read the configuration file and set all flags that are not set on the
+ * command line or by their environment variable
+ * Args:
+ *   path: path to the configuration file, if empty, advocate.toml in the
+ *     current directory is used if it exists
+ * Returns:
+ *   error: if the file could not be read or a value is invalid
+ */
+func applyConfig(path string) error {
+	if path == "" {
+		path = config.Find()
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	setOnCommandLine := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	for _, cf := range configFlags {
+		if setOnCommandLine[cf.flag] || (cf.env != "" && os.Getenv(cf.env) != "") {
+			continue
+		}
+
+		value, ok := configValue(cfg, cf)
+		if !ok {
+			continue
+		}
+
+		if err := flag.Set(cf.flag, value); err != nil {
+			return err
+		}
+	}
+
+	if suppress, ok := cfg.List("analysis", "suppress"); ok {
+		results.SetConfigSuppressions(suppress)
+	}
+
+	return nil
+}
+
+/*
+ * Get the value of a setting as a flag value
+ * Args:
+ *   cfg: the configuration
+ *   cf: the setting
+ * Returns:
+ *   string: the value
+ *   bool: true if the setting exists
+ */
+func configValue(cfg *config.Config, cf configFlag) (string, bool) {
+	if value, ok := cfg.Bool(cf.section, cf.key); ok {
+		return strconv.FormatBool(value), true
+	}
+	if value, ok := cfg.Int(cf.section, cf.key); ok {
+		return strconv.Itoa(value), true
+	}
+	if cf.flag == "baseline" {
+		return cfg.Path(cf.section, cf.key)
+	}
+	if value, ok := cfg.List(cf.section, cf.key); ok {
+		return strings.Join(value, ","), true
+	}
+	return "", false
+}
@@ -0,0 +1,249 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: config.go
+// Brief: Read the configuration file advocate.toml
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+/*
+Package config reads the configuration file advocate.toml, that is shared by
+the analyzer and the toolchain. The file uses a subset of TOML: sections,
+comments and key = value pairs with strings, integers, booleans and arrays of
+strings, that can span multiple lines. Strings in double quotes can contain
+escapes, strings in single quotes are read literally. Keys can be quoted,
+e.g. package paths in the scenarios section.
+*/
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// name of the configuration file
+const FileName = "advocate.toml"
+
+/*
+ * Configuration read from the configuration file
+ * Fields:
+ *   File (string): path to the configuration file, empty if no file was read
+ *   sections (map[string]map[string]interface{}): section -> key -> value
+ */
+type Config struct {
+	File     string
+	sections map[string]map[string]interface{}
+}
+
+/*
+ * Find the configuration file. The path is taken from ADVOCATE_CONFIG,
+ * which is set by the toolchain. Otherwise advocate.toml in the current
+ * directory is used, if it exists.
+ * Returns:
+ *   string: the path to the file, empty if no file exists
+ */
+func Find() string {
+	if path := os.Getenv("ADVOCATE_CONFIG"); path != "" {
+		return path
+	}
+
+	if _, err := os.Stat(FileName); err == nil {
+		return FileName
+	}
+
+	return ""
+}
+
+/*
+ * Read the configuration file
+ * Args:
+ *   path: path to the file, if empty, an empty configuration is returned
+ * Returns:
+ *   *Config: the configuration
+ *   error
+ */
+func Load(path string) (*Config, error) {
+	if path == "" {
+		return Parse("")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	cfg.File, _ = filepath.Abs(path)
+	return cfg, nil
+}
+
+/*
+ * Parse the content of a configuration file
+ * Args:
+ *   data: the content
+ * Returns:
+ *   *Config: the configuration
+ *   error
+ */
+func Parse(data string) (*Config, error) {
+	sections, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{sections: sections}, nil
+}
+
+/*
+ * Get a value of the configuration
+ * Args:
+ *   section: the section, empty for values before the first section
+ *   key: the key
+ * Returns:
+ *   interface{}: the value
+ *   bool: true if the value exists
+ */
+func (c *Config) get(section, key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	val, ok := c.sections[section][key]
+	return val, ok
+}
+
+/*
+ * Get a string value
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   string: the value
+ *   bool: true if a string value exists
+ */
+func (c *Config) String(section, key string) (string, bool) {
+	val, ok := c.get(section, key)
+	res, isString := val.(string)
+	return res, ok && isString
+}
+
+/*
+ * Get an integer value
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   int: the value
+ *   bool: true if an integer value exists
+ */
+func (c *Config) Int(section, key string) (int, bool) {
+	val, ok := c.get(section, key)
+	res, isInt := val.(int)
+	return res, ok && isInt
+}
+
+/*
+ * Get a boolean value
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   bool: the value
+ *   bool: true if a boolean value exists
+ */
+func (c *Config) Bool(section, key string) (bool, bool) {
+	val, ok := c.get(section, key)
+	res, isBool := val.(bool)
+	return res, ok && isBool
+}
+
+/*
+ * Get an array of strings. A single string is returned as an array with
+ * one element.
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   []string: the value
+ *   bool: true if an array or string value exists
+ */
+func (c *Config) List(section, key string) ([]string, bool) {
+	val, ok := c.get(section, key)
+	switch v := val.(type) {
+	case []string:
+		return v, ok
+	case string:
+		return []string{v}, ok
+	}
+	return nil, false
+}
+
+/*
+ * Get a value in the form in which it would be given as a flag. Arrays are
+ * joined with ,
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   string: the value
+ *   bool: true if the value exists
+ */
+func (c *Config) FlagValue(section, key string) (string, bool) {
+	val, ok := c.get(section, key)
+	switch v := val.(type) {
+	case []string:
+		return strings.Join(v, ","), ok
+	case int:
+		return strconv.Itoa(v), ok
+	case bool:
+		return strconv.FormatBool(v), ok
+	case string:
+		return v, ok
+	}
+	return "", false
+}
+
+/*
+ * Get the keys of a section
+ * Args:
+ *   section: the section
+ * Returns:
+ *   []string: the sorted keys, empty if the section does not exist
+ */
+func (c *Config) Keys(section string) []string {
+	res := make([]string, 0)
+	if c == nil {
+		return res
+	}
+	for key := range c.sections[section] {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+/*
+ * Get a path from the configuration. Relative paths are relative to the
+ * folder of the configuration file.
+ * Args:
+ *   section: the section
+ *   key: the key
+ * Returns:
+ *   string: the path
+ *   bool: true if the value exists
+ */
+func (c *Config) Path(section, key string) (string, bool) {
+	path, ok := c.String(section, key)
+	if !ok || path == "" || filepath.IsAbs(path) || c.File == "" {
+		return path, ok
+	}
+	return filepath.Join(filepath.Dir(c.File), path), true
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: config_test.go
+// Brief: Tests for config.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := "# configuration\n" +
+		"[test]\n" +
+		"packages = [\"./pkg/...\", \"./cmd\"] # comment\n" +
+		"short = true\n" +
+		"timeout = \"30m\"\n" +
+		"\n" +
+		"[timeout]\n" +
+		"analysis = 300\n" +
+		"\n" +
+		"[scenarios]\n" +
+		"\"pkg/a\" = \"sl\"\n" +
+		"\"pkg/#b\" = \"r#\"\n"
+
+	cfg, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if list, ok := cfg.List("test", "packages"); !ok || !reflect.DeepEqual(list, []string{"./pkg/...", "./cmd"}) {
+		t.Errorf("Incorrect packages. Got %v", list)
+	}
+	if short, ok := cfg.Bool("test", "short"); !ok || !short {
+		t.Errorf("Incorrect short. Got %t", short)
+	}
+	if timeout, ok := cfg.String("test", "timeout"); !ok || timeout != "30m" {
+		t.Errorf("Incorrect timeout. Got %s", timeout)
+	}
+	if list, ok := cfg.List("test", "timeout"); !ok || !reflect.DeepEqual(list, []string{"30m"}) {
+		t.Errorf("Incorrect string as list. Got %v", list)
+	}
+	if analysis, ok := cfg.Int("timeout", "analysis"); !ok || analysis != 300 {
+		t.Errorf("Incorrect analysis timeout. Got %d", analysis)
+	}
+	if _, ok := cfg.Int("test", "timeout"); ok {
+		t.Errorf("Expected no int for a string value")
+	}
+	if packages, _ := cfg.FlagValue("test", "packages"); packages != "./pkg/...,./cmd" {
+		t.Errorf("Incorrect packages as flag. Got %s", packages)
+	}
+	if analysis, _ := cfg.FlagValue("timeout", "analysis"); analysis != "300" {
+		t.Errorf("Incorrect analysis timeout as flag. Got %s", analysis)
+	}
+	if short, _ := cfg.FlagValue("test", "short"); short != "true" {
+		t.Errorf("Incorrect short as flag. Got %s", short)
+	}
+	if _, ok := cfg.String("test", "missing"); ok {
+		t.Errorf("Expected no value for a missing key")
+	}
+	if keys := cfg.Keys("scenarios"); !reflect.DeepEqual(keys, []string{"pkg/#b", "pkg/a"}) {
+		t.Errorf("Incorrect keys. Got %v", keys)
+	}
+	if scenarios, _ := cfg.String("scenarios", "pkg/#b"); scenarios != "r#" {
+		t.Errorf("Incorrect value with #. Got %s", scenarios)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+	}{
+		{"Section", "[test\n"},
+		{"No value", "[test]\nshort\n"},
+		{"Missing value", "short =\n"},
+		{"Invalid value", "short = yes\n"},
+		{"Unterminated string", "timeout = \"30m\n"},
+		{"Unterminated array", "packages = [\"a\",\n\"b\"\n"},
+		{"Array of ints", "packages = [1, 2]\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(test.content); err == nil {
+				t.Errorf("Expected error for %q", test.content)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte("[analysis]\nbaseline = \"baseline.txt\"\nabs = \"/tmp/b\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if baseline, _ := cfg.Path("analysis", "baseline"); baseline != filepath.Join(dir, "baseline.txt") {
+		t.Errorf("Incorrect relative path. Got %s", baseline)
+	}
+	if abs, _ := cfg.Path("analysis", "abs"); abs != "/tmp/b" {
+		t.Errorf("Incorrect absolute path. Got %s", abs)
+	}
+
+	var empty *Config
+	if _, ok := empty.String("analysis", "baseline"); ok {
+		t.Errorf("Expected no value in nil config")
+	}
+}
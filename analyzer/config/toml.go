@@ -0,0 +1,380 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: toml.go
+// Brief: Parser for the subset of TOML used by the configuration file
+//
+// Author: Erik Kassubek
+// Created: 2024-12-02
+//
+// License: BSD-3-Clause
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+ * Parser for the subset of TOML used by the configuration file
+ * Fields:
+ *   data (string): the content of the file
+ *   pos (int): the position of the next byte to read
+ *   line (int): the line of pos, starting with 1
+ */
+type tomlParser struct {
+	data string
+	pos  int
+	line int
+}
+
+/*
+ * Parse the content of a configuration file
+ * Args:
+ *   data: the content
+ * Returns:
+ *   map[string]map[string]interface{}: section -> key -> value, the values
+ *     before the first section are in the section ""
+ *   error
+ */
+func parseTOML(data string) (map[string]map[string]interface{}, error) {
+	p := &tomlParser{data: data, line: 1}
+	res := map[string]map[string]interface{}{"": {}}
+	section := ""
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return res, nil
+		}
+
+		switch p.peek() {
+		case '\n', '#':
+			// empty line or comment
+		case '[':
+			p.pos++
+			p.skipSpace()
+			name, err := p.parseKey()
+			if err != nil {
+				return nil, p.errorf("invalid section: %s", err.Error())
+			}
+			p.skipSpace()
+			if p.eof() || p.peek() != ']' {
+				return nil, p.errorf("invalid section, expected ]")
+			}
+			p.pos++
+
+			section = name
+			if _, ok := res[section]; !ok {
+				res[section] = make(map[string]interface{})
+			}
+		default:
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, p.errorf("%s", err.Error())
+			}
+			p.skipSpace()
+			if p.eof() || p.peek() != '=' {
+				return nil, p.errorf("expected key = value")
+			}
+			p.pos++
+			p.skipSpace()
+
+			val, err := p.parseValue()
+			if err != nil {
+				return nil, p.errorf("%s", err.Error())
+			}
+			if _, ok := res[section][key]; ok {
+				return nil, p.errorf("duplicate key %s", key)
+			}
+			res[section][key] = val
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+/*
+ * Create an error with the current line
+ * Args:
+ *   format: the format of the message
+ *   args: the arguments of the format
+ * Returns:
+ *   error: the error
+ */
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+/*
+ * Check if the whole content has been read
+ * Returns:
+ *   bool: true if there is nothing left to read
+ */
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+/*
+ * Get the next byte without reading it. Must not be called at the end.
+ * Returns:
+ *   byte: the next byte
+ */
+func (p *tomlParser) peek() byte {
+	return p.data[p.pos]
+}
+
+/*
+ * Skip spaces and tabs. \r is skipped as well, so that files with \r\n
+ * line endings can be read.
+ */
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.pos++
+	}
+}
+
+/*
+ * Skip a comment until the end of the line. The line break is not skipped.
+ */
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+/*
+ * Skip spaces, comments and line breaks, e.g. between the elements of an array
+ */
+func (p *tomlParser) skipSpaceAndLines() {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return
+		}
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '\n':
+			p.pos++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+/*
+ * Read the rest of the line after a value or section. Only a comment is
+ * allowed there.
+ * Returns:
+ *   error: if the line contains anything else
+ */
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if !p.eof() && p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q", p.data[p.pos:p.pos+1])
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+/*
+ * Check if a byte can be part of a bare key
+ * Args:
+ *   c: the byte
+ * Returns:
+ *   bool: true for letters, digits, _ and -
+ */
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+/*
+ * Parse a key or a section name. Bare keys can only contain letters,
+ * digits, _ and -. Other keys, e.g. package paths, must be quoted.
+ * Returns:
+ *   string: the key
+ *   error
+ */
+func (p *tomlParser) parseKey() (string, error) {
+	if !p.eof() && (p.peek() == '"' || p.peek() == '\'') {
+		return p.parseString()
+	}
+
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.peek()) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", errors.New("missing key")
+	}
+	return p.data[start:p.pos], nil
+}
+
+/*
+ * Parse a value
+ * Returns:
+ *   interface{}: string, int, bool or []string
+ *   error
+ */
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.eof() {
+		return nil, errors.New("missing value")
+	}
+
+	switch p.peek() {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		return p.parseArray()
+	}
+
+	start := p.pos
+	for !p.eof() && strings.IndexByte(" \t\r\n#,]", p.peek()) == -1 {
+		p.pos++
+	}
+	value := p.data[start:p.pos]
+
+	switch value {
+	case "":
+		return nil, errors.New("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	res, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s", value)
+	}
+	return res, nil
+}
+
+/*
+ * Parse an array of strings. The array can span multiple lines and contain
+ * comments and a trailing comma.
+ * Returns:
+ *   []string: the elements
+ *   error
+ */
+func (p *tomlParser) parseArray() ([]string, error) {
+	p.pos++ // [
+	res := make([]string, 0)
+
+	for {
+		p.skipSpaceAndLines()
+		if p.eof() {
+			return nil, errors.New("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return res, nil
+		}
+
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("arrays can only contain strings, got %v", val)
+		}
+		res = append(res, str)
+
+		p.skipSpaceAndLines()
+		if p.eof() {
+			return nil, errors.New("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return res, nil
+		default:
+			return nil, fmt.Errorf("expected , or ] in array, got %q", p.data[p.pos:p.pos+1])
+		}
+	}
+}
+
+/*
+ * Parse a string. Strings in "" can contain the escapes \", \\, \b, \f,
+ * \n, \r, \t, \uXXXX and \UXXXXXXXX. Strings in '' are read literally.
+ * Strings cannot span multiple lines.
+ * Returns:
+ *   string: the string without quotes and with the escapes replaced
+ *   error
+ */
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var res strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", errors.New("unterminated string")
+		}
+
+		c := p.peek()
+		p.pos++
+
+		if c == quote {
+			return res.String(), nil
+		}
+		if c != '\\' || quote == '\'' {
+			res.WriteByte(c)
+			continue
+		}
+
+		if p.eof() {
+			return "", errors.New("unterminated string")
+		}
+		esc := p.peek()
+		p.pos++
+
+		switch esc {
+		case '"', '\\':
+			res.WriteByte(esc)
+		case 'b':
+			res.WriteByte('\b')
+		case 'f':
+			res.WriteByte('\f')
+		case 'n':
+			res.WriteByte('\n')
+		case 'r':
+			res.WriteByte('\r')
+		case 't':
+			res.WriteByte('\t')
+		case 'u', 'U':
+			length := 4
+			if esc == 'U' {
+				length = 8
+			}
+			if p.pos+length > len(p.data) {
+				return "", fmt.Errorf("invalid escape \\%c", esc)
+			}
+			code, err := strconv.ParseUint(p.data[p.pos:p.pos+length], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape \\%c%s", esc, p.data[p.pos:p.pos+length])
+			}
+			p.pos += length
+			res.WriteRune(rune(code))
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", esc)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: toml_test.go
+// Brief: Tests for toml.go
+//
+// Author: Erik Kassubek
+// Created: 2024-12-02
+//
+// License: BSD-3-Clause
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		section string
+		key     string
+		exp     interface{}
+	}{
+		{"Bare key", "a = 1", "", "a", 1},
+		{"Negative int", "a = -5 # comment", "", "a", -5},
+		{"Bool", "[s]\na = false\n", "s", "a", false},
+		{"Quoted key", "[s]\n\"pkg/a\" = \"x\"", "s", "pkg/a", "x"},
+		{"Literal key", "[s]\n'pkg/b' = \"x\"", "s", "pkg/b", "x"},
+		{"Quoted section", "[\"a.b\"]\nc = 1", "a.b", "c", 1},
+		{"Comma in string", "a = [\"x,y\", \"z\"]", "", "a", []string{"x,y", "z"}},
+		{"Bracket in string", "a = [\"x]\", \"[y\"]", "", "a", []string{"x]", "[y"}},
+		{"Hash in string", "a = \"x#y\" # comment", "", "a", "x#y"},
+		{"Escaped quote", `a = "x\"y"`, "", "a", `x"y`},
+		{"Escaped quote in array", `a = ["x\",", "y"]`, "", "a", []string{`x",`, "y"}},
+		{"Escapes", `a = "\\ \t \n \u00e4 \U0001F600"`, "", "a", "\\ \t \n ä 😀"},
+		{"Literal string", `a = 'C:\path\"x'`, "", "a", `C:\path\"x`},
+		{"Empty array", "a = []", "", "a", []string{}},
+		{"Multi line array", "a = [\n  \"x\", # first\n\n  \"y\",\n]\nb = 1", "", "a", []string{"x", "y"}},
+		{"After multi line array", "a = [\n  \"x\",\n]\nb = 1", "", "b", 1},
+		{"CRLF", "[s]\r\na = \"x\"\r\nb = 2\r\n", "s", "b", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := parseTOML(test.content)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if val := res[test.section][test.key]; !reflect.DeepEqual(val, test.exp) {
+				t.Errorf("Incorrect value. Expected %#v. Got %#v.", test.exp, val)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"Invalid escape", `a = "\q"`, "line 1: invalid escape \\q"},
+		{"Invalid unicode escape", `a = "\u12"`, "line 1: invalid escape \\u"},
+		{"Unterminated string", "a = \"x\nb = 1", "line 1: unterminated string"},
+		{"String over lines in array", "a = [\n\"x\ny\"]", "line 2: unterminated string"},
+		{"Missing comma", "a = [\"x\" \"y\"]", "line 1: expected , or ] in array, got \"\\\"\""},
+		{"Unterminated array", "a = [\n\"x\",\n", "line 3: unterminated array"},
+		{"Text after value", "a = \"x\" y", "line 1: unexpected \"y\""},
+		{"Duplicate key", "a = 1\na = 2", "line 2: duplicate key a"},
+		{"Invalid bare key", "a.b = 1", "line 1: expected key = value"},
+		{"Missing key", "= 1", "line 1: missing key"},
+		{"Section", "[s\na = 1", "line 1: invalid section, expected ]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseTOML(test.content)
+			if err == nil {
+				t.Fatalf("Expected error for %q", test.content)
+			}
+			if err.Error() != test.err {
+				t.Errorf("Incorrect error. Expected %s. Got %s.", test.err, err.Error())
+			}
+		})
+	}
+}
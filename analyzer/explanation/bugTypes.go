@@ -11,6 +11,7 @@
 package explanation
 
 import (
+	"analyzer/results"
 	"fmt"
 )
 
@@ -426,6 +427,15 @@ func GetCodeFromDescription(description string) string {
 }
 
 func getBugTypeDescription(bugType string) map[string]string {
+	if results.IsCustomResultType(results.ResultType(bugType)) {
+		return map[string]string{
+			"crit":        "Custom",
+			"name":        results.GetResultTypeDescription(results.ResultType(bugType)),
+			"explanation": "The result was reported by a custom checker.",
+			"example":     "",
+		}
+	}
+
 	return map[string]string{
 		"crit":        bugCrit[bugType],
 		"name":        bugNames[bugType],
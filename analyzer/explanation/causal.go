@@ -0,0 +1,98 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: causal.go
+// Brief: Causal explanation of the bugs in the explanation and the report
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"analyzer/results"
+	"html"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Get the causal explanations from the result records, if they exist
+ * Args:
+ *    path: the path to the folder with the results
+ * Returns:
+ *    map[string]*results.Causal: id of the bug -> causal explanation
+ */
+func getRecordCausal(path string) map[string]*results.Causal {
+	res := make(map[string]*results.Causal)
+
+	records, err := results.ReadRecords(path)
+	if err != nil {
+		return res
+	}
+
+	for _, record := range records {
+		if record.Causal == nil {
+			continue
+		}
+		// the reports of the recorded run are named without the rewrite number
+		res[strings.TrimPrefix(record.ID, "0_")] = record.Causal
+	}
+
+	return res
+}
+
+/*
+ * Get the causal explanation as markdown
+ * Args:
+ *    causal: the explanation
+ * Returns:
+ *    string: the markdown, empty if no explanation exists
+ */
+func causalToMarkdown(causal *results.Causal) string {
+	if causal == nil {
+		return ""
+	}
+
+	res := "## Causal explanation\n"
+	res += "Vector clocks of the involved operations in the recorded trace:\n\n"
+	res += "| Routine | tPre | Operation | Position | Vector clock |\n"
+	res += "| --- | --- | --- | --- | --- |\n"
+	for _, elem := range causal.Elems {
+		res += "| " + strconv.Itoa(elem.Routine) + " | " + strconv.Itoa(elem.TPre) + " | " +
+			elem.Op + " | " + elem.Pos + " | " + elem.VC + " |\n"
+	}
+	res += "\n"
+
+	res += strings.Join(causal.Narrative, " ") + "\n\n"
+
+	return res
+}
+
+/*
+ * Get the causal explanation as html
+ * Args:
+ *    causal: the explanation
+ * Returns:
+ *    string: the html code, empty if no explanation exists
+ */
+func causalToHTML(causal *results.Causal) string {
+	if causal == nil {
+		return ""
+	}
+
+	res := "<h2>Causal explanation</h2>\n"
+	res += "<p>Vector clocks of the involved operations in the recorded trace:</p>\n"
+	res += "<table>\n<tr><th>Routine</th><th>tPre</th><th>Operation</th><th>Position</th><th>Vector clock</th></tr>\n"
+	for _, elem := range causal.Elems {
+		res += "<tr><td>" + strconv.Itoa(elem.Routine) + "</td><td>" + strconv.Itoa(elem.TPre) + "</td><td>" +
+			html.EscapeString(elem.Op) + "</td><td><code>" + html.EscapeString(elem.Pos) + "</code></td><td><code>" +
+			html.EscapeString(elem.VC) + "</code></td></tr>\n"
+	}
+	res += "</table>\n"
+
+	res += "<p>" + html.EscapeString(strings.Join(causal.Narrative, " ")) + "</p>\n"
+
+	return res
+}
@@ -11,6 +11,7 @@
 package explanation
 
 import (
+	"analyzer/results"
 	"errors"
 	"fmt"
 	"os"
@@ -49,7 +50,10 @@ import (
 func CreateOverview(path string, ignoreDouble bool) error {
 	// get the code info (main file, test name, commands)
 
-	replayCodes := getOutputCodes(path)
+	replayCodes, verdicts, ok := getRecordCodes(path)
+	if !ok {
+		replayCodes = getOutputCodes(path)
+	}
 
 	progInfo, err := readProgInfo(path)
 	if err != nil {
@@ -61,14 +65,93 @@ func CreateOverview(path string, ignoreDouble bool) error {
 		fmt.Println("Cound not read header line: ", err)
 	}
 
+	objects, stacks := readTraceInfo(path)
+	causal := getRecordCausal(path)
+
+	bugResults, runs := readResults(path, progInfo["file"], hl)
+	reports := make([]bugReportInfo, 0)
+
+	var errWrite error
+	for _, bug := range bugResults {
+		// get the bug type description
+		bugTypeDescription := getBugTypeDescription(bug.bugType)
+
+		// get the code of the bug elements
+		code, err := getBugPositions(bug.positions, progInfo)
+		if err != nil {
+			fmt.Println("Error getting bug positions: ", err)
+		}
+
+		// get the replay info
+		replay := getRewriteInfo(bug.bugType, replayCodes, bug.id)
+		replay["verdict"] = verdicts[bug.id]
+
+		if ignoreDouble && replay["exitCode"] == "double" {
+			continue
+		}
+
+		score := getScore(path, bug.id, bug.result, replay, runs[results.PositionKey(bug.result)])
+
+		errWrite = writeFile(path, bug.id, bugTypeDescription, bug.positions, bug.args, bug.elemType, code,
+			replay, progInfo, objects, stacks, score, runs[results.PositionKey(bug.result)], causal[bug.id])
+		if errWrite == nil {
+			reports = append(reports, bugReportInfo{bug.id, bug.bugType, bugTypeDescription["name"], score})
+		}
+	}
+
+	if errOverview := writeOverview(path, reports); errOverview != nil {
+		fmt.Println("Error writing overview: ", errOverview)
+	}
+
+	return errWrite
+
+}
+
+/*
+ * A result of the analysis as used in the bug reports
+ * Fields:
+ *    id (string): id of the bug, [index] for the recorded run, [rewriteNr]_[index] for rerecorded runs
+ *    bugType (string): result type
+ *    result (string): the result in the machine readable format
+ *    positions (map[int][]string): argument -> positions of the elements
+ *    args (map[int][][]string): argument -> fields of the elements
+ *    elemType (map[int]string): argument -> type of the elements
+ */
+type bugResult struct {
+	id        string
+	bugType   string
+	result    string
+	positions map[int][]string
+	args      map[int][][]string
+	elemType  map[int]string
+}
+
+/*
+ * Read all results of the analysis in a result folder
+ * Args:
+ *    path: the path to the folder with the results
+ *    fileWithHeader: the file in which the header was inserted
+ *    headerLine: the line in which the header was inserted
+ * Returns:
+ *    []bugResult: the results
+ *    map[string]int: key of the result (results.PositionKey) -> number of runs in which it was found
+ */
+func readResults(path string, fileWithHeader string, headerLine int) ([]bugResult, map[string]int) {
 	resultsMachine, _ := filepath.Glob(filepath.Join(path, "results_machine_*.log"))
 	resultsMachine = append(resultsMachine, filepath.Join(path, "results_machine.log"))
 
+	res := make([]bugResult, 0)
+
 	for _, result := range resultsMachine {
 		file, _ := os.ReadFile(result)
-		numberResults := len(strings.Split(string(file), "\n"))
+		resultLines := strings.Split(string(file), "\n")
+		numberResults := len(resultLines)
 
 		for index := 1; index < numberResults; index++ {
+			if !results.IsResultLine(resultLines[index-1]) {
+				continue
+			}
+
 			id := ""
 			if strings.HasSuffix(result, "results_machine.log") {
 				id += strconv.Itoa(index)
@@ -77,7 +160,7 @@ func CreateOverview(path string, ignoreDouble bool) error {
 				id += elem[len(elem)-1] + "_" + strconv.Itoa(index)
 			}
 
-			bugType, bugPos, bugElemType, err := readAnalysisResults(result, index, progInfo["file"], hl)
+			bugType, bugPos, bugArgs, bugElemType, err := readAnalysisResults(result, index, fileWithHeader, headerLine)
 			if err != nil {
 				continue
 			}
@@ -86,35 +169,17 @@ func CreateOverview(path string, ignoreDouble bool) error {
 				break
 			}
 
-			// get the bug type description
-			bugTypeDescription := getBugTypeDescription(bugType)
-
-			// get the code of the bug elements
-			code, err := getBugPositions(bugPos, progInfo)
-			if err != nil {
-				fmt.Println("Error getting bug positions: ", err)
-			}
-
-			// get the replay info
-			replay := getRewriteInfo(bugType, replayCodes, id)
-
-			if ignoreDouble && replay["exitCode"] == "double" {
-				continue
-			}
-
-			err = writeFile(path, id, bugTypeDescription, bugPos, bugElemType, code,
-				replay, progInfo)
+			res = append(res, bugResult{id, bugType, resultLines[index-1], bugPos, bugArgs, bugElemType})
 		}
 	}
 
-	return err
-
+	return res, countRuns(resultsMachine)
 }
 
-func readAnalysisResults(path string, index int, fileWithHeader string, headerLine int) (string, map[int][]string, map[int]string, error) {
+func readAnalysisResults(path string, index int, fileWithHeader string, headerLine int) (string, map[int][]string, map[int][][]string, map[int]string, error) {
 	file, err := os.ReadFile(path)
 	if err != nil {
-		return "", nil, nil, err
+		return "", nil, nil, nil, err
 	}
 
 	lines := strings.Split(string(file), "\n")
@@ -122,7 +187,7 @@ func readAnalysisResults(path string, index int, fileWithHeader string, headerLi
 	index-- // the index is 1-based
 
 	if index >= len(lines) {
-		return "", nil, nil, errors.New("index out of range")
+		return "", nil, nil, nil, errors.New("index out of range")
 	}
 
 	bugStr := string(lines[index])
@@ -130,6 +195,7 @@ func readAnalysisResults(path string, index int, fileWithHeader string, headerLi
 	bugType := bugFields[0]
 
 	bugPos := make(map[int][]string)
+	bugArgs := make(map[int][][]string)
 	bugElemType := make(map[int]string)
 
 	posAlreadyKnown := make([]string, 0)
@@ -141,6 +207,7 @@ func readAnalysisResults(path string, index int, fileWithHeader string, headerLi
 		}
 
 		bugPos[i] = make([]string, 0)
+		bugArgs[i] = make([][]string, 0)
 
 		for j, elem := range bugElems {
 			fields := strings.Split(elem, ":")
@@ -175,21 +242,31 @@ func readAnalysisResults(path string, index int, fileWithHeader string, headerLi
 			posAlreadyKnown = append(posAlreadyKnown, pos)
 
 			bugPos[i] = append(bugPos[i], pos)
+			bugArgs[i] = append(bugArgs[i], fields)
 		}
 	}
 
-	return bugType, bugPos, bugElemType, nil
+	return bugType, bugPos, bugArgs, bugElemType, nil
 }
 
 func writeFile(path string, index string, description map[string]string,
-	positions map[int][]string, bugElemType map[int]string, code map[int][]string,
-	replay map[string]string, progInfo map[string]string) error {
+	positions map[int][]string, args map[int][][]string, bugElemType map[int]string,
+	code map[int][]string, replay map[string]string, progInfo map[string]string,
+	objects map[string]string, stacks map[string][]string, score float64, runs int,
+	causal *results.Causal) error {
 
 	res := ""
 
 	// write the bug type description
 	res += "# " + description["crit"] + ": " + description["name"] + "\n\n"
 	res += description["explanation"] + "\n\n"
+
+	res += "## Confidence\n"
+	res += "Confidence score: **" + results.FormatScore(score) + "**\n\n"
+	res += "The score is based on whether the bug was observed or predicted, "
+	res += "the distance of the involved operations in the trace, whether the trace "
+	res += "could be rewritten and the bug was confirmed by the replay, and the number "
+	res += "of runs in which the bug was found (" + strconv.Itoa(runs) + ").\n\n"
 	res += "## Minimal Example\n"
 	res += "The following code is a minimal example to visualize the bug type. It is not the code where the bug was found.\n\n```go\n"
 	res += description["example"] + "\n```\n\n"
@@ -246,10 +323,25 @@ func writeFile(path string, index string, description map[string]string,
 			}
 			code := code[key][j]
 			res += "-> " + pos + "\n"
+
+			// T:routine:objID:tPre:objType:file:line
+			arg := args[key][j]
+			if stack, ok := stacks[arg[3]]; ok && stack[0] == arg[5]+":"+arg[6] {
+				res += "\nCall stack:\n\n"
+				for _, frame := range stack {
+					res += "- " + frame + "\n"
+				}
+				res += "\n"
+			}
+			if obj, ok := objects[arg[2]]; ok {
+				res += "\n" + strings.ToUpper(obj[:1]) + obj[1:] + "\n\n"
+			}
 			res += code + "\n\n"
 		}
 	}
 
+	res += causalToMarkdown(causal)
+
 	// write the info about the replay, if possible including the command to read the bug
 	res += "## Replay\n"
 	res += replay["description"] + "\n\n"
@@ -261,6 +353,9 @@ func writeFile(path string, index string, description map[string]string,
 		res += "The replay was not performed, because the same bug had been found before."
 	} else {
 		res += "**Replaying " + replay["replaySuc"] + "**.\n\n"
+		if replay["verdict"] != "" {
+			res += "Verdict: " + replay["verdict"] + "\n\n"
+		}
 		if replayPossible {
 			res += "The replayed trace can be found in: "
 			res += "rewritten_trace_" + index + "\n\n"
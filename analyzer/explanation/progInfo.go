@@ -53,6 +53,10 @@ func readProgInfo(path string) (map[string]string, error) {
 			res["file"] = strings.TrimPrefix(lines[i], "FileName: ")
 		} else if strings.Contains(lines[i], "TestName: ") {
 			res["name"] = strings.TrimPrefix(lines[i], "TestName: ")
+		} else if strings.Contains(lines[i], "ExecutableName: ") {
+			res["exec"] = strings.TrimPrefix(lines[i], "ExecutableName: ")
+		} else if strings.Contains(lines[i], "ProgramOptions: ") {
+			res["progOptions"] = strings.TrimPrefix(lines[i], "ProgramOptions: ")
 		} else if strings.Contains(lines[i], "Import added at line: ") {
 			res["importLine"] = strings.TrimPrefix(lines[i], "Import added at line: ")
 		} else if strings.Contains(lines[i], "Header added at line: ") {
@@ -62,6 +66,8 @@ func readProgInfo(path string) (map[string]string, error) {
 
 	res["file"] = strings.TrimSpace(res["file"])
 	res["name"] = strings.TrimSpace(res["name"])
+	res["exec"] = strings.TrimSpace(res["exec"])
+	res["progOptions"] = strings.TrimSpace(res["progOptions"])
 	res["importLine"] = strings.TrimSpace(res["importLine"])
 	res["headerLine"] = strings.TrimSpace(res["headerLine"])
 
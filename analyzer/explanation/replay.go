@@ -11,6 +11,7 @@
 package explanation
 
 import (
+	"analyzer/results"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -122,6 +123,47 @@ func getOutputCodes(path string) map[string]string {
 	return replayCode
 }
 
+/*
+ * Get the replay codes from the result records, if they exist
+ * Args:
+ *    path: the path to the folder with the results
+ * Returns:
+ *    map[string]string: id of the bug -> exit code, double or fail
+ *    map[string]string: id of the bug -> verdict of the replay
+ *    bool: true if the records exist
+ */
+func getRecordCodes(path string) (map[string]string, map[string]string, bool) {
+	records, err := results.ReadRecords(path)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	codes := make(map[string]string)
+	verdicts := make(map[string]string)
+	for _, record := range records {
+		// the reports of the recorded run are named without the rewrite number
+		id := strings.TrimPrefix(record.ID, "0_")
+
+		switch record.Rewrite {
+		case results.RewriteDouble:
+			codes[id] = "double"
+		case results.RewriteDone:
+			if record.ExitCode != -1 {
+				codes[id] = strconv.Itoa(record.ExitCode)
+			} else if record.Verdict == results.VerdictTimeout {
+				codes[id] = "10"
+			} else if record.Verdict == results.VerdictDiverged {
+				codes[id] = "panic"
+			}
+		default:
+			codes[id] = "fail"
+		}
+		verdicts[id] = record.Verdict
+	}
+
+	return codes, verdicts, true
+}
+
 func getReplayInfo(replayCode map[string]string, index string) (string, string, string, error) {
 	if _, ok := replayCode["AdvocateFailExplanationInfo"]; ok {
 		fmt.Println("Could not read")
@@ -0,0 +1,541 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: report.go
+// Brief: Create a static html report over all bugs found in a toolchain run
+//
+// Author: Erik Kassubek
+// Created: 2024-11-27
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"analyzer/results"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// style of all report pages
+const reportStyle = `<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+pre { margin: 0; }
+pre.cmd { background: #f4f4f4; padding: 8px; overflow-x: auto; }
+table.code td, table.code { border: none; padding: 0 8px; font-family: monospace; }
+table.code { background: #fafafa; margin-bottom: 1em; }
+tr.bug { background: #ffe0e0; }
+td.ln { color: #888; text-align: right; }
+.kw { color: #0033b3; font-weight: bold; }
+.str { color: #067d17; }
+.com { color: #8c8c8c; font-style: italic; }
+.num { color: #1750eb; }
+.confirmed { color: #b00000; font-weight: bold; }
+</style>
+`
+
+// script to filter the bugs in the index
+const reportScript = `<script>
+function filterBugs() {
+  var code = document.getElementById("code").value;
+  var pkg = document.getElementById("pkg").value;
+  var status = document.getElementById("status").value;
+  document.querySelectorAll("#bugs tbody tr").forEach(function(row) {
+    var show = (code === "" || row.dataset.code === code) &&
+      (pkg === "" || row.dataset.pkg === pkg) &&
+      (status === "" || row.dataset.status === status);
+    row.style.display = show ? "" : "none";
+  });
+}
+</script>
+`
+
+/*
+ * Info about a bug in the report
+ * Fields:
+ *   page (string): name of the page of the bug
+ *   bugType (string): result type
+ *   name (string): description of the result type
+ *   test (string): name of the test or program
+ *   pkg (string): package of the test or program
+ *   status (string): status of the replay
+ *   score (float64): confidence score
+ */
+type reportEntry struct {
+	page    string
+	bugType string
+	name    string
+	test    string
+	pkg     string
+	status  string
+	score   float64
+}
+
+/*
+ * Create a static html report over all bugs found in a run of the toolchain.
+ * The report contains an index of all bugs and one page per bug and is
+ * written into the report folder in the result folder.
+ * Args:
+ *   resultFolder (string): the advocateResult folder of the toolchain or the
+ *     result folder of one test
+ * Returns:
+ *   error: if the report could not be written
+ */
+func CreateReport(resultFolder string) error {
+	resultFolder, err := filepath.Abs(resultFolder)
+	if err != nil {
+		return err
+	}
+
+	folders, err := getReportFolders(resultFolder)
+	if err != nil {
+		return err
+	}
+
+	reportFolder := filepath.Join(resultFolder, "report")
+	if err := os.MkdirAll(reportFolder, 0755); err != nil {
+		return err
+	}
+
+	entries := make([]reportEntry, 0)
+	for i, folder := range folders {
+		folderEntries, err := writeReportPages(resultFolder, folder, i+1)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, folderEntries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].score > entries[j].score
+	})
+
+	return os.WriteFile(filepath.Join(reportFolder, "index.html"), []byte(reportIndex(entries)), 0644)
+}
+
+/*
+ * Get all folders with results in the result folder
+ * Args:
+ *   resultFolder (string): the result folder
+ * Returns:
+ *   []string: the folders containing a results_machine.log file
+ *   error: if the folder could not be read
+ */
+func getReportFolders(resultFolder string) ([]string, error) {
+	res := make([]string, 0)
+
+	if _, err := os.Stat(filepath.Join(resultFolder, "results_machine.log")); err == nil {
+		res = append(res, resultFolder)
+	}
+
+	entries, err := os.ReadDir(resultFolder)
+	if err != nil {
+		return res, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		folder := filepath.Join(resultFolder, entry.Name())
+		if _, err := os.Stat(filepath.Join(folder, "results_machine.log")); err == nil {
+			res = append(res, folder)
+		}
+	}
+
+	return res, nil
+}
+
+/*
+ * Get the status of the replay of a bug
+ * Args:
+ *   bugType (string): the result type
+ *   replay (map[string]string): the replay info (getRewriteInfo)
+ * Returns:
+ *   string: the status
+ */
+func replayStatus(bugType string, replay map[string]string) string {
+	if replay["verdict"] != "" {
+		return replay["verdict"]
+	}
+
+	if rewriteType[bugType] == "Actual" {
+		return "actual"
+	}
+
+	switch replay["exitCode"] {
+	case "double":
+		return "double"
+	case "panic":
+		return results.VerdictDiverged
+	}
+
+	exitCode, err := strconv.Atoi(replay["exitCode"])
+	if err != nil {
+		return "not replayed"
+	}
+	return results.VerdictFromExitCode(exitCode)
+}
+
+/*
+ * Get the path of the ADVOCATE folder from the path of the analyzer
+ * Returns:
+ *   string: the path, quoted for the shell, $ADVOCATE if it cannot be determined
+ */
+func getAdvocateRoot() string {
+	exe, err := os.Executable()
+	if err == nil && filepath.Base(filepath.Dir(exe)) == "analyzer" {
+		return shellQuote(filepath.Dir(filepath.Dir(exe)))
+	}
+	return "$ADVOCATE"
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_/.:=,+-]*$`)
+
+/*
+ * Quote an argument for the shell, if necessary
+ * Args:
+ *   arg (string): the argument
+ * Returns:
+ *   string: the quoted argument
+ */
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+/*
+ * Get the commands to rerecord and replay a bug with the toolchain
+ * Args:
+ *   folder (string): the result folder of the test
+ *   id (string): id of the bug
+ *   progInfo (map[string]string): info about the program (readProgInfo)
+ * Returns:
+ *   string: command to rerecord the test or program
+ *   string: command to replay the bug, empty if no rewritten trace exists
+ */
+func getReportCommands(folder string, id string, progInfo map[string]string) (string, string) {
+	if progInfo["file"] == "" {
+		return "", ""
+	}
+
+	advocate := getAdvocateRoot()
+	tool := advocate + "/toolchain/tool "
+	args := " -a " + advocate
+
+	record := ""
+	replay := ""
+	if progInfo["name"] != "" {
+		// the toolchain creates the advocateResult folder in the folder of the
+		// program and the result folder of each test in the advocateResult folder
+		progDir := filepath.Dir(filepath.Dir(folder))
+		record = tool + "test" + args + " -f " + shellQuote(progDir) +
+			" -n " + shellQuote(progInfo["name"])
+		replay = tool + "replay" + args + " -f " + shellQuote(progInfo["file"]) +
+			" -n " + shellQuote(progInfo["name"])
+	} else {
+		record = tool + "main" + args + " -f " + shellQuote(progInfo["file"]) +
+			" -E " + shellQuote(progInfo["exec"])
+		replay = tool + "replay" + args + " -f " + shellQuote(progInfo["file"]) +
+			" -E " + shellQuote(progInfo["exec"])
+	}
+
+	trace := filepath.Join(folder, "rewritten_trace_"+id)
+	replay += " -W " + shellQuote(trace)
+
+	// arguments, environment and input of a main program, the
+	// arguments of the program must be the last options
+	if progInfo["progOptions"] != "" {
+		record += " " + progInfo["progOptions"]
+		replay += " " + progInfo["progOptions"]
+	}
+
+	if _, err := os.Stat(trace); err != nil {
+		return record, ""
+	}
+
+	return record, replay
+}
+
+/*
+ * Write the pages of all bugs in a result folder of one test
+ * Args:
+ *   resultFolder (string): the result folder of the toolchain
+ *   folder (string): the result folder of the test
+ *   number (int): number of the folder, used for the names of the pages
+ * Returns:
+ *   []reportEntry: the entries for the index
+ *   error: if a page could not be written
+ */
+func writeReportPages(resultFolder string, folder string, number int) ([]reportEntry, error) {
+	replayCodes, verdicts, ok := getRecordCodes(folder)
+	if !ok {
+		replayCodes = getOutputCodes(folder)
+	}
+
+	progInfo, _ := readProgInfo(folder)
+	hl, _ := strconv.Atoi(progInfo["headerLine"])
+	objects, stacks := readTraceInfo(folder)
+	causal := getRecordCausal(folder)
+
+	test := progInfo["name"]
+	if test == "" {
+		test = filepath.Base(folder)
+	}
+	pkg := ""
+	if progInfo["file"] != "" {
+		pkg = filepath.Dir(progInfo["file"])
+		if rel, err := filepath.Rel(filepath.Dir(resultFolder), pkg); err == nil && !strings.HasPrefix(rel, "..") {
+			pkg = rel
+		}
+	}
+
+	bugResults, runs := readResults(folder, progInfo["file"], hl)
+
+	res := make([]reportEntry, 0)
+	for _, bug := range bugResults {
+		replay := getRewriteInfo(bug.bugType, replayCodes, bug.id)
+		replay["verdict"] = verdicts[bug.id]
+
+		if replay["exitCode"] == "double" {
+			continue
+		}
+
+		description := getBugTypeDescription(bug.bugType)
+		score := getScore(folder, bug.id, bug.result, replay, runs[results.PositionKey(bug.result)])
+
+		entry := reportEntry{
+			page:    "bug_" + strconv.Itoa(number) + "_" + bug.id + ".html",
+			bugType: bug.bugType,
+			name:    description["name"],
+			test:    test,
+			pkg:     pkg,
+			status:  replayStatus(bug.bugType, replay),
+			score:   score,
+		}
+
+		record, replayCmd := getReportCommands(folder, bug.id, progInfo)
+
+		page := reportPage(entry, bug, description, replay, progInfo, objects, stacks,
+			causal[bug.id], folder, record, replayCmd)
+		err := os.WriteFile(filepath.Join(resultFolder, "report", entry.page), []byte(page), 0644)
+		if err != nil {
+			return res, err
+		}
+
+		res = append(res, entry)
+	}
+
+	return res, nil
+}
+
+/*
+ * Get the head of a report page
+ * Args:
+ *   title (string): title of the page
+ * Returns:
+ *   string: the html code
+ */
+func reportHead(title string) string {
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" +
+		html.EscapeString(title) + "</title>\n" + reportStyle + "</head>\n<body>\n"
+}
+
+/*
+ * Get a select to filter the index
+ * Args:
+ *   id (string): id of the select
+ *   label (string): label of the select
+ *   values (map[string]bool): the values
+ * Returns:
+ *   string: the html code
+ */
+func reportFilter(id string, label string, values map[string]bool) string {
+	sorted := make([]string, 0, len(values))
+	for value := range values {
+		sorted = append(sorted, value)
+	}
+	sort.Strings(sorted)
+
+	res := "<label>" + label + " <select id=\"" + id + "\" onchange=\"filterBugs()\">"
+	res += "<option value=\"\">all</option>"
+	for _, value := range sorted {
+		res += "<option>" + html.EscapeString(value) + "</option>"
+	}
+	res += "</select></label>\n"
+	return res
+}
+
+/*
+ * Get the index of the report
+ * Args:
+ *   entries ([]reportEntry): all bugs, sorted by score
+ * Returns:
+ *   string: the html code
+ */
+func reportIndex(entries []reportEntry) string {
+	codes := make(map[string]bool)
+	pkgs := make(map[string]bool)
+	status := make(map[string]bool)
+	for _, entry := range entries {
+		codes[entry.bugType] = true
+		pkgs[entry.pkg] = true
+		status[entry.status] = true
+	}
+
+	res := reportHead("Advocate report")
+	res += "<h1>Advocate report</h1>\n"
+	res += "<p>" + strconv.Itoa(len(entries)) + " bugs found. The bugs are sorted by their confidence score.</p>\n"
+	res += "<p>" + reportFilter("code", "Bug code", codes) + reportFilter("pkg", "Package", pkgs) +
+		reportFilter("status", "Replay", status) + "</p>\n"
+
+	res += "<table id=\"bugs\">\n<thead><tr><th>Score</th><th>Code</th><th>Bug</th><th>Test</th>" +
+		"<th>Package</th><th>Replay</th></tr></thead>\n<tbody>\n"
+	for _, entry := range entries {
+		statusClass := ""
+		if entry.status == results.VerdictConfirmed {
+			statusClass = " class=\"confirmed\""
+		}
+		res += "<tr data-code=\"" + html.EscapeString(entry.bugType) + "\" data-pkg=\"" +
+			html.EscapeString(entry.pkg) + "\" data-status=\"" + html.EscapeString(entry.status) + "\">"
+		res += "<td>" + results.FormatScore(entry.score) + "</td>"
+		res += "<td>" + html.EscapeString(entry.bugType) + "</td>"
+		res += "<td><a href=\"" + html.EscapeString(entry.page) + "\">" + html.EscapeString(entry.name) + "</a></td>"
+		res += "<td>" + html.EscapeString(entry.test) + "</td>"
+		res += "<td>" + html.EscapeString(entry.pkg) + "</td>"
+		res += "<td" + statusClass + ">" + html.EscapeString(entry.status) + "</td>"
+		res += "</tr>\n"
+	}
+	res += "</tbody>\n</table>\n"
+	res += reportScript
+	res += "</body>\n</html>\n"
+
+	return res
+}
+
+/*
+ * Get the page of a bug
+ * Args:
+ *   entry (reportEntry): the entry of the bug in the index
+ *   bug (bugResult): the bug
+ *   description (map[string]string): description of the bug type
+ *   replay (map[string]string): the replay info (getRewriteInfo)
+ *   progInfo (map[string]string): info about the program (readProgInfo)
+ *   objects (map[string]string): object id -> description of the creation
+ *   stacks (map[string][]string): tPre -> call stack
+ *   causal (*results.Causal): causal explanation of the bug, nil if not available
+ *   folder (string): the result folder of the test
+ *   record (string): command to rerecord the bug
+ *   replayCmd (string): command to replay the bug
+ * Returns:
+ *   string: the html code
+ */
+func reportPage(entry reportEntry, bug bugResult, description map[string]string,
+	replay map[string]string, progInfo map[string]string, objects map[string]string,
+	stacks map[string][]string, causal *results.Causal, folder string, record string, replayCmd string) string {
+
+	res := reportHead(bug.bugType + " " + description["name"])
+	res += "<p><a href=\"index.html\">&larr; All bugs</a></p>\n"
+	res += "<h1>" + html.EscapeString(description["crit"]+": "+description["name"]) + "</h1>\n"
+	res += "<p>" + html.EscapeString(description["explanation"]) + "</p>\n"
+
+	res += "<table>\n"
+	res += "<tr><th>Code</th><td>" + html.EscapeString(bug.bugType) + "</td></tr>\n"
+	res += "<tr><th>Confidence score</th><td>" + results.FormatScore(entry.score) + "</td></tr>\n"
+	res += "<tr><th>Test/Program</th><td>" + html.EscapeString(entry.test) + "</td></tr>\n"
+	res += "<tr><th>File</th><td>" + html.EscapeString(progInfo["file"]) + "</td></tr>\n"
+	res += "<tr><th>Package</th><td>" + html.EscapeString(entry.pkg) + "</td></tr>\n"
+	res += "<tr><th>Results</th><td>" + html.EscapeString(folder) + "</td></tr>\n"
+	res += "</table>\n"
+
+	// the elements of the bug
+	res += "<h2>Bug elements</h2>\n"
+	keys := make([]int, 0, len(bug.positions))
+	for key := range bug.positions {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	for _, key := range keys {
+		res += "<h3>" + html.EscapeString(bug.elemType[key]) + "</h3>\n"
+		for j, pos := range bug.positions[key] {
+			if pos == ":-1" {
+				continue
+			}
+			res += "<p><code>" + html.EscapeString(pos) + "</code></p>\n"
+
+			// T:routine:objID:tPre:objType:file:line
+			arg := bug.args[key][j]
+			if stack, ok := stacks[arg[3]]; ok && stack[0] == arg[5]+":"+arg[6] {
+				res += "<p>Call stack:</p>\n<ul>\n"
+				for _, frame := range stack {
+					res += "<li><code>" + html.EscapeString(frame) + "</code></li>\n"
+				}
+				res += "</ul>\n"
+			}
+			if obj, ok := objects[arg[2]]; ok {
+				res += "<p>" + html.EscapeString(strings.ToUpper(obj[:1])+obj[1:]) + "</p>\n"
+			}
+
+			index := strings.LastIndex(pos, ":")
+			line, err := strconv.Atoi(pos[index+1:])
+			if err != nil {
+				continue
+			}
+			res += codeToHTML(pos[:index], line)
+		}
+	}
+
+	// the timeline of the involved routines
+	res += "<h2>Timeline</h2>\n"
+	res += "<p>The operations of the involved routines around the bug in the trace in which it was found. " +
+		"The elements of the bug are highlighted.</p>\n"
+	res += timelineToHTML(folder, bug.id, bug.args)
+
+	res += causalToHTML(causal)
+
+	// the replay
+	res += "<h2>Replay</h2>\n"
+	res += "<p>" + html.EscapeString(replay["description"]) + "</p>\n"
+	res += "<p>Status: <b>" + html.EscapeString(entry.status) + "</b></p>\n"
+	if replay["exitCodeExplanation"] != "" {
+		res += "<p>" + html.EscapeString(replay["exitCodeExplanation"]) + "</p>\n"
+	}
+
+	// the commands
+	res += "<h2>Commands</h2>\n"
+	if record == "" {
+		res += "<p>The commands are not available, because the program is unknown.</p>\n"
+	} else {
+		if getAdvocateRoot() == "$ADVOCATE" {
+			res += "<p>Set <code>ADVOCATE</code> to the path of the ADVOCATE folder to run the commands.</p>\n"
+		}
+		res += "<p>Rerecord and analyze the test/program:</p>\n"
+		res += "<pre class=\"cmd\">" + html.EscapeString(record) + "</pre>\n"
+		if replayCmd != "" {
+			res += "<p>Replay the rewritten trace of the bug:</p>\n"
+			res += "<pre class=\"cmd\">" + html.EscapeString(replayCmd) + "</pre>\n"
+		}
+	}
+
+	// the minimal example
+	if description["example"] != "" {
+		res += "<h2>Minimal example</h2>\n"
+		res += "<p>The following code is a minimal example to visualize the bug type. " +
+			"It is not the code where the bug was found.</p>\n"
+		res += "<pre class=\"cmd\">" + strings.Join(highlightGo([]byte(description["example"])), "\n") + "</pre>\n"
+	}
+
+	res += "</body>\n</html>\n"
+
+	return res
+}
@@ -0,0 +1,346 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: reportCode.go
+// Brief: Syntax highlighted code and routine timelines for the html report
+//
+// Author: Erik Kassubek
+// Created: 2024-11-27
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// number of lines before and after a bug element shown in the code
+const reportCodeLines = 10
+
+// number of elements before and after the bug elements shown in the timeline
+const reportTimelineElems = 5
+
+/*
+ * Highlight go code for html
+ * Args:
+ *   src ([]byte): the code
+ * Returns:
+ *   []string: the highlighted lines, html escaped
+ */
+func highlightGo(src []byte) []string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var res strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		class := ""
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		}
+		if class == "" {
+			continue
+		}
+
+		offset := file.Offset(pos)
+		end := min(offset+len(lit), len(src))
+		if offset < last {
+			continue
+		}
+
+		res.WriteString(html.EscapeString(string(src[last:offset])))
+
+		// spans must not cross lines, e.g. for raw strings or block comments
+		for i, part := range strings.Split(string(src[offset:end]), "\n") {
+			if i > 0 {
+				res.WriteString("\n")
+			}
+			res.WriteString("<span class=\"" + class + "\">" + html.EscapeString(part) + "</span>")
+		}
+		last = end
+	}
+	res.WriteString(html.EscapeString(string(src[last:])))
+
+	return strings.Split(res.String(), "\n")
+}
+
+/*
+ * Get the highlighted code around a line of a file as html table
+ * Args:
+ *   file (string): path to the file
+ *   line (int): the line of the bug element
+ * Returns:
+ *   string: the html code
+ */
+func codeToHTML(file string, line int) string {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return "<p>The code is not available.</p>\n"
+	}
+
+	lines := highlightGo(src)
+	start := max(line-reportCodeLines, 1)
+	end := min(line+reportCodeLines, len(lines))
+
+	res := "<table class=\"code\">\n"
+	for i := start; i <= end; i++ {
+		class := ""
+		if i == line {
+			class = " class=\"bug\""
+		}
+		res += "<tr" + class + "><td class=\"ln\">" + strconv.Itoa(i) + "</td><td><pre>" + lines[i-1] + "</pre></td></tr>\n"
+	}
+	res += "</table>\n"
+
+	return res
+}
+
+/*
+ * Element of the timeline of the routines involved in a bug
+ * Fields:
+ *   routine (int): the routine of the element
+ *   tPre (int): tPre of the element
+ *   label (string): description of the operation
+ *   pos (string): position of the operation, empty if not known
+ *   bug (bool): true if the element is part of the bug
+ */
+type timelineElem struct {
+	routine int
+	tPre    int
+	label   string
+	pos     string
+	bug     bool
+}
+
+/*
+ * Get a short description of a trace element
+ * Args:
+ *   fields ([]string): the fields of the element
+ * Returns:
+ *   string: the description
+ */
+func elemLabel(fields []string) string {
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	switch fields[0] {
+	case "G":
+		return "go " + field(2)
+	case "A":
+		return "atomic " + map[string]string{"L": "load", "S": "store", "A": "add",
+			"W": "swap", "C": "compare and swap"}[field(3)]
+	case "M":
+		return map[string]string{"L": "lock", "R": "rlock", "T": "trylock", "Y": "tryrlock",
+			"U": "unlock", "N": "runlock"}[field(5)] + " " + field(3)
+	case "W":
+		if field(4) == "W" {
+			return "wait " + field(3)
+		}
+		if strings.HasPrefix(field(5), "-") {
+			return "done " + field(3)
+		}
+		return "add " + field(3)
+	case "C":
+		return map[string]string{"S": "send", "R": "receive", "C": "close"}[field(4)] + " " + field(3)
+	case "S":
+		return "select " + field(3)
+	case "O":
+		return "once " + field(3)
+	case "N":
+		return map[string]string{"W": "wait", "S": "signal", "B": "broadcast"}[field(4)] + " " + field(3)
+	case "E":
+		return "end of routine"
+	case "I":
+		return "new " + map[string]string{"C": "channel", "M": "mutex", "R": "rw mutex",
+			"W": "wait group", "O": "once", "N": "cond"}[field(3)] + " " + field(2)
+	case "H":
+		return "sync edge " + field(4) + " " + field(3)
+	case "X":
+		return "replay signal"
+	}
+	return fields[0]
+}
+
+/*
+ * Get the folder of the trace in which a bug was found. The bugs of
+ * results_machine_[n] (id [n]_[index]) were found in the trace recorded
+ * during the replay of rewritten_trace_[n]. If this trace was not kept,
+ * rewritten_trace_[n] is used, whose elements have the same routines.
+ * Args:
+ *   path (string): path to the result folder
+ *   id (string): id of the bug
+ * Returns:
+ *   string: path to the trace folder, empty if it does not exist
+ */
+func bugTraceFolder(path string, id string) string {
+	names := []string{"advocateTrace"}
+	if nr, _, found := strings.Cut(id, "_"); found && nr != "0" {
+		names = []string{"advocateTraceReplay_" + nr, "rewritten_trace_" + nr}
+	}
+
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return filepath.Join(path, name)
+		}
+	}
+	return ""
+}
+
+/*
+ * Read the elements of the routines involved in a bug around the bug elements
+ * Args:
+ *   trace (string): path to the folder of the trace in which the bug was found
+ *   args (map[int][][]string): argument -> fields of the bug elements
+ * Returns:
+ *   []int: the involved routines, sorted
+ *   []timelineElem: the elements, sorted by tPre
+ */
+func readTimeline(trace string, args map[int][][]string) ([]int, []timelineElem) {
+	// routine -> tPre of the bug elements
+	bugElems := make(map[int]map[int]bool)
+	for _, arg := range args {
+		for _, fields := range arg {
+			// T:routine:objID:tPre:objType:file:line
+			routine, err1 := strconv.Atoi(fields[1])
+			tPre, err2 := strconv.Atoi(fields[3])
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			if _, ok := bugElems[routine]; !ok {
+				bugElems[routine] = make(map[int]bool)
+			}
+			bugElems[routine][tPre] = true
+		}
+	}
+
+	routines := make([]int, 0)
+	res := make([]timelineElem, 0)
+
+	for routine, tPres := range bugElems {
+		content, err := os.ReadFile(filepath.Join(trace, "trace_"+strconv.Itoa(routine)+".log"))
+		if err != nil {
+			continue
+		}
+
+		elems := make([]timelineElem, 0)
+		first, last := -1, -1
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Split(line, ",")
+			if len(fields) < 2 {
+				continue
+			}
+			tPre, err := strconv.Atoi(fields[1])
+			if err != nil {
+				continue
+			}
+
+			elem := timelineElem{routine: routine, tPre: tPre, label: elemLabel(fields), bug: tPres[tPre]}
+			if fields[0] != "A" && fields[0] != "E" && fields[0] != "X" {
+				// the position may be followed by the call stack
+				elem.pos = strings.Split(fields[len(fields)-1], ";")[0]
+			}
+
+			if elem.bug {
+				if first == -1 {
+					first = len(elems)
+				}
+				last = len(elems)
+			}
+			elems = append(elems, elem)
+		}
+
+		if first == -1 {
+			continue
+		}
+
+		routines = append(routines, routine)
+		start := max(first-reportTimelineElems, 0)
+		end := min(last+reportTimelineElems+1, len(elems))
+		res = append(res, elems[start:end]...)
+	}
+
+	sort.Ints(routines)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].tPre < res[j].tPre
+	})
+
+	return routines, res
+}
+
+/*
+ * Get the timeline of the routines involved in a bug as html table
+ * Args:
+ *   path (string): path to the result folder
+ *   id (string): id of the bug
+ *   args (map[int][][]string): argument -> fields of the bug elements
+ * Returns:
+ *   string: the html code
+ */
+func timelineToHTML(path string, id string, args map[int][][]string) string {
+	trace := bugTraceFolder(path, id)
+	if trace == "" {
+		return "<p>The trace is not available.</p>\n"
+	}
+
+	routines, elems := readTimeline(trace, args)
+	if len(elems) == 0 {
+		return "<p>The trace is not available.</p>\n"
+	}
+
+	column := make(map[int]int)
+	res := "<table class=\"timeline\">\n<tr><th>tPre</th>"
+	for i, routine := range routines {
+		column[routine] = i
+		res += "<th>Routine " + strconv.Itoa(routine) + "</th>"
+	}
+	res += "</tr>\n"
+
+	for _, elem := range elems {
+		class := ""
+		if elem.bug {
+			class = " class=\"bug\""
+		}
+		res += "<tr" + class + "><td class=\"ln\">" + strconv.Itoa(elem.tPre) + "</td>"
+		for i := range routines {
+			res += "<td>"
+			if i == column[elem.routine] {
+				res += "<b>" + html.EscapeString(elem.label) + "</b>"
+				if elem.pos != "" {
+					res += "<br><small title=\"" + html.EscapeString(elem.pos) + "\">" +
+						html.EscapeString(filepath.Base(elem.pos)) + "</small>"
+				}
+			}
+			res += "</td>"
+		}
+		res += "</tr>\n"
+	}
+	res += "</table>\n"
+
+	return res
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: reportCode_test.go
+// Brief: Tests for reportCode.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-27
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHighlightGo(t *testing.T) {
+	src := "package main\n\n// a <comment>\nvar s = `a\nb` + \"x\" // 1\nvar n = 42\n"
+	expected := []string{
+		"<span class=\"kw\">package</span> main",
+		"",
+		"<span class=\"com\">// a &lt;comment&gt;</span>",
+		"<span class=\"kw\">var</span> s = <span class=\"str\">`a</span>",
+		"<span class=\"str\">b`</span> + <span class=\"str\">&#34;x&#34;</span> <span class=\"com\">// 1</span>",
+		"<span class=\"kw\">var</span> n = <span class=\"num\">42</span>",
+		"",
+	}
+
+	res := highlightGo([]byte(src))
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Incorrect highlighting. Expected\n%s\nGot\n%s", strings.Join(expected, "\n"), strings.Join(res, "\n"))
+	}
+}
+
+func TestElemLabel(t *testing.T) {
+	var tests = []struct {
+		name     string
+		elem     string
+		expected string
+	}{
+		{"Send", "C,5,7,3,S,f,1,0,0,main.go:10", "send 3"},
+		{"Lock", "M,5,7,2,-,L,t,1,main.go:12", "lock 2"},
+		{"Done", "W,5,7,4,A,-1,0,main.go:14", "done 4"},
+		{"Wait", "W,5,7,4,W,0,0,main.go:15", "wait 4"},
+		{"New channel", "I,5,3,C,2,main.go:16", "new channel 3"},
+		{"Unknown", "Z,5", "Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := elemLabel(strings.Split(test.elem, ",")); res != test.expected {
+				t.Errorf("Incorrect label. Expected %s. Got %s", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestBugTraceFolder(t *testing.T) {
+	path := t.TempDir()
+	for _, name := range []string{"advocateTrace", "rewritten_trace_1", "rewritten_trace_2", "advocateTraceReplay_2"} {
+		if err := os.Mkdir(filepath.Join(path, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tests = []struct {
+		name     string
+		id       string
+		expected string
+	}{
+		{"Recorded trace", "3", "advocateTrace"},
+		{"Rewritten trace", "1_2", "rewritten_trace_1"},
+		{"Rerecorded trace", "2_1", "advocateTraceReplay_2"},
+		{"Missing trace", "4_1", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := ""
+			if test.expected != "" {
+				expected = filepath.Join(path, test.expected)
+			}
+			if res := bugTraceFolder(path, test.id); res != expected {
+				t.Errorf("Incorrect trace. Expected %s. Got %s", expected, res)
+			}
+		})
+	}
+}
+
+func TestReadTimeline(t *testing.T) {
+	trace := t.TempDir()
+	routine1 := "G,1,2,main.go:5\nC,2,3,1,S,f,1,0,0,main.go:6\nC,4,5,1,S,f,2,0,0,main.go:7\nE,6\n"
+	routine2 := "C,3,4,1,R,f,1,0,0,main.go:20\n"
+	for routine, content := range map[string]string{"1": routine1, "2": routine2, "3": "E,1\n"} {
+		if err := os.WriteFile(filepath.Join(trace, "trace_"+routine+".log"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := map[int][][]string{
+		0: {strings.Split("T:1:1:4:CS:main.go:7", ":")},
+		1: {strings.Split("T:2:1:3:CR:main.go:20", ":")},
+	}
+
+	routines, elems := readTimeline(trace, args)
+
+	if !reflect.DeepEqual(routines, []int{1, 2}) {
+		t.Errorf("Incorrect routines. Expected [1 2]. Got %v", routines)
+	}
+
+	expected := []timelineElem{
+		{1, 1, "go 2", "main.go:5", false},
+		{1, 2, "send 1", "main.go:6", false},
+		{2, 3, "receive 1", "main.go:20", true},
+		{1, 4, "send 1", "main.go:7", true},
+		{1, 6, "end of routine", "", false},
+	}
+	if !reflect.DeepEqual(elems, expected) {
+		t.Errorf("Incorrect timeline. Expected %v. Got %v", expected, elems)
+	}
+}
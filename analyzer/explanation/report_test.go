@@ -0,0 +1,76 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: report_test.go
+// Brief: Tests for report.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-27
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"analyzer/results"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	var tests = []struct {
+		name     string
+		arg      string
+		expected string
+	}{
+		{"Safe", "/home/user/prog/main.go", "/home/user/prog/main.go"},
+		{"Empty", "", ""},
+		{"Space", "/home/user/my prog", "'/home/user/my prog'"},
+		{"Variable", "$HOME/prog", "'$HOME/prog'"},
+		{"Quote", "it's", `'it'\''s'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := shellQuote(test.arg); res != test.expected {
+				t.Errorf("Incorrect quote. Expected %s. Got %s", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestReplayStatus(t *testing.T) {
+	var tests = []struct {
+		name     string
+		bugType  string
+		replay   map[string]string
+		expected string
+	}{
+		{"Verdict of record", "P01", map[string]string{"verdict": results.VerdictTimeout, "exitCode": "20"}, results.VerdictTimeout},
+		{"Actual", "A01", map[string]string{}, "actual"},
+		{"Double", "P01", map[string]string{"exitCode": "double"}, "double"},
+		{"Panic", "P01", map[string]string{"exitCode": "panic"}, results.VerdictDiverged},
+		{"Confirmed", "P01", map[string]string{"exitCode": "30"}, results.VerdictConfirmed},
+		{"Not reproduced", "P01", map[string]string{"exitCode": "0"}, results.VerdictNotReproduced},
+		{"Not replayed", "P01", map[string]string{}, "not replayed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := replayStatus(test.bugType, test.replay); res != test.expected {
+				t.Errorf("Incorrect status. Expected %s. Got %s", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestReportFilter(t *testing.T) {
+	res := reportFilter("code", "Bug code", map[string]bool{"P01": true, "A01": true, "<x>": true})
+
+	expected := "<option value=\"\">all</option><option>&lt;x&gt;</option><option>A01</option><option>P01</option>"
+	if !strings.Contains(res, expected) {
+		t.Errorf("Incorrect options. Expected %s in %s", expected, res)
+	}
+	if !strings.HasPrefix(res, "<label>Bug code <select id=\"code\"") {
+		t.Errorf("Incorrect select: %s", res)
+	}
+}
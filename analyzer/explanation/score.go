@@ -0,0 +1,132 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: score.go
+// Brief: Confidence score of the bugs and overview sorted by the score
+//
+// Author: Erik Kassubek
+// Created: 2024-11-25
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"analyzer/results"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Info about a written bug report, used for the overview
+ * Fields:
+ *   id (string): id of the bug
+ *   bugType (string): result type of the bug
+ *   name (string): description of the bug type
+ *   score (float64): confidence score
+ */
+type bugReportInfo struct {
+	id      string
+	bugType string
+	name    string
+	score   float64
+}
+
+/*
+ * Count in how many runs each result was found. Each result machine file
+ * in the folder is the result of one run
+ * Args:
+ *   resultFiles: the result machine files
+ * Returns:
+ *   map[string]int: key of the result (results.PositionKey) -> number of runs
+ */
+func countRuns(resultFiles []string) map[string]int {
+	res := make(map[string]int)
+	for _, resultFile := range resultFiles {
+		content, err := os.ReadFile(resultFile)
+		if err != nil {
+			continue
+		}
+
+		counted := make(map[string]bool)
+		for _, line := range strings.Split(string(content), "\n") {
+			if !results.IsResultLine(line) {
+				continue
+			}
+			key := results.PositionKey(line)
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			res[key]++
+		}
+	}
+	return res
+}
+
+/*
+ * Get the confidence score of a bug
+ * Args:
+ *   path: path to the folder with the results
+ *   id: id of the bug
+ *   result: the result line in the machine readable format
+ *   replay: the replay info (getRewriteInfo)
+ *   runs: number of runs in which the bug was found
+ * Returns:
+ *   float64: the score between 0 and 1
+ */
+func getScore(path string, id string, result string, replay map[string]string, runs int) float64 {
+	bugType := strings.Split(result, ",")[0]
+	actual := rewriteType[bugType] == "Actual"
+
+	rewritten := 0.0
+	if _, err := os.Stat(filepath.Join(path, "rewritten_trace_"+id, "rewrite_info.log")); err == nil || actual {
+		rewritten = 1
+	}
+
+	replayScore := 0.5 // no replay result available
+	if actual {
+		replayScore = 1
+	} else if exitCode, err := strconv.Atoi(replay["exitCode"]); err == nil {
+		if exitCode >= 20 {
+			replayScore = 1
+		} else if exitCode == 0 {
+			replayScore = 0
+		} else {
+			replayScore = 0.25
+		}
+	}
+
+	return results.ConfidenceScore(results.AnalysisScoreMachine(result), rewritten, replayScore, runs)
+}
+
+/*
+ * Write an overview over all bug reports, sorted by the confidence score
+ * Args:
+ *   path: path to the folder with the results
+ *   reports: the written bug reports
+ * Returns:
+ *   error: if the file could not be written
+ */
+func writeOverview(path string, reports []bugReportInfo) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].score > reports[j].score
+	})
+
+	res := "# Found bugs\n\n"
+	res += "The bugs are sorted by their confidence score.\n\n"
+	res += "| Score | Type | Bug | Report |\n"
+	res += "| --- | --- | --- | --- |\n"
+	for _, report := range reports {
+		res += "| " + results.FormatScore(report.score) + " | " + report.bugType + " | " +
+			report.name + " | [bug_" + report.id + "](bug_" + report.id + ".md) |\n"
+	}
+
+	return os.WriteFile(filepath.Join(path, "bugs", "overview.md"), []byte(res), 0644)
+}
@@ -0,0 +1,80 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: traceInfo.go
+// Brief: Read additional information about the bug elements from the trace
+//
+// Author: Erik Kassubek
+// Created: 2024-11-18
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"analyzer/results"
+	"analyzer/utils"
+)
+
+/*
+ * Read the creation of all objects and the call stacks of all elements
+ * from the recorded trace
+ * Args:
+ *   path (string): path to the folder containing the advocateTrace folder
+ * Returns:
+ *   map[string]string: object id -> description of the creation
+ *   map[string][]string: tPre -> call stack, only for elements with recorded stack
+ */
+func readTraceInfo(path string) (map[string]string, map[string][]string) {
+	objects := make(map[string]string)
+	stacks := make(map[string][]string)
+
+	files, err := filepath.Glob(filepath.Join(path, "advocateTrace", "trace_*.log"))
+	if err != nil {
+		return objects, stacks
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		for _, elem := range strings.Split(string(content), "\n") {
+			fields := strings.Split(elem, ",")
+			if len(fields) < 2 {
+				continue
+			}
+
+			// the position is the last field, it may contain the call stack
+			if fields[0] != "E" && strings.Contains(fields[len(fields)-1], ";") {
+				stack := strings.Split(fields[len(fields)-1], ";")
+				stacks[fields[1]] = stack
+				fields[len(fields)-1] = stack[0]
+			}
+
+			// I,tPost,id,objType,elemType,qSize,pos
+			if fields[0] != "I" || len(fields) != 7 {
+				continue
+			}
+
+			qSize, err := strconv.Atoi(fields[5])
+			if err != nil {
+				continue
+			}
+
+			elemType, err := utils.UnescapeField(fields[4])
+			if err != nil {
+				continue
+			}
+
+			objects[fields[2]] = results.ObjectDescription(fields[3], elemType, qSize, fields[6])
+		}
+	}
+
+	return objects, stacks
+}
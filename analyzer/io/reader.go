@@ -15,18 +15,26 @@ package io
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"analyzer/analysis"
+	"analyzer/memory"
 )
 
+// name of the file with the information about a recording region
+const regionInfoFile = "region_info.txt"
+
 /*
  * Create the trace from all files in a folder.
  * Args:
+ *   ctx (context.Context): If the context is canceled, e.g. by the timeout,
+ *     the reading is stopped and the analysis is marked as incomplete
  *   filePath (string): The path to the folder
  *   ignoreAtomics (bool): If atomic operations should be ignored
  * Returns:
@@ -34,7 +42,7 @@ import (
  *   bool: True if the trace contains any elems
  *   error: An error if the trace could not be created
  */
-func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, bool, error) {
+func CreateTraceFromFiles(ctx context.Context, filePath string, ignoreAtomics bool) (int, bool, error) {
 	numberIds := 0
 
 	println("Read trace from " + filePath)
@@ -55,13 +63,14 @@ func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, bool, error
 			continue
 		}
 
+		// ignore files that are not traces, e.g. the info of a recording region
 		routine, err := getRoutineFromFileName(file.Name())
 		if err != nil {
-			return 0, containsElems, nil
+			continue
 		}
 		numberIds = max(numberIds, routine)
 
-		containsElem, err := CreateTraceFromFile(filePath+"/"+file.Name(), routine, ignoreAtomics)
+		containsElem, err := CreateTraceFromFile(ctx, filePath+"/"+file.Name(), routine, ignoreAtomics)
 		if err != nil {
 			return 0, containsElems, err
 		}
@@ -70,14 +79,86 @@ func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, bool, error
 		}
 	}
 
+	numberIds, err = addPreExistingRoutines(filepath.Join(filePath, regionInfoFile), numberIds)
+	if err != nil {
+		return 0, containsElems, err
+	}
+
 	analysis.Sort()
 
 	return numberIds, containsElems, nil
 }
 
+/*
+ * Read the routines that were already running at the start of a recording
+ * region from the region_info.txt file of the trace folder of the region
+ * Args:
+ *   filePath (string): The path to the region_info.txt file
+ * Returns:
+ *   []int: The ids of the routines, nil if the file does not exist
+ *   error: An error if the file contains an invalid routine id
+ */
+func readRegionInfo(filePath string) ([]int, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil
+	}
+
+	res := make([]int, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		value, found := strings.CutPrefix(line, "pre-existing routines:")
+		if !found {
+			continue
+		}
+
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			routine, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, errors.New("Invalid pre-existing routine in " + filePath + ": " + field)
+			}
+			res = append(res, routine)
+		}
+	}
+
+	return res, nil
+}
+
+/*
+ * The routines that were already running at the start of a recording region
+ * have no fork in the trace of the region. Add a synthetic routine, that
+ * represents the program before the region and forks all of them before the
+ * first element of the region.
+ * Args:
+ *   filePath (string): The path to the region_info.txt file
+ *   numberIds (int): The number of routines in the trace
+ * Returns:
+ *   int: The number of routines including the synthetic routine
+ *   error: An error if the file could not be read
+ */
+func addPreExistingRoutines(filePath string, numberIds int) (int, error) {
+	running, err := readRegionInfo(filePath)
+	if err != nil || len(running) == 0 {
+		return numberIds, err
+	}
+
+	routine := numberIds + 1
+	for _, id := range running {
+		if err := analysis.AddTraceElementFork(routine, "1", strconv.Itoa(id), ""); err != nil {
+			return numberIds, err
+		}
+	}
+
+	return routine, nil
+}
+
 /*
  * Read and build the trace from a file
  * Args:
+ *   ctx (context.Context): If the context is canceled, the reading is stopped
  *   filePath (string): The path to the log file
  *   routine (int): The routine id
  *   ignoreAtomics (bool): If atomic operations should be ignored
@@ -85,7 +166,7 @@ func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, bool, error
  *   bool: true if the trace contains any values
  *	 error: An error if the trace could not be created
  */
-func CreateTraceFromFile(filePath string, routine int, ignoreAtomics bool) (bool, error) {
+func CreateTraceFromFile(ctx context.Context, filePath string, routine int, ignoreAtomics bool) (bool, error) {
 	log.Print("Create trace from file " + filePath)
 
 	file, err := os.Open(filePath)
@@ -98,8 +179,20 @@ func CreateTraceFromFile(filePath string, routine int, ignoreAtomics bool) (bool
 
 	containsElem := false
 	for scanner.Scan() {
+		if memory.WasCanceled() {
+			file.Close()
+			analysis.SetAnalysisIncomplete()
+			return containsElem, errors.New("Reading of trace canceled because of low memory")
+		}
+
+		if ctx.Err() != nil {
+			file.Close()
+			analysis.SetAnalysisIncomplete()
+			return containsElem, errors.New("Reading of trace canceled because of timeout")
+		}
+
 		line := scanner.Text()
-		processElement(line, routine, ignoreAtomics)
+		processElement(line, routine, ignoreAtomics || memory.DropAtomics())
 		containsElem = true
 	}
 
@@ -126,6 +219,13 @@ func processElement(element string, routine int, ignoreAtomics bool) error {
 		return errors.New("Element is empty")
 	}
 	fields := strings.Split(element, ",")
+
+	// the position is the last field, it may contain the call stack
+	var stack []string
+	if fields[0] != "E" {
+		fields[len(fields)-1], stack = analysis.SplitPosStack(fields[len(fields)-1])
+	}
+
 	var err error
 	switch fields[0] {
 	case "A":
@@ -155,6 +255,18 @@ func processElement(element string, routine int, ignoreAtomics bool) error {
 			fields[4], fields[5])
 	case "E":
 		err = analysis.AddTraceElementRoutineEnd(routine, fields[1])
+	case "I":
+		if len(fields) != 7 {
+			return errors.New("Creation element has wrong number of fields: " + element)
+		}
+		err = analysis.AddTraceElementNew(routine, fields[1], fields[2], fields[3],
+			fields[4], fields[5], fields[6])
+	case "H":
+		if len(fields) != 6 {
+			return errors.New("Sync edge element has wrong number of fields: " + element)
+		}
+		err = analysis.AddTraceElementSyncEdge(routine, fields[1], fields[2], fields[3],
+			fields[4], fields[5])
 	default:
 		return errors.New("Unknown element type in: " + element)
 	}
@@ -163,6 +275,14 @@ func processElement(element string, routine int, ignoreAtomics bool) error {
 		return err
 	}
 
+	if stack != nil {
+		tPre, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return err
+		}
+		analysis.SetStack(tPre, stack)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: reader_bench_test.go
+// Brief: Benchmarks for reading and analyzing traces
+//
+// Author: Erik Kassubek
+// Created: 2024-11-20
+//
+// License: BSD-3-Clause
+
+package io
+
+import (
+	"analyzer/analysis"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// folder with the eval traces, relative to this package
+const evalTraceFolder = "../../eval/data"
+
+/*
+ * Create a synthetic trace, where the main routine spawns n short lived
+ * routines, that each send one message to the main routine over an
+ * unbuffered channel.
+ * Args:
+ *   dir (string): The folder to write the trace into
+ *   n (int): Number of spawned routines
+ * Returns:
+ *   error: An error if the trace could not be written
+ */
+func writeSyntheticTrace(dir string, n int) error {
+	var mainTrace strings.Builder
+	for i := 0; i < n; i++ {
+		mainTrace.WriteString("G," + strconv.Itoa(i+1) + "," +
+			strconv.Itoa(i+2) + ",main.go:10\n")
+	}
+
+	for i := 0; i < n; i++ {
+		t := n + 1 + 4*i
+		oID := strconv.Itoa(i + 1)
+		tPost := strconv.Itoa(t + 2)
+
+		mainTrace.WriteString("C," + strconv.Itoa(t+1) + "," + tPost + ",1,R,f," +
+			oID + ",0,main.go:20\n")
+
+		child := "C," + strconv.Itoa(t) + "," + tPost + ",1,S,f," + oID +
+			",0,main.go:15\nE," + strconv.Itoa(t+3) + "\n"
+		err := os.WriteFile(filepath.Join(dir, "trace_"+strconv.Itoa(i+2)+".log"),
+			[]byte(child), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "trace_1.log"), []byte(mainTrace.String()), 0644)
+}
+
+/*
+ * Get all trace folders in the eval data, i.e. all folders that
+ * contain a trace_*.log file. Empty trace files are not stored, so the
+ * folder may not contain trace_1.log.
+ * Returns:
+ *   []string: The trace folders
+ */
+func evalTraces() []string {
+	res := make([]string, 0)
+	filepath.Walk(evalTraceFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if files, _ := filepath.Glob(filepath.Join(path, "trace_*.log")); len(files) > 0 {
+			res = append(res, path)
+		}
+		return nil
+	})
+	return res
+}
+
+/*
+ * Read and analyze a trace b.N times
+ * Args:
+ *   b (*testing.B): The benchmark
+ *   path (string): Path to the trace folder
+ */
+func benchmarkAnalysis(b *testing.B, path string) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		analysis.ClearTrace()
+		analysis.ClearData()
+
+		numberOfRoutines, _, err := CreateTraceFromFiles(context.Background(), path, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		analysis.SetNumberOfRoutines(numberOfRoutines)
+		analysis.RunAnalysis(context.Background(), false, false, map[string]bool{"all": true})
+	}
+}
+
+func BenchmarkAnalysisSynthetic(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000, 20000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			dir := b.TempDir()
+			if err := writeSyntheticTrace(dir, n); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			benchmarkAnalysis(b, dir)
+		})
+	}
+}
+
+func BenchmarkAnalysisEval(b *testing.B) {
+	traces := evalTraces()
+	if len(traces) == 0 {
+		b.Skip("no traces found in " + evalTraceFolder)
+	}
+
+	for _, path := range traces {
+		b.Run(filepath.Base(path), func(b *testing.B) {
+			benchmarkAnalysis(b, path)
+		})
+	}
+}
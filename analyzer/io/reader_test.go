@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: reader_test.go
+// Brief: Tests for reader.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package io
+
+import (
+	"analyzer/analysis"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessElementMalformed(t *testing.T) {
+	var tests = []struct {
+		name    string
+		element string
+	}{
+		{"Empty", ""},
+		{"Unknown type", "Q,1,2"},
+		{"Creation too short", "I,12,3,C"},
+		{"Creation too long", "I,12,3,C,func(int, int),0,a.go:1"},
+		{"Sync edge too short", "H,12,3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := processElement(test.element, 1, false); err == nil {
+				t.Errorf("Expected error for %s", test.element)
+			}
+		})
+	}
+}
+
+func TestCreateTraceFromFilesCanceled(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeSyntheticTrace(dir, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	analysis.ClearTrace()
+	_, _, err := CreateTraceFromFiles(ctx, dir, false)
+	if err == nil {
+		t.Errorf("Expected error for canceled context")
+	}
+	if !analysis.IsAnalysisIncomplete() {
+		t.Errorf("Analysis should be marked as incomplete")
+	}
+
+	analysis.ClearTrace()
+}
+
+func TestCreateTraceFromFilesRegion(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"trace_1.log":     "C,10,12,1,R,f,1,0,main.go:20\n",
+		"trace_3.log":     "C,11,12,1,S,f,1,0,main.go:15\n",
+		"trace_4.log":     "",
+		"region_info.txt": "region: request\nnumber: 1\npre-existing routines: 1,3,4\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis.ClearTrace()
+	defer analysis.ClearTrace()
+
+	numberIds, containsElems, err := CreateTraceFromFiles(context.Background(), dir, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if !containsElems {
+		t.Errorf("Trace should contain elements")
+	}
+	if numberIds != 5 {
+		t.Errorf("Incorrect number of routines. Expected 5. Got %d", numberIds)
+	}
+
+	expected := []string{"G,1,1,", "G,1,3,", "G,1,4,"}
+	trace := analysis.GetTraceFromId(5)
+	if len(trace) != len(expected) {
+		t.Fatalf("Incorrect synthetic routine. Expected %d elements. Got %d", len(expected), len(trace))
+	}
+	for i, elem := range trace {
+		if elem.ToString() != expected[i] {
+			t.Errorf("Incorrect fork %d. Expected %s. Got %s", i, expected[i], elem.ToString())
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "region_info.txt"), []byte("pre-existing routines: 1,x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	analysis.ClearTrace()
+	if _, _, err := CreateTraceFromFiles(context.Background(), dir, false); err == nil {
+		t.Errorf("Expected error for invalid region info")
+	}
+}
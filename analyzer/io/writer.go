@@ -52,10 +52,11 @@ func CopyFolder(source string, dest string) {
 /*
  * Write the trace to a file
  * Args:
+ *   trace (*analysis.TraceView): The trace to write
  *   path (string): The path to the file to write to
  *   numberRoutines (int): The number of routines in the trace
  */
-func WriteTrace(path string, numberRoutines int) error {
+func WriteTrace(trace *analysis.TraceView, path string, numberRoutines int) error {
 	// delete folder if exists
 	if _, err := os.Stat(path); err == nil {
 		println(path + " already exists. Delete folder " + path)
@@ -86,19 +87,20 @@ func WriteTrace(path string, numberRoutines int) error {
 
 			// write trace
 			// println("Write trace to " + fileName + "...")
-			trace := analysis.GetTraceFromId(i)
+			// the routine may be shared with other views, sort a copy
+			routineTrace := append([]analysis.TraceElement(nil), trace.GetTraceFromId(i)...)
 
 			// sort trace by tPre
-			sort.Slice(trace, func(i, j int) bool {
-				return trace[i].GetTPre() < trace[j].GetTPre()
+			sort.Slice(routineTrace, func(i, j int) bool {
+				return routineTrace[i].GetTPre() < routineTrace[j].GetTPre()
 			})
 
-			for index, element := range trace {
+			for index, element := range routineTrace {
 				elementString := element.ToString()
 				if _, err := file.WriteString(elementString); err != nil {
 					panic(err)
 				}
-				if index < len(trace)-1 {
+				if index < len(routineTrace)-1 {
 					if _, err := file.WriteString("\n"); err != nil {
 						panic(err)
 					}
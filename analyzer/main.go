@@ -11,6 +11,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -25,13 +26,12 @@ import (
 	"analyzer/complete"
 	"analyzer/explanation"
 	"analyzer/io"
+	"analyzer/memory"
 	"analyzer/results"
 	"analyzer/rewriter"
 	"analyzer/stats"
 	timemeasurement "analyzer/timeMeasurement"
 	"analyzer/utils"
-
-	"github.com/shirou/gopsutil/mem"
 )
 
 func main() {
@@ -55,6 +55,22 @@ func main() {
 	outR := flag.String("outR", "results_readable", "Name for the result readable file")
 	outT := flag.String("outT", "rewritten_trace", "Name for the rewritten traces")
 	ignoreRewrite := flag.String("ignoreRew", "", "Path to a result machine file. If a found bug is already in this file, it will not be rewritten")
+	include := flag.String("include", os.Getenv("ADVOCATE_INCLUDE"), "Comma separated list of patterns. If set, only bugs in files matching one of the patterns are reported. "+
+		"Default: value of ADVOCATE_INCLUDE")
+	exclude := flag.String("exclude", os.Getenv("ADVOCATE_EXCLUDE"), "Comma separated list of patterns. Bugs with elements in files matching one of the patterns are not reported. "+
+		"Default: value of ADVOCATE_EXCLUDE")
+	baseline := flag.String("baseline", os.Getenv("ADVOCATE_BASELINE"), "Path to a baseline file with known results. Known results are shown separately and are not rewritten. "+
+		"Default: value of ADVOCATE_BASELINE")
+	writeBaseline := flag.String("writeBaseline", "", "Write all found results to this file, so it can be used as baseline")
+	memRAM := flag.Int("memRAM", 1024, "Minimum available RAM in MB. If less RAM is available, the analysis is stopped and the results found so far are written")
+	memSwap := flag.Int("memSwap", 200, "Minimum free swap in MB, if the system uses swap. If less swap is free, the analysis is stopped and the results found so far are written")
+	memDegrade := flag.Int("memDegrade", 2048, "Available RAM in MB under which the analysis is degraded step by step "+
+		"(drop atomics, spill vector clocks to disk, disable expensive scenarios)")
+	workers := flag.Int("workers", 0, "Number of workers for the scenarios that are checked after the trace has been processed and for rewriting the traces. "+
+		"0 for the number of cpus, 1 to run them sequentially")
+
+	configFile := flag.String("config", "", "Path to the configuration file. Flags set on the command line override the file. "+
+		"Default: value of ADVOCATE_CONFIG or advocate.toml in the current directory")
 
 	scenarios := flag.String("s", "", "Select which analysis scenario to run, e.g. -s srd for the option s, r and d."+
 		"If not set, all scenarios are run.\n"+
@@ -66,13 +82,13 @@ func main() {
 		"\tb: Concurrent receive on channel\n"+
 		"\tl: Leaking routine\n"+
 		"\tp: Select case without partner\n"+
-		"\tu: Unlock of unlocked mutex\n",
+		"\tu: Unlock of unlocked mutex\n"+
+		"Custom checkers are selected by name, separated by comma, e.g. -s sr,[name]. "+
+		"Registered checkers: "+strings.Join(checkerNames(), ", ")+"\n",
 	)
 	// "\tc: Cyclic deadlock\n",
 	// "\tm: Mixed deadlock\n"
 
-	go memorySupervisor() // panic if not enough ram
-
 	flag.Parse()
 
 	var mode string
@@ -81,7 +97,7 @@ func main() {
 		flag.CommandLine.Parse(os.Args[2:])
 	} else {
 		fmt.Printf("No mode selected")
-		fmt.Printf("Select one mode from 'run', 'stats', 'explain' or 'check'")
+		fmt.Printf("Select one mode from 'run', 'stats', 'explain', 'check' or 'report'")
 		printHelp()
 	}
 
@@ -90,6 +106,16 @@ func main() {
 		return
 	}
 
+	if err := applyConfig(*configFile); err != nil {
+		fmt.Println("Could not read configuration: " + err.Error())
+		return
+	}
+
+	memory.SetLimits(*memRAM, *memSwap, *memDegrade)
+	go memory.Supervisor() // degrade or stop the analysis if not enough ram
+
+	analysis.SetNumberOfWorkers(*workers)
+
 	folderTrace, err := filepath.Abs(*pathTrace)
 	if err != nil {
 		panic(err)
@@ -112,6 +138,13 @@ func main() {
 		*ignoreRewrite = filepath.Join(*resultFolder, *ignoreRewrite)
 	}
 
+	results.SetFilter(utils.SplitPatterns(*include), utils.SplitPatterns(*exclude))
+
+	if err := results.SetBaseline(*baseline); err != nil {
+		fmt.Println("Could not read baseline: " + err.Error())
+		return
+	}
+
 	switch mode {
 	case "stats":
 		modeStats(*pathTrace, *progName, *testName)
@@ -119,13 +152,15 @@ func main() {
 		modeExplain(pathTrace, !*rewriteAll)
 	case "check":
 		modeCheck(resultFolderTool, programPath)
+	case "report":
+		modeReport(resultFolderTool)
 	case "run":
 		modeRun(pathTrace, noPrint, noRewrite, scenarios, outReadable,
 			outMachine, ignoreAtomics, fifo, ignoreCriticalSection,
-			noWarning, rewriteAll, folderTrace, newTrace, timeout, ignoreRewrite)
+			noWarning, rewriteAll, folderTrace, newTrace, timeout, ignoreRewrite, workers, writeBaseline)
 	default:
 		fmt.Printf("Unknown mode %s", os.Args[1])
-		fmt.Printf("Select one mode from 'run', 'stats', 'explain' or 'check'")
+		fmt.Printf("Select one mode from 'run', 'stats', 'explain', 'check' or 'report'")
 		printHelp()
 	}
 }
@@ -161,6 +196,18 @@ func modeExplain(pathTrace *string, ignoreDouble bool) {
 	}
 }
 
+func modeReport(resultFolderTool *string) {
+	if *resultFolderTool == "" {
+		fmt.Println("Please provide the path to the advocateResult folder created by the pipeline. Set with -R [folder]")
+		return
+	}
+
+	err := explanation.CreateReport(*resultFolderTool)
+	if err != nil {
+		log.Println("Error creating report: ", err.Error())
+	}
+}
+
 func modeCheck(resultFolderTool, programPath *string) {
 	if *resultFolderTool == "" {
 		fmt.Println("Please provide the path to the advocateResult folder created by the pipeline. Set with -R [folder]")
@@ -182,7 +229,8 @@ func modeCheck(resultFolderTool, programPath *string) {
 func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 	scenarios *string, outReadable string, outMachine string,
 	ignoreAtomics *bool, fifo *bool, ignoreCriticalSection *bool,
-	noWarning *bool, rewriteAll *bool, folderTrace string, newTrace string, timeout *int, ignoreRewrite *string) {
+	noWarning *bool, rewriteAll *bool, folderTrace string, newTrace string, timeout *int, ignoreRewrite *string,
+	workers *int, writeBaseline *string) {
 	// printHeader()
 
 	if *pathTrace == "" {
@@ -194,12 +242,13 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 		*noRewrite = true
 	}
 
-	// set timeout
+	// set timeout. If the timeout is reached, the analysis is stopped and
+	// the results found until then are written
+	ctx := context.Background()
 	if timeout != nil && *timeout > 0 {
-		go func() {
-			<-time.After(time.Duration(*timeout) * time.Second)
-			os.Exit(1)
-		}()
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*timeout)*time.Second)
+		defer cancel()
 	}
 
 	analysisCases, err := parseAnalysisCases(*scenarios)
@@ -212,15 +261,19 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 
 	results.InitResults(outReadable, outMachine)
 
-	// done and separate routine to implement timeout
 	done := make(chan bool)
 	numberOfRoutines := 0
 	containsElems := false
 	go func() {
 		defer func() { done <- true }()
 
-		numberOfRoutines, containsElems, err = io.CreateTraceFromFiles(*pathTrace, *ignoreAtomics)
+		numberOfRoutines, containsElems, err = io.CreateTraceFromFiles(ctx, *pathTrace, *ignoreAtomics)
 		if err != nil {
+			if analysis.IsAnalysisIncomplete() {
+				fmt.Println(err.Error())
+				containsElems = false
+				return
+			}
 			panic(err)
 		}
 
@@ -230,6 +283,12 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 			return
 		}
 
+		if ctx.Err() != nil {
+			fmt.Println("Skip analysis because of timeout")
+			analysis.SetAnalysisIncomplete()
+			return
+		}
+
 		analysis.SetNumberOfRoutines(numberOfRoutines)
 
 		if analysisCases["all"] {
@@ -244,31 +303,62 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 		}
 
 		timemeasurement.Start("analysis")
-		analysis.RunAnalysis(*fifo, *ignoreCriticalSection, analysisCases)
+		analysis.RunAnalysis(ctx, *fifo, *ignoreCriticalSection, analysisCases)
 		timemeasurement.End("analysis")
 
 		timemeasurement.Print()
 	}()
 
-	if timeout != nil && *timeout > 0 {
-		select {
-		case <-done:
-			fmt.Print("Analysis finished\n\n")
-		case <-time.After(time.Duration(*timeout) * time.Second):
-			fmt.Printf("Analysis ended by timeout after %d seconds\n\n", *timeout)
-		}
-	} else {
-		<-done
+	<-done
+
+	incomplete := analysis.GetIncompleteReason()
+	switch incomplete {
+	case results.IncompleteTimeout:
+		fmt.Printf("Analysis ended by timeout after %d seconds\n\n", *timeout)
+		results.AddNote(fmt.Sprintf("The analysis was stopped by the timeout of %d seconds. The results are incomplete.", *timeout))
+	case results.IncompleteMemory:
+		fmt.Print("Analysis ended because of low memory\n\n")
+	default:
+		fmt.Print("Analysis finished\n\n")
 	}
+	if incomplete != "" {
+		results.SetIncomplete(incomplete)
+		fmt.Println("AdvocateAnalysisIncomplete:" + incomplete)
+	}
+
+	addMemoryNotes()
 
 	numberOfResults := results.PrintSummary(*noWarning, *noPrint)
 
+	if *writeBaseline != "" {
+		if err := results.WriteBaseline(*writeBaseline); err != nil {
+			fmt.Println("Could not write baseline: " + err.Error())
+		}
+	}
+	defer analysis.RemoveSpillFiles()
+
+	if memory.WasCanceled() && !*noRewrite {
+		fmt.Println("Skip rewriting because of low memory")
+		*noRewrite = true
+	}
+
+	file := filepath.Base(*pathTrace)
+	rewriteNr := "0"
+	spl := strings.Split(file, "_")
+	if len(spl) > 1 {
+		rewriteNr = spl[len(spl)-1]
+	}
+
+	// explain the results before the trace is changed by the rewrite
+	causal := explainResults(outMachine)
+
+	var rewrites []rewriteResult
+
 	if !*noRewrite {
 		numberRewrittenTrace := 0
 		failedRewrites := 0
 		notNeededRewrites := 0
 		println("\n\nStart rewriting trace file ", *pathTrace)
-		originalTrace := analysis.CopyCurrentTrace()
 
 		analysis.ClearData()
 
@@ -276,33 +366,63 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 
 		addAlreadyProcessed(rewrittenBugs, *ignoreRewrite)
 
-		file := filepath.Base(*pathTrace)
-		rewriteNr := "0"
-		spl := strings.Split(file, "_")
-		if len(spl) > 1 {
-			rewriteNr = spl[len(spl)-1]
+		// read the bugs sequentially, so that duplicates are detected in
+		// the order of the results
+		rewrites = make([]rewriteResult, numberOfResults)
+		for resultIndex := 0; resultIndex < numberOfResults; resultIndex++ {
+			rewrites[resultIndex] = readBug(outMachine, resultIndex, &rewrittenBugs, !*rewriteAll)
 		}
 
-		for resultIndex := 0; resultIndex < numberOfResults; resultIndex++ {
-			needed, double, err := rewriteTrace(outMachine,
-				newTrace+"_"+strconv.Itoa(resultIndex+1)+"/", resultIndex, numberOfRoutines, &rewrittenBugs, !*rewriteAll)
+		// all rewrites work on their own view of the trace and can
+		// therefore run in parallel
+		utils.RunParallel(*workers, numberOfResults, func(resultIndex int) {
+			res := &rewrites[resultIndex]
+			if !res.needed || res.err != nil {
+				return
+			}
+
+			if memory.WasCanceled() {
+				res.skipped = true
+				return
+			}
 
-			if !needed {
+			res.needed, res.err = rewriteTrace(res.bug,
+				newTrace+"_"+strconv.Itoa(resultIndex+1)+"/", resultIndex, numberOfRoutines)
+		})
+
+		stopped := false
+		for resultIndex := range rewrites {
+			res := &rewrites[resultIndex]
+			if res.skipped {
+				if !stopped {
+					fmt.Println("Stop rewriting because of low memory")
+					stopped = true
+				}
+				res.status = results.RewriteSkipped
+				continue
+			}
+
+			if !res.needed {
 				println("Trace can not be rewritten.")
 				notNeededRewrites++
-				if double {
+				if res.double {
+					res.status = results.RewriteDouble
 					fmt.Printf("Bugreport info: %s_%d,double", rewriteNr, resultIndex+1)
 				} else {
+					res.status = results.RewriteNotPossible
+					if res.actual {
+						res.status = results.RewriteActual
+					}
 					fmt.Printf("Bugreport info: %s_%d,fail", rewriteNr, resultIndex+1)
 				}
-			} else if err != nil {
-				println("Failed to rewrite trace: ", err.Error())
+			} else if res.err != nil {
+				println("Failed to rewrite trace: ", res.err.Error())
 				failedRewrites++
-				analysis.SetTrace(originalTrace)
+				res.status = results.RewriteFailed
 				fmt.Printf("Bugreport info: %s_%d,fail", rewriteNr, resultIndex+1)
 			} else { // needed && err == nil
 				numberRewrittenTrace++
-				analysis.SetTrace(originalTrace)
+				res.status = results.RewriteDone
 				fmt.Printf("Bugreport info: %s_%d,suc", rewriteNr, resultIndex+1)
 			}
 
@@ -320,9 +440,89 @@ func modeRun(pathTrace *string, noPrint *bool, noRewrite *bool,
 		}
 	}
 
+	if err := writeRecords(outMachine, rewriteNr, rewrites, causal, newTrace); err != nil {
+		fmt.Println("Could not write result records: ", err.Error())
+	}
+
 	print("\n\n\n")
 }
 
+/*
+ * Write one record for each result of the analysis into the result folder.
+ * The verdict of the replay is added later by the toolchain.
+ * Args:
+ *   outMachine (string): The path to the analysis result file
+ *   rewriteNr (string): The number of the analyzed trace, 0 for the recorded trace
+ *   rewrites ([]rewriteResult): The results of the rewrite, nil if the trace was not rewritten
+ *   causal ([]*results.Causal): The causal explanations of the results
+ *   newTrace (string): The path of the rewritten trace folders
+ * Returns:
+ *   error: An error if the records could not be written
+ */
+func writeRecords(outMachine string, rewriteNr string, rewrites []rewriteResult,
+	causal []*results.Causal, newTrace string) error {
+	data, err := os.ReadFile(outMachine)
+	if err != nil {
+		return err
+	}
+
+	records := make([]results.Record, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if !results.IsResultLine(line) {
+			continue
+		}
+
+		index := len(records)
+		record := results.Record{
+			ID:       rewriteNr + "_" + strconv.Itoa(index+1),
+			Type:     strings.Split(line, ",")[0],
+			Result:   line,
+			Score:    results.AnalysisScoreMachine(line),
+			Verdict:  results.VerdictNone,
+			ExitCode: -1,
+		}
+
+		if index < len(causal) {
+			record.Causal = causal[index]
+		}
+
+		if index < len(rewrites) {
+			record.Rewrite = rewrites[index].status
+			if record.Rewrite == results.RewriteDone {
+				record.Trace = filepath.Base(newTrace) + "_" + strconv.Itoa(index+1)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return results.WriteRecords(filepath.Dir(outMachine), rewriteNr, records, results.GetIncomplete())
+}
+
+/*
+ * Get the causal explanation for each result of the analysis
+ * Args:
+ *   outMachine (string): The path to the analysis result file
+ * Returns:
+ *   []*results.Causal: The explanations, nil for results without explanation
+ */
+func explainResults(outMachine string) []*results.Causal {
+	data, err := os.ReadFile(outMachine)
+	if err != nil {
+		return nil
+	}
+
+	res := make([]*results.Causal, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if !results.IsResultLine(line) {
+			continue
+		}
+		res = append(res, analysis.ExplainResult(line))
+	}
+
+	return res
+}
+
 func addAlreadyProcessed(alreadyProcessed map[bugs.ResultType][]string, ignoreRewrite string) {
 	if ignoreRewrite == "" {
 		return
@@ -349,57 +549,63 @@ func addAlreadyProcessed(alreadyProcessed map[bugs.ResultType][]string, ignoreRe
 	}
 }
 
-func memorySupervisor() {
-	thresholdRAM := uint64(1 * 1024 * 1024 * 1024) // 1GB
-	thresholdSwap := uint64(200 * 1024 * 1024)     // 200mb
-	for {
-		// Get the memory stats
-		v, err := mem.VirtualMemory()
-		if err != nil {
-			log.Fatalf("Error getting memory info: %v", err)
-		}
-
-		// Get the swap stats
-		s, err := mem.SwapMemory()
-		if err != nil {
-			log.Fatalf("Error getting swap info: %v", err)
-		}
-
-		// fmt.Printf("Available RAM: %v MB, Available Swap: %v MB\n", v.Available/1024/1024, s.Free/1024/1024)
-
-		// Panic if available RAM or swap is below the threshold
-		if v.Available < thresholdRAM {
-			log.Panicf("Available RAM is below threshold! Available: %v MB, Threshold: %v MB", v.Available/1024/1024, thresholdRAM/1024/1024)
-		}
+/*
+ * Add notes to the results, if the analysis was degraded or stopped
+ * because of low memory
+ */
+func addMemoryNotes() {
+	if memory.WasCanceled() {
+		results.AddNote("The analysis was stopped because not enough memory was available. The results are incomplete.")
+	} else if level := memory.GetLevel(); level != memory.LevelNone {
+		results.AddNote("The analysis was degraded because of low memory (" + level.String() + ").")
+	}
 
-		if s.Free < thresholdSwap {
-			log.Panicf("Available Swap is below threshold! Available: %v MB, Threshold: %v MB", s.Free/1024/1024, thresholdSwap/1024/1024)
-		}
+	if memory.DropAtomics() {
+		results.AddNote("Atomic operations were removed from the trace because of low memory.")
+	}
 
-		// Sleep for a while before checking again
-		time.Sleep(5 * time.Second)
+	if scenarios := analysis.GetDegradedScenarios(); len(scenarios) > 0 {
+		results.AddNote("The following scenarios were disabled because of low memory: " + strings.Join(scenarios, ", "))
 	}
 }
 
 /*
- * Rewrite the trace file based on given analysis results
+ * Result of the rewrite of one bug
+ * Fields:
+ *   bug (bugs.Bug): The bug to rewrite
+ *   needed (bool): true, if a rewrite is nessesary, false if not (e.g. actual bug, warning)
+ *   double (bool): true if the rewrite was skipped because of double
+ *   actual (bool): true if the bug is an actual bug that does not need a rewrite
+ *   skipped (bool): true if the rewrite was skipped because of low memory
+ *   err (error): An error if the bug could not be read or the trace could not be rewritten
+ *   status (string): status of the rewrite for the result records (results.Rewrite...)
+ */
+type rewriteResult struct {
+	bug     bugs.Bug
+	needed  bool
+	double  bool
+	actual  bool
+	skipped bool
+	err     error
+	status  string
+}
+
+/*
+ * Read a bug from the analysis results and check if it must be rewritten
  * Args:
  *   outMachine (string): The path to the analysis result file
- *   newTrace (string): The path where the new traces folder will be created
- *   resultIndex (int): The index of the result to use for the reordered trace file
- *   numberOfRoutines (int): The number of routines in the trace
+ *   resultIndex (int): The index of the result to read
  *   rewrittenTrace (*map[string][]string): set of bugs that have been already rewritten
+ *   rewriteOnce (bool): if true, bugs that have already been rewritten are skipped
  * Returns:
- *   bool: true, if a rewrite was nessesary, false if not (e.g. actual bug, warning)
- *   bool: true if rewrite was skipped because of double
- *   error: An error if the trace file could not be created
+ *   rewriteResult: The bug, needed is true if the bug should be rewritten
  */
-func rewriteTrace(outMachine string, newTrace string, resultIndex int,
-	numberOfRoutines int, rewrittenTrace *map[bugs.ResultType][]string, rewriteOnce bool) (bool, bool, error) {
+func readBug(outMachine string, resultIndex int,
+	rewrittenTrace *map[bugs.ResultType][]string, rewriteOnce bool) rewriteResult {
 
 	actual, bug, err := io.ReadAnalysisResults(outMachine, resultIndex)
 	if err != nil {
-		return false, false, err
+		return rewriteResult{err: err}
 	}
 
 	if rewriteOnce {
@@ -411,37 +617,71 @@ func rewriteTrace(outMachine string, newTrace string, resultIndex int,
 			if utils.ContainsString((*rewrittenTrace)[bug.Type], bugString) {
 				fmt.Println("Bug was already rewritten before")
 				fmt.Println("Skip rewrite")
-				return false, true, nil
+				return rewriteResult{bug: bug, double: true}
 			}
 		}
 		(*rewrittenTrace)[bug.Type] = append((*rewrittenTrace)[bug.Type], bugString)
 	}
 
 	if actual {
-		return false, false, nil
+		return rewriteResult{bug: bug, actual: true}
 	}
 
-	rewriteNeeded, code, err := rewriter.RewriteTrace(bug, 0)
+	return rewriteResult{bug: bug, needed: true}
+}
+
+/*
+ * Rewrite the trace for the given bug and write it into a new folder.
+ * The trace of the analysis is not changed, the rewrite is done on a
+ * view of the trace.
+ * Args:
+ *   bug (bugs.Bug): The bug to rewrite the trace for
+ *   newTrace (string): The path where the new traces folder will be created
+ *   resultIndex (int): The index of the result to use for the reordered trace file
+ *   numberOfRoutines (int): The number of routines in the trace
+ * Returns:
+ *   bool: true, if a rewrite was nessesary, false if not (e.g. actual bug, warning)
+ *   error: An error if the trace file could not be created
+ */
+func rewriteTrace(bug bugs.Bug, newTrace string, resultIndex int, numberOfRoutines int) (bool, error) {
+	trace := analysis.NewTraceView()
+
+	rewriteNeeded, code, err := rewriter.RewriteTrace(trace, bug, 0)
 
 	if err != nil {
-		return rewriteNeeded, false, err
+		return rewriteNeeded, err
 	}
 
-	err = io.WriteTrace(newTrace, numberOfRoutines)
+	err = io.WriteTrace(trace, newTrace, numberOfRoutines)
 	if err != nil {
-		return rewriteNeeded, false, err
+		return rewriteNeeded, err
 	}
 
 	err = io.WriteRewriteInfoFile(newTrace, string(bug.Type), code, resultIndex)
 	if err != nil {
-		return rewriteNeeded, false, err
+		return rewriteNeeded, err
 	}
 
-	return rewriteNeeded, false, nil
+	return rewriteNeeded, nil
 }
 
 /*
- * Parse the given analysis cases
+ * Get the names of all registered custom checkers
+ * Returns:
+ *   []string: The names
+ */
+func checkerNames() []string {
+	names := make([]string, 0)
+	for _, checker := range analysis.GetCheckers() {
+		names = append(names, checker.Name())
+	}
+	return names
+}
+
+/*
+ * Parse the given analysis cases. The cases are a comma separated list.
+ * Each entry is either the name of a registered custom checker or a
+ * string of letters for the scenarios of the analyzer.
  * Args:
  *   cases (string): The string of analysis cases to parse
  * Returns:
@@ -458,10 +698,17 @@ func parseAnalysisCases(cases string) (map[string]bool, error) {
 		"concurrentRecv":       false,
 		"leak":                 false,
 		"selectWithoutPartner": false,
+		"unlockBeforeLock":     false,
 		"cyclicDeadlock":       false,
 		"mixedDeadlock":        false,
 	}
 
+	for _, checker := range analysis.GetCheckers() {
+		if _, ok := analysisCases[checker.Name()]; ok {
+			return nil, fmt.Errorf("Custom checker %s has the name of a scenario of the analyzer", checker.Name())
+		}
+	}
+
 	if cases == "" {
 		analysisCases["all"] = true
 		analysisCases["sendOnClosed"] = true
@@ -478,6 +725,30 @@ func parseAnalysisCases(cases string) (map[string]bool, error) {
 		return analysisCases, nil
 	}
 
+	for _, part := range strings.Split(cases, ",") {
+		if analysis.GetChecker(part) != nil {
+			analysisCases[part] = true
+			continue
+		}
+
+		err := parseAnalysisCaseLetters(part, analysisCases)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return analysisCases, nil
+}
+
+/*
+ * Parse a string of letters for the scenarios of the analyzer
+ * Args:
+ *   cases (string): The letters
+ *   analysisCases (map[string]bool): The map in which the selected cases are set
+ * Returns:
+ *   error: An error if a letter is not a valid scenario
+ */
+func parseAnalysisCaseLetters(cases string, analysisCases map[string]bool) error {
 	for _, c := range cases {
 		switch c {
 		case 's':
@@ -501,10 +772,10 @@ func parseAnalysisCases(cases string) (map[string]bool, error) {
 		// case 'm':
 		// analysisCases["mixedDeadlock"] = true
 		default:
-			return nil, fmt.Errorf("Invalid analysis case: %c", c)
+			return fmt.Errorf("Invalid analysis case: %c", c)
 		}
 	}
-	return analysisCases, nil
+	return nil
 }
 
 func printHeader() {
@@ -535,11 +806,12 @@ func printHeader() {
 
 func printHelp() {
 	println("Usage: ./analyzer [mode] [options]\n")
-	println("There are four modes of operation:")
+	println("There are five modes of operation:")
 	println("1. Analyze a trace file and create a reordered trace file based on the analysis results (Default)")
 	println("2. Create an explanation for a found bug")
 	println("3. Check if all concurrency elements of the program have been executed at least once")
-	println("4. Create statistics about a program\n\n")
+	println("4. Create statistics about a program")
+	println("5. Create a html report over all found bugs\n\n")
 	println("1. Analyze a trace file and create a reordered trace file based on the analysis results (Default)")
 	println("This mode is the default mode and analyzes a trace file and creates a reordered trace file based on the analysis results.")
 	println("Usage: ./analyzer run [options]")
@@ -554,7 +826,21 @@ func printHelp() {
 	println("  -r [folder] Path to where the result file should be saved. (default parallel to -t)")
 	println("  -a          Ignore atomic operations (default false). Use to reduce memory header for large traces.")
 	println("  -S          If the same bug is detected multiple times, run the replay for each of them. If not set, only the first occurence is rewritten")
-	println("  -T [second] Set a timeout in seconds for the analysis")
+	println("  -T [second] Set a timeout in seconds for the analysis. If the timeout is reached,")
+	println("     the results found so far are written and the run is marked as incomplete")
+	println("  -include [patterns] Comma separated list of patterns. If set, only bugs in matching files are reported (default ADVOCATE_INCLUDE)")
+	println("  -exclude [patterns] Comma separated list of patterns. Bugs with elements in matching files are not reported (default ADVOCATE_EXCLUDE)")
+	println("  -baseline [file] Baseline with known results. Known results are shown separately and not rewritten (default ADVOCATE_BASELINE)")
+	println("  -writeBaseline [file] Write all found results to the file, to use it as baseline")
+	println("  -config [file] Configuration file, flags set on the command line override it (default ADVOCATE_CONFIG or ./advocate.toml)")
+	println("  Results can be suppressed with a comment //advocate:ignore [resType] [reason] in the line of an operation of the result")
+	println("  -memRAM [MB]     Minimum available RAM. If less is available, the analysis is stopped and the results found so far are written (default 1024)")
+	println("  -memSwap [MB]    Minimum free swap, if the system uses swap (default 200)")
+	println("  -memDegrade [MB] Available RAM under which the analysis drops atomics, spills vector clocks to disk")
+	println("                   and disables expensive scenarios, one step at a time (default 2048)")
+	println("  -workers [n]     Number of workers for the scenarios checked after the trace has been processed")
+	println("                   and for rewriting the traces,")
+	println("                   0 for the number of cpus, 1 to run them sequentially (default 0)")
 	println("  -s [cases]  Select which analysis scenario to run, e.g. -s srd for the option s, r and d.")
 	println("              If it is not set, all scenarios are run")
 	println("              Options:")
@@ -567,6 +853,9 @@ func printHelp() {
 	println("                  u: Select case without partner")
 	// println("                  c: Cyclic deadlock")
 	// println("                  m: Mixed deadlock")
+	println("              Custom checkers are selected by name, separated by comma,")
+	println("              e.g. -s sr,[name]. They are only run if they are selected.")
+	println("              Registered checkers: " + strings.Join(checkerNames(), ", "))
 	println("\n\n")
 	println("2. Create an explanation for a found bug")
 	println("Usage: ./analyzer explain [options]")
@@ -588,5 +877,12 @@ func printHelp() {
 	println("  -t [file]   Path to the folder containing the results_machine file (required)")
 	println("  -N [name]   Name of the program")
 	println("  -M [name]   Name of the test")
+	println("\n\n")
+	println("5. Create a html report over all found bugs")
+	println("Usage: ./analyzer report [options]")
+	println("This mode creates a static html site in [folder]/report with an index of all bugs")
+	println("and a page for each bug, that can be opened in a browser.")
+	println("It has the following options:")
+	println("  -R [folder] Path to the advocateResult folder created by the pipeline or to the result folder of one test (required)")
 	println("\n")
 }
@@ -0,0 +1,209 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: memory.go
+// Brief: Supervise the available memory and degrade the analysis if it gets low
+//
+// Author: Erik Kassubek
+// Created: 2024-11-21
+//
+// License: BSD-3-Clause
+
+package memory
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/shirou/gopsutil/mem"
+)
+
+// Level of degradation of the analysis. Each level includes all previous levels
+type Level int32
+
+const (
+	// no memory pressure
+	LevelNone Level = iota
+	// do not record atomic operations any more, like -a
+	LevelDropAtomics
+	// write the vector clocks of already processed elements to disk
+	LevelSpill
+	// disable the scenarios that keep large amounts of data
+	LevelNoExpensive
+)
+
+/*
+ * Limits for the memory supervisor. All values are in bytes
+ * Fields:
+ *   MinRAM (uint64): If less RAM is available, the analysis is canceled
+ *   MinSwap (uint64): If less swap is free, the analysis is canceled
+ *   DegradeRAM (uint64): If less RAM is available, the analysis is degraded
+ */
+type Limits struct {
+	MinRAM     uint64
+	MinSwap    uint64
+	DegradeRAM uint64
+}
+
+const MB = 1024 * 1024
+
+var (
+	limits = Limits{
+		MinRAM:     1024 * MB,
+		MinSwap:    200 * MB,
+		DegradeRAM: 2048 * MB,
+	}
+
+	level    atomic.Int32
+	canceled atomic.Bool
+
+	// time between two checks
+	interval = 2 * time.Second
+)
+
+/*
+ * Set the limits for the memory supervisor
+ * Args:
+ *   minRAM (int): Minimum available RAM in MB
+ *   minSwap (int): Minimum free swap in MB
+ *   degradeRAM (int): Available RAM in MB under which the analysis is degraded
+ */
+func SetLimits(minRAM int, minSwap int, degradeRAM int) {
+	limits = Limits{
+		MinRAM:     uint64(max(minRAM, 0)) * MB,
+		MinSwap:    uint64(max(minSwap, 0)) * MB,
+		DegradeRAM: uint64(max(degradeRAM, minRAM, 0)) * MB,
+	}
+}
+
+/*
+ * Get the current degradation level
+ * Returns:
+ *   Level: The level
+ */
+func GetLevel() Level {
+	return Level(level.Load())
+}
+
+/*
+ * Check if the analysis should drop atomic operations
+ * Returns:
+ *   bool: True if atomics should be dropped
+ */
+func DropAtomics() bool {
+	return GetLevel() >= LevelDropAtomics
+}
+
+/*
+ * Check if the analysis should spill processed vector clocks to disk
+ * Returns:
+ *   bool: True if vector clocks should be spilled
+ */
+func Spill() bool {
+	return GetLevel() >= LevelSpill
+}
+
+/*
+ * Check if expensive scenarios should be disabled
+ * Returns:
+ *   bool: True if expensive scenarios should be disabled
+ */
+func NoExpensive() bool {
+	return GetLevel() >= LevelNoExpensive
+}
+
+/*
+ * Check if the analysis was canceled because not enough memory was available
+ * Returns:
+ *   bool: True if the analysis was canceled
+ */
+func WasCanceled() bool {
+	return canceled.Load()
+}
+
+/*
+ * Compute the next state of the supervisor
+ * Args:
+ *   current (Level): The current level
+ *   availableRAM (uint64): Available RAM in bytes
+ *   totalSwap (uint64): Total swap in bytes, 0 if the system has no swap
+ *   freeSwap (uint64): Free swap in bytes
+ *   lim (Limits): The limits
+ * Returns:
+ *   Level: The new level
+ *   bool: True if the analysis must be canceled
+ */
+func nextLevel(current Level, availableRAM uint64, totalSwap uint64, freeSwap uint64, lim Limits) (Level, bool) {
+	if availableRAM < lim.MinRAM {
+		return current, true
+	}
+
+	// only check the swap if the system uses swap
+	if totalSwap > 0 && freeSwap < lim.MinSwap {
+		return current, true
+	}
+
+	// escalate by one level per check, as long as the pressure stays.
+	// The level is never decreased, because dropped data cannot be restored
+	if availableRAM < lim.DegradeRAM && current < LevelNoExpensive {
+		return current + 1, false
+	}
+
+	return current, false
+}
+
+/*
+ * Supervise the available memory. If the available memory gets low,
+ * the analysis is degraded step by step. If it gets lower than the minimum,
+ * the analysis is canceled. Run as a separate routine.
+ */
+func Supervisor() {
+	for {
+		v, err := mem.VirtualMemory()
+		if err != nil {
+			log.Printf("Error getting memory info: %v", err)
+			return
+		}
+
+		s, err := mem.SwapMemory()
+		if err != nil {
+			log.Printf("Error getting swap info: %v", err)
+			return
+		}
+
+		current := GetLevel()
+		newLevel, cancel := nextLevel(current, v.Available, s.Total, s.Free, limits)
+
+		if cancel {
+			if !canceled.Load() {
+				log.Printf("Available memory is below threshold! Available RAM: %v MB, Threshold: %v MB, Free Swap: %v MB, Threshold: %v MB. Cancel analysis",
+					v.Available/MB, limits.MinRAM/MB, s.Free/MB, limits.MinSwap/MB)
+			}
+			canceled.Store(true)
+		} else if newLevel != current {
+			log.Printf("Available RAM is low (%v MB). Degrade analysis: %s", v.Available/MB, newLevel.String())
+			level.Store(int32(newLevel))
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+/*
+ * Get a description of a level
+ * Returns:
+ *   string: The description
+ */
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelDropAtomics:
+		return "drop atomic operations"
+	case LevelSpill:
+		return "spill vector clocks to disk"
+	case LevelNoExpensive:
+		return "disable expensive scenarios"
+	}
+	return "unknown"
+}
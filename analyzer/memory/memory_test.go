@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: memory_test.go
+// Brief: Tests for memory.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-21
+//
+// License: BSD-3-Clause
+
+package memory
+
+import "testing"
+
+func TestNextLevel(t *testing.T) {
+	lim := Limits{MinRAM: 1000, MinSwap: 200, DegradeRAM: 2000}
+
+	var tests = []struct {
+		name      string
+		current   Level
+		ram       uint64
+		totalSwap uint64
+		freeSwap  uint64
+		expLevel  Level
+		expCancel bool
+	}{
+		{"Enough memory", LevelNone, 5000, 1000, 1000, LevelNone, false},
+		{"Degrade", LevelNone, 1500, 1000, 1000, LevelDropAtomics, false},
+		{"Degrade further", LevelDropAtomics, 1500, 1000, 1000, LevelSpill, false},
+		{"Max degradation", LevelNoExpensive, 1500, 1000, 1000, LevelNoExpensive, false},
+		{"Keep level", LevelSpill, 5000, 1000, 1000, LevelSpill, false},
+		{"RAM below minimum", LevelSpill, 500, 1000, 1000, LevelSpill, true},
+		{"Swap below minimum", LevelNone, 5000, 1000, 100, LevelNone, true},
+		{"No swap", LevelNone, 5000, 0, 0, LevelNone, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, cancel := nextLevel(test.current, test.ram, test.totalSwap, test.freeSwap, lim)
+			if l != test.expLevel || cancel != test.expCancel {
+				t.Errorf("Incorrect result. Expected %v, %t. Got %v, %t.", test.expLevel, test.expCancel, l, cancel)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: collector.go
+// Brief: Buffer results of parts of the analysis that run concurrently
+//
+// Author: Erik Kassubek
+// Created: 2024-11-22
+//
+// License: BSD-3-Clause
+
+package results
+
+/*
+ * A result that has been collected but not yet added
+ */
+type collectedResult struct {
+	level    resultLevel
+	resType  ResultType
+	argType1 string
+	arg1     []ResultElem
+	argType2 string
+	arg2     []ResultElem
+}
+
+/*
+ * Collector buffers the results of one part of the analysis, so that
+ * multiple parts can run concurrently. Each part gets its own collector.
+ * After all parts are finished, the collectors are flushed in a fixed
+ * order, so that the results do not depend on the scheduling.
+ * A collector must only be used by one routine at a time.
+ */
+type Collector struct {
+	results []collectedResult
+}
+
+/*
+ * Create a new, empty collector
+ * Returns:
+ *   *Collector: the collector
+ */
+func NewCollector() *Collector {
+	return &Collector{results: make([]collectedResult, 0)}
+}
+
+/*
+ * Collect a result. The arguments are the same as for Result
+ */
+func (c *Collector) Result(level resultLevel, resType ResultType, argType1 string, arg1 []ResultElem, argType2 string, arg2 []ResultElem) {
+	c.results = append(c.results, collectedResult{level, resType, argType1, arg1, argType2, arg2})
+}
+
+/*
+ * Add all collected results in the order they were collected and
+ * empty the collector. Must not be called concurrently with Result or
+ * another Flush
+ */
+func (c *Collector) Flush() {
+	for _, r := range c.results {
+		Result(r.level, r.resType, r.argType1, r.arg1, r.argType2, r.arg2)
+	}
+	c.results = make([]collectedResult, 0)
+}
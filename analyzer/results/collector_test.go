@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: collector_test.go
+// Brief: Tests for collector.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-22
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectorFlushOrder(t *testing.T) {
+	resultCriticalMachine = []string{}
+	resultWithoutTime = []string{}
+
+	arg := func(line int) []ResultElem {
+		return []ResultElem{TraceElementResult{RoutineID: 1, ObjID: 2, TPre: line, ObjType: "WD", File: "a.go", Line: line}}
+	}
+
+	// the second collector is filled first, but flushed last
+	first := NewCollector()
+	second := NewCollector()
+	second.Result(CRITICAL, PNegWG, "done", arg(3), "", []ResultElem{})
+	first.Result(CRITICAL, PNegWG, "done", arg(1), "", []ResultElem{})
+	first.Result(CRITICAL, PNegWG, "done", arg(2), "", []ResultElem{})
+
+	if len(resultCriticalMachine) != 0 {
+		t.Errorf("Results added before flush: %v", resultCriticalMachine)
+	}
+
+	first.Flush()
+	second.Flush()
+
+	expected := []string{
+		"P03,T:1:2:1:WD:a.go:1\n",
+		"P03,T:1:2:2:WD:a.go:2\n",
+		"P03,T:1:2:3:WD:a.go:3\n",
+	}
+	if !reflect.DeepEqual(resultCriticalMachine, expected) {
+		t.Errorf("Incorrect order. Expected %v. Got %v.", expected, resultCriticalMachine)
+	}
+
+	resultCriticalMachine = []string{}
+	resultsCriticalReadable = []string{}
+	resultWithoutTime = []string{}
+	foundBug = false
+}
@@ -0,0 +1,268 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: records.go
+// Brief: One record per found bug with the result of the rewrite and replay
+//
+// Author: Erik Kassubek
+// Created: 2024-11-26
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// name of the file with the records in the result folder
+const RecordsFile = "results_records.json"
+
+// status of the rewrite of a bug
+const (
+	RewriteNone        = ""             // the trace was not rewritten (-x)
+	RewriteDone        = "rewritten"    // a rewritten trace was created
+	RewriteActual      = "actual"       // actual bug, no rewrite needed
+	RewriteNotPossible = "not possible" // the analyzer could not rewrite the trace
+	RewriteFailed      = "failed"       // an error occurred during the rewrite
+	RewriteDouble      = "double"       // the bug was already rewritten before
+	RewriteSkipped     = "skipped"      // skipped because of low memory
+)
+
+// verdict of the replay of a bug, set by the toolchain after the replay
+const (
+	VerdictNone          = ""               // not replayed
+	VerdictConfirmed     = "confirmed"      // the replay confirmed the bug
+	VerdictNotReproduced = "not reproduced" // the replay finished without confirming the bug
+	VerdictTimeout       = "timeout"        // the replay timed out
+	VerdictDiverged      = "diverged"       // the replay panicked unexpectedly or got stuck
+)
+
+/*
+ * Record of a found bug
+ * Fields:
+ *   ID (string): id of the bug, [rewriteNr]_[index], as in Bugreport info
+ *   Type (string): result type, e.g. P01
+ *   Result (string): result in the machine readable format
+ *   Score (float64): score of the analysis
+ *   Rewrite (string): status of the rewrite (Rewrite...)
+ *   Trace (string): name of the folder with the rewritten trace, if it exists
+ *   Verdict (string): verdict of the replay (Verdict...)
+ *   ExitCode (int): exit code of the replay, -1 if not replayed
+ *   Causal (*Causal): causal explanation of the bug, nil if not available
+ */
+type Record struct {
+	ID       string  `json:"id"`
+	Type     string  `json:"type"`
+	Result   string  `json:"result"`
+	Score    float64 `json:"score"`
+	Rewrite  string  `json:"rewrite"`
+	Trace    string  `json:"trace,omitempty"`
+	Verdict  string  `json:"verdict"`
+	ExitCode int     `json:"exitCode"`
+	Causal   *Causal `json:"causal,omitempty"`
+}
+
+/*
+ * Content of the records file
+ * Fields:
+ *   Incomplete (map[string]string): rewriteNr -> reason (IncompleteTimeout or
+ *     IncompleteMemory) for each analysis that was stopped before it finished.
+ *     The records of those analyses only contain the bugs found until then
+ *   Records ([]Record): the records of all analyses
+ */
+type RecordsData struct {
+	Incomplete map[string]string `json:"incomplete,omitempty"`
+	Records    []Record          `json:"records"`
+}
+
+/*
+ * Element of a bug with its vector clock
+ * Fields:
+ *   Routine (int): routine of the element
+ *   TPre (int): tPre of the element
+ *   Op (string): description of the operation, e.g. send on channel 3
+ *   Pos (string): position of the operation
+ *   VC (string): vector clock of the operation
+ */
+type CausalElem struct {
+	Routine int    `json:"routine"`
+	TPre    int    `json:"tPre"`
+	Op      string `json:"op"`
+	Pos     string `json:"pos"`
+	VC      string `json:"vc"`
+}
+
+/*
+ * Causal explanation of a bug, i.e. why the involved operations
+ * can be reordered or why a routine is blocked
+ * Fields:
+ *   Elems ([]CausalElem): the involved elements
+ *   Narrative ([]string): the explanation, one sentence per entry
+ */
+type Causal struct {
+	Elems     []CausalElem `json:"elems"`
+	Narrative []string     `json:"narrative"`
+}
+
+/*
+ * Check if a bug was confirmed, i.e. the replay confirmed it or it
+ * occurred in the recorded run
+ * Returns:
+ *   bool: true if the bug is confirmed
+ */
+func (r Record) IsConfirmed() bool {
+	return r.Verdict == VerdictConfirmed || r.Rewrite == RewriteActual
+}
+
+/*
+ * Get the verdict for an exit code of the replay
+ * Args:
+ *   exitCode: the exit code
+ * Returns:
+ *   string: the verdict
+ */
+func VerdictFromExitCode(exitCode int) string {
+	switch {
+	case exitCode >= 20:
+		return VerdictConfirmed
+	case exitCode == 0:
+		return VerdictNotReproduced
+	case exitCode == 10:
+		return VerdictTimeout
+	}
+	return VerdictDiverged
+}
+
+/*
+ * Read the records from a result folder
+ * Args:
+ *   folder: the result folder
+ * Returns:
+ *   []Record: the records
+ *   error: if the file does not exist or is invalid
+ */
+func ReadRecords(folder string) ([]Record, error) {
+	data, err := ReadRecordsData(folder)
+	return data.Records, err
+}
+
+/*
+ * Read the records file of a result folder, including the information
+ * which analyses are incomplete
+ * Args:
+ *   folder: the result folder
+ * Returns:
+ *   RecordsData: the content of the file
+ *   error: if the file does not exist or is invalid
+ */
+func ReadRecordsData(folder string) (RecordsData, error) {
+	res := RecordsData{Records: make([]Record, 0)}
+
+	data, err := os.ReadFile(filepath.Join(folder, RecordsFile))
+	if err != nil {
+		return res, err
+	}
+
+	err = json.Unmarshal(data, &res)
+	if res.Records == nil {
+		res.Records = make([]Record, 0)
+	}
+	return res, err
+}
+
+/*
+ * Write the records of one analysis into the result folder. Existing records
+ * of previous analyses of the same trace (same rewriteNr) are replaced,
+ * records of other traces are kept.
+ * Args:
+ *   folder: the result folder
+ *   rewriteNr: number of the analyzed trace, 0 for the recorded trace
+ *   records: the new records
+ *   incomplete: reason why the analysis is incomplete, empty if it finished
+ * Returns:
+ *   error: if the file could not be written
+ */
+func WriteRecords(folder string, rewriteNr string, records []Record, incomplete string) error {
+	res := RecordsData{
+		Incomplete: make(map[string]string),
+		Records:    make([]Record, 0),
+	}
+
+	if old, err := ReadRecordsData(folder); err == nil {
+		for _, record := range old.Records {
+			if !strings.HasPrefix(record.ID, rewriteNr+"_") {
+				res.Records = append(res.Records, record)
+			}
+		}
+		for nr, reason := range old.Incomplete {
+			if nr != rewriteNr {
+				res.Incomplete[nr] = reason
+			}
+		}
+	}
+	res.Records = append(res.Records, records...)
+	if incomplete != "" {
+		res.Incomplete[rewriteNr] = incomplete
+	}
+
+	sort.SliceStable(res.Records, func(i, j int) bool {
+		return res.Records[i].Score > res.Records[j].Score
+	})
+
+	return writeRecordsData(folder, res)
+}
+
+/*
+ * Set the result of the replay of a rewritten trace in its record
+ * Args:
+ *   folder: the result folder
+ *   trace: name of the folder with the replayed trace
+ *   exitCode: the exit code of the replay
+ *   verdict: the verdict of the replay (Verdict...)
+ * Returns:
+ *   error: if the records could not be read or written or there is
+ *     no record for the trace
+ */
+func SetReplayResult(folder string, trace string, exitCode int, verdict string) error {
+	data, err := ReadRecordsData(folder)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range data.Records {
+		if data.Records[i].Trace == trace {
+			data.Records[i].Verdict = verdict
+			data.Records[i].ExitCode = exitCode
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("No record for trace %s", trace)
+	}
+
+	return writeRecordsData(folder, data)
+}
+
+/*
+ * Write the content of the records file
+ * Args:
+ *   folder: the result folder
+ *   data: the content
+ * Returns:
+ *   error: if the file could not be written
+ */
+func writeRecordsData(folder string, data RecordsData) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(folder, RecordsFile), content, 0644)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: records_test.go
+// Brief: Tests for records.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-26
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerdictFromExitCode(t *testing.T) {
+	var tests = []struct {
+		name     string
+		exitCode int
+		expected string
+	}{
+		{"Leak confirmed", 20, VerdictConfirmed},
+		{"Send on closed confirmed", 30, VerdictConfirmed},
+		{"Not reproduced", 0, VerdictNotReproduced},
+		{"Timeout", 10, VerdictTimeout},
+		{"Unexpected panic", 3, VerdictDiverged},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := VerdictFromExitCode(test.exitCode)
+			if res != test.expected {
+				t.Errorf("Incorrect verdict for %d. Expected %s. Got %s", test.exitCode, test.expected, res)
+			}
+		})
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	folder := t.TempDir()
+
+	first := []Record{
+		{ID: "0_1", Type: "P01", Score: 0.5, Rewrite: RewriteDone, ExitCode: -1},
+		{ID: "0_2", Type: "L00", Score: 0.2, Rewrite: RewriteNotPossible, ExitCode: -1},
+	}
+	second := []Record{
+		{ID: "1_1", Type: "A01", Score: 0.9, Rewrite: RewriteActual, ExitCode: -1},
+	}
+	again := []Record{
+		{ID: "0_1", Type: "P01", Score: 0.5, Rewrite: RewriteDone, ExitCode: -1},
+	}
+
+	for _, write := range []struct {
+		rewriteNr  string
+		records    []Record
+		incomplete string
+	}{{"0", first, IncompleteTimeout}, {"1", second, IncompleteMemory}, {"0", again, ""}} {
+		if err := WriteRecords(folder, write.rewriteNr, write.records, write.incomplete); err != nil {
+			t.Fatalf("Could not write records: %s", err.Error())
+		}
+	}
+
+	res, err := ReadRecords(folder)
+	if err != nil {
+		t.Fatalf("Could not read records: %s", err.Error())
+	}
+
+	expected := []string{"1_1", "0_1"}
+	if len(res) != len(expected) {
+		t.Fatalf("Incorrect number of records. Expected %d. Got %d", len(expected), len(res))
+	}
+	for i, id := range expected {
+		if res[i].ID != id {
+			t.Errorf("Incorrect record %d. Expected %s. Got %s", i, id, res[i].ID)
+		}
+	}
+}
+
+func TestReadRecordsData(t *testing.T) {
+	folder := t.TempDir()
+
+	records := []Record{{ID: "0_1", Type: "P01", ExitCode: -1}}
+	if err := WriteRecords(folder, "0", records, IncompleteTimeout); err != nil {
+		t.Fatalf("Could not write records: %s", err.Error())
+	}
+	if err := WriteRecords(folder, "1", nil, ""); err != nil {
+		t.Fatalf("Could not write records: %s", err.Error())
+	}
+
+	data, err := ReadRecordsData(folder)
+	if err != nil {
+		t.Fatalf("Could not read records: %s", err.Error())
+	}
+
+	expected := map[string]string{"0": IncompleteTimeout}
+	if !reflect.DeepEqual(data.Incomplete, expected) {
+		t.Errorf("Incorrect incomplete analyses. Expected %v. Got %v", expected, data.Incomplete)
+	}
+	if len(data.Records) != 1 || data.Records[0].ID != "0_1" {
+		t.Errorf("Incorrect records. Got %v", data.Records)
+	}
+}
+
+func TestSetReplayResult(t *testing.T) {
+	folder := t.TempDir()
+
+	records := []Record{
+		{ID: "0_1", Type: "P01", Rewrite: RewriteDone, Trace: "rewritten_trace_1", ExitCode: -1},
+		{ID: "0_2", Type: "A01", Rewrite: RewriteActual, ExitCode: -1},
+	}
+	if err := WriteRecords(folder, "0", records, IncompleteTimeout); err != nil {
+		t.Fatalf("Could not write records: %s", err.Error())
+	}
+
+	if err := SetReplayResult(folder, "rewritten_trace_1", 20, VerdictFromExitCode(20)); err != nil {
+		t.Fatalf("Could not set replay result: %s", err.Error())
+	}
+	if err := SetReplayResult(folder, "rewritten_trace_2", 0, VerdictNotReproduced); err == nil {
+		t.Errorf("Expected error for missing record")
+	}
+
+	data, err := ReadRecordsData(folder)
+	if err != nil {
+		t.Fatalf("Could not read records: %s", err.Error())
+	}
+
+	if data.Incomplete["0"] != IncompleteTimeout {
+		t.Errorf("Incomplete analysis was not kept. Got %v", data.Incomplete)
+	}
+	for _, record := range data.Records {
+		if record.ID == "0_1" && (record.Verdict != VerdictConfirmed || record.ExitCode != 20) {
+			t.Errorf("Incorrect replay result. Got %s, %d", record.Verdict, record.ExitCode)
+		}
+		if !record.IsConfirmed() {
+			t.Errorf("Record %s should be confirmed", record.ID)
+		}
+	}
+}
@@ -11,8 +11,10 @@
 package results
 
 import (
+	"analyzer/utils"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -88,6 +90,60 @@ var resultTypeMap = map[ResultType]string{
 	SNotExecutedWithPartner: "Not executed select with potential partner",
 }
 
+// result types registered by custom checkers
+var customResultTypes = make(map[ResultType]bool)
+
+/*
+ * Register a result type for a custom checker. Custom result types must
+ * start with X, e.g. X01, so that they never collide with the result types
+ * of the analyzer. Must be called before the analysis is started, e.g. in
+ * the init function of the package of the checker.
+ * Args:
+ *   resType: the result type
+ *   description: the description used as header in the readable result file
+ * Returns:
+ *   error: if the result type is invalid or already registered
+ */
+func RegisterResultType(resType ResultType, description string) error {
+	if !strings.HasPrefix(string(resType), "X") || len(resType) < 2 {
+		return fmt.Errorf("Custom result type %s must start with X", resType)
+	}
+
+	if strings.ContainsAny(string(resType), ",:") {
+		return fmt.Errorf("Custom result type %s must not contain , or :", resType)
+	}
+
+	if _, ok := resultTypeMap[resType]; ok {
+		return fmt.Errorf("Result type %s already registered", resType)
+	}
+
+	resultTypeMap[resType] = description
+	customResultTypes[resType] = true
+	return nil
+}
+
+/*
+ * Check if a result type was registered by a custom checker
+ * Args:
+ *   resType: the result type
+ * Returns:
+ *   bool: true if the result type is a custom result type
+ */
+func IsCustomResultType(resType ResultType) bool {
+	return customResultTypes[resType]
+}
+
+/*
+ * Get the description of a result type
+ * Args:
+ *   resType: the result type
+ * Returns:
+ *   string: the description, empty if the result type does not exist
+ */
+func GetResultTypeDescription(resType ResultType) string {
+	return resultTypeMap[resType]
+}
+
 var outputReadableFile string
 var outputMachineFile string
 var foundBug = false
@@ -97,8 +153,39 @@ var resultsWarningMachine []string
 var resultCriticalMachine []string
 var resultInformationMachine []string
 
+// scores of the critical and warning results
+var resultsCriticalScore []float64
+var resultsWarningScore []float64
+
 var resultWithoutTime []string
 
+// description of the creation of objects
+var objectInfo = make(map[int]string) // id -> description
+
+// call stacks of the elements, if recorded
+var stacks = make(map[int][]string) // tPre -> stack
+
+// include and exclude patterns, results with elements in filtered files
+// are not reported
+var includePatterns []string
+var excludePatterns []string
+
+// notes about the analysis, e.g. if it was degraded because of low memory
+var notes []string
+
+// reason why the analysis is incomplete, empty if it finished
+var incompleteReason string
+
+// reasons why an analysis is incomplete
+const (
+	IncompleteTimeout = "timeout" // stopped by the timeout set with -T
+	IncompleteMemory  = "memory"  // stopped because not enough memory was available
+)
+
+// prefix of the line at the end of the result machine file, that marks
+// the results as incomplete, followed by the reason
+const IncompleteMarker = "#incomplete:"
+
 type ResultElem interface {
 	isInvalid() bool
 	stringMachine() string
@@ -116,7 +203,14 @@ type TraceElementResult struct {
 }
 
 func (t TraceElementResult) stringMachineShort() string {
-	return fmt.Sprintf("T:%d:%s:%s:%d", t.ObjID, t.ObjType, t.File, t.Line)
+	res := fmt.Sprintf("T:%d:%s:%s:%d", t.ObjID, t.ObjType, t.File, t.Line)
+
+	// operations in a shared helper are only the same if they have
+	// been called from the same position
+	if stack, ok := stacks[t.TPre]; ok {
+		res += ":" + strings.Join(stack[1:], ":")
+	}
+	return res
 }
 
 func (t TraceElementResult) stringMachine() string {
@@ -155,12 +249,145 @@ func (s SelectCaseResult) isInvalid() bool {
 	return s.ObjType == ""
 }
 
+/*
+ * Set the description of the creation of an object, e.g.
+ * channel created at server.go:88 (chan *Request, cap 0)
+ * Args:
+ *   id: id of the object
+ *   info: description of the creation
+ */
+func SetObjectInfo(id int, info string) {
+	objectInfo[id] = info
+}
+
+/*
+ * Set the call stack of an element
+ * Args:
+ *   tPre: tPre of the element
+ *   stack: call stack, starting with the innermost frame
+ */
+func SetStack(tPre int, stack []string) {
+	stacks[tPre] = stack
+}
+
+/*
+ * Get the call stacks of the given result elements
+ * Args:
+ *   args: the result elements
+ * Returns:
+ *   []string: the call stacks as readable strings
+ */
+func getStackInfo(args ...[]ResultElem) []string {
+	res := make([]string, 0)
+	for _, arg := range args {
+		for _, elem := range arg {
+			t, ok := elem.(TraceElementResult)
+			if !ok {
+				continue
+			}
+
+			stack, ok := stacks[t.TPre]
+			if !ok {
+				continue
+			}
+
+			res = append(res, "stack of "+t.stringReadable()+": "+strings.Join(stack, " <- "))
+		}
+	}
+	return res
+}
+
+/*
+ * Get a human readable description of the creation of an object, e.g.
+ * channel created at server.go:88 (chan *Request, cap 0)
+ * Args:
+ *   objType: type of the object as recorded in the trace (C, M, R, W, O, N)
+ *   elemType: element type of the object
+ *   qSize: capacity of a channel
+ *   pos: position of the creation
+ * Returns:
+ *   string: the description
+ */
+func ObjectDescription(objType string, elemType string, qSize int, pos string) string {
+	switch objType {
+	case "C":
+		return "channel created at " + pos + " (chan " + elemType +
+			", cap " + strconv.Itoa(qSize) + ")"
+	case "M":
+		return "mutex first used at " + pos + " (" + elemType + ")"
+	case "R":
+		return "rwmutex first used at " + pos + " (" + elemType + ")"
+	case "W":
+		return "waitgroup first used at " + pos + " (" + elemType + ")"
+	case "O":
+		return "once first used at " + pos + " (" + elemType + ")"
+	case "N":
+		return "cond first used at " + pos + " (" + elemType + ")"
+	}
+	return "object created at " + pos
+}
+
+/*
+ * Get the description of the creation of the objects of the given
+ * result elements
+ * Args:
+ *   args: the result elements
+ * Returns:
+ *   []string: the descriptions without duplicates
+ */
+func getObjectInfo(args ...[]ResultElem) []string {
+	res := make([]string, 0)
+	for _, arg := range args {
+		for _, elem := range arg {
+			t, ok := elem.(TraceElementResult)
+			if !ok {
+				continue
+			}
+
+			info, ok := objectInfo[t.ObjID]
+			if !ok || stringInSlice(info, res) {
+				continue
+			}
+			res = append(res, info)
+		}
+	}
+	return res
+}
+
+/*
+ * Set the include and exclude patterns. Results containing elements
+ * in files that are filtered out are not reported. Use the same patterns
+ * as for the recording (ADVOCATE_INCLUDE, ADVOCATE_EXCLUDE)
+ * Args:
+ *   include: if not empty, only results in files matching one of the patterns are reported
+ *   exclude: results with elements in files matching one of the patterns are not reported
+ */
+func SetFilter(include []string, exclude []string) {
+	includePatterns = include
+	excludePatterns = exclude
+}
+
 func ignore(file string) bool {
 	return strings.Contains(file, "signal_unix.go") ||
 		strings.Contains(file, "src/advocate/advocate.go")
 
 }
 
+/*
+ * Check if a result element is in a file that is filtered out
+ * Args:
+ *   arg: the result element
+ * Returns:
+ *   bool: true if the element is filtered out
+ */
+func filtered(arg ResultElem) bool {
+	t, ok := arg.(TraceElementResult)
+	if !ok {
+		return false
+	}
+	return utils.IsFiltered(t.File, includePatterns, excludePatterns)
+}
+
 /*
  * Print a result message
  * Args:
@@ -182,7 +409,7 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 		if arg.isInvalid() {
 			return
 		}
-		if ignore(arg.stringMachine()) {
+		if ignore(arg.stringMachine()) || filtered(arg) {
 			return
 		}
 		if i != 0 {
@@ -202,7 +429,7 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 			if arg.isInvalid() {
 				return
 			}
-			if ignore(arg.stringMachine()) {
+			if ignore(arg.stringMachine()) || filtered(arg) {
 				return
 			}
 			if i != 0 {
@@ -216,13 +443,45 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 
 	}
 
+	for _, info := range getStackInfo(arg1, arg2) {
+		resultReadable += "\t" + info + "\n"
+	}
+
+	for _, info := range getObjectInfo(arg1, arg2) {
+		resultReadable += "\t" + info + "\n"
+	}
+
+	score := analysisScore(resType, getTPres(arg1), getTPres(arg2))
+	resultReadable += "\tscore: " + FormatScore(score) + "\n"
+
 	resultReadable += "\n"
 	resultMachine += "\n"
 
+	// results suppressed in the code or known from the baseline
+	if level != INFORMATION && !stringInSlice(resultMachineShort, resultWithoutTime) {
+		if isSuppressed(resType, arg1, arg2) {
+			numberSuppressed++
+			resultWithoutTime = append(resultWithoutTime, resultMachineShort)
+			return
+		}
+
+		entry := newBaselineEntry(resType, arg1, arg2)
+		if !stringInSlice(entry.toString(), resultsBaselineKeys) {
+			resultsBaselineKeys = append(resultsBaselineKeys, entry.toString())
+		}
+
+		if inBaseline(entry) {
+			resultsBaselinedReadable = append(resultsBaselinedReadable, resultReadable)
+			resultWithoutTime = append(resultWithoutTime, resultMachineShort)
+			return
+		}
+	}
+
 	if level == WARNING {
 		if !stringInSlice(resultMachineShort, resultWithoutTime) {
 			resultsWarningReadable = append(resultsWarningReadable, resultReadable)
 			resultsWarningMachine = append(resultsWarningMachine, resultMachine)
+			resultsWarningScore = append(resultsWarningScore, score)
 			resultWithoutTime = append(resultWithoutTime, resultMachineShort)
 		}
 	} else if level == CRITICAL {
@@ -230,6 +489,7 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 			println(resultReadable)
 			resultsCriticalReadable = append(resultsCriticalReadable, resultReadable)
 			resultCriticalMachine = append(resultCriticalMachine, resultMachine)
+			resultsCriticalScore = append(resultsCriticalScore, score)
 			resultWithoutTime = append(resultWithoutTime, resultMachineShort)
 		}
 	} else if level == INFORMATION {
@@ -251,6 +511,63 @@ func InitResults(outReadable string, outMachine string) {
 	outputMachineFile = outMachine
 }
 
+/*
+ * Add a note about the analysis, e.g. that it was degraded because of low memory.
+ * The notes are shown at the start of the readable summary
+ * Args:
+ *   note: the note
+ */
+func AddNote(note string) {
+	notes = append(notes, note)
+}
+
+/*
+ * Mark the results as incomplete. The marker is added as the last line of
+ * the result machine file.
+ * Args:
+ *   reason: why the analysis is incomplete (IncompleteTimeout or IncompleteMemory)
+ */
+func SetIncomplete(reason string) {
+	incompleteReason = reason
+}
+
+/*
+ * Get the reason why the analysis is incomplete
+ * Returns:
+ *   string: the reason, empty if the analysis finished
+ */
+func GetIncomplete() string {
+	return incompleteReason
+}
+
+/*
+ * Check if a line of a result machine file is a result, i.e. neither
+ * empty nor the incomplete marker
+ * Args:
+ *   line: the line
+ * Returns:
+ *   bool: true if the line is a result
+ */
+func IsResultLine(line string) bool {
+	return line != "" && !strings.HasPrefix(line, IncompleteMarker)
+}
+
+/*
+ * Get the reason from the incomplete marker of a result machine file
+ * Args:
+ *   content: the content of the file
+ * Returns:
+ *   string: the reason, empty if the file does not contain the marker
+ */
+func IncompleteFromMachine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, IncompleteMarker) {
+			return strings.TrimPrefix(line, IncompleteMarker)
+		}
+	}
+	return ""
+}
+
 /*
 * Print the summary of the analysis
 * Args:
@@ -268,8 +585,25 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 		fmt.Print("==================== Summary ====================\n\n")
 	}
 
+	for _, note := range notes {
+		resReadable += "Note: " + note + "\n"
+		if !noPrint {
+			fmt.Println("Note: " + note)
+		}
+	}
+	if len(notes) > 0 {
+		resReadable += "\n"
+		if !noPrint {
+			fmt.Print("\n")
+		}
+	}
+
 	found := false
 
+	// show the results with the highest score first
+	sortByScore(resultsCriticalReadable, resultCriticalMachine, resultsCriticalScore)
+	sortByScore(resultsWarningReadable, resultsWarningMachine, resultsWarningScore)
+
 	if len(resultsCriticalReadable) > 0 {
 		found = true
 		resReadable += "-------------------- Critical -------------------\n\n"
@@ -321,16 +655,50 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 		}
 	}
 
+	if len(resultsBaselinedReadable) > 0 {
+		resReadable += "\n-------------------- Known ----------------------\n\n"
+		resReadable += "Results in the baseline, not reported as new results\n\n"
+		if !noPrint {
+			fmt.Print("\n-------------------- Known ----------------------\n\n")
+			fmt.Print("Results in the baseline, not reported as new results\n\n")
+		}
+
+		for _, result := range resultsBaselinedReadable {
+			resReadable += "- " + result + "\n"
+
+			if !noPrint {
+				fmt.Println("- " + result)
+			}
+		}
+	}
+
+	if numberSuppressed > 0 {
+		info := strconv.Itoa(numberSuppressed) + " results suppressed by " + suppressDirective
+		resReadable += "\n" + info + "\n"
+
+		if !noPrint {
+			fmt.Println(info)
+		}
+	}
+
 	if !found {
-		resReadable += "No bugs found" + "\n"
+		noBugs := "No bugs found"
+		if len(resultsBaselinedReadable) > 0 || numberSuppressed > 0 {
+			noBugs = "No new bugs found"
+		}
+		resReadable += noBugs + "\n"
 
 		if !noPrint {
-			fmt.Println("No bugs found")
+			fmt.Println(noBugs)
 		}
 	}
 
 	resReadable += "```"
 
+	if incompleteReason != "" {
+		resMachine += IncompleteMarker + incompleteReason + "\n"
+	}
+
 	// write output readable
 	if _, err := os.Stat(outputReadableFile); err == nil {
 		if err := os.Remove(outputReadableFile); err != nil {
@@ -368,6 +736,36 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 	return len(resultCriticalMachine) + len(resultsWarningMachine) + len(resultInformationMachine)
 }
 
+/*
+ * Sort results by their score, highest score first. Results with the
+ * same score keep their order
+ * Args:
+ *   readable: the readable results
+ *   machine: the machine results
+ *   scores: the scores of the results
+ */
+func sortByScore(readable []string, machine []string, scores []float64) {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	sortedReadable := make([]string, len(order))
+	sortedMachine := make([]string, len(order))
+	sortedScores := make([]float64, len(order))
+	for i, index := range order {
+		sortedReadable[i] = readable[index]
+		sortedMachine[i] = machine[index]
+		sortedScores[i] = scores[index]
+	}
+	copy(readable, sortedReadable)
+	copy(machine, sortedMachine)
+	copy(scores, sortedScores)
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
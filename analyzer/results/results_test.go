@@ -0,0 +1,108 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: results_test.go
+// Brief: Tests for results.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-24
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"testing"
+)
+
+func TestRegisterResultType(t *testing.T) {
+	var tests = []struct {
+		name    string
+		resType ResultType
+		err     bool
+	}{
+		{"Custom", "X90", false},
+		{"Duplicate", "X90", true},
+		{"Builtin", PNegWG, true},
+		{"No X", "C01", true},
+		{"Only X", "X", true},
+		{"Separator", "X9,1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RegisterResultType(test.resType, "Custom result:")
+			if test.err && err == nil {
+				t.Errorf("Expected error for %s", test.resType)
+			}
+			if !test.err && err != nil {
+				t.Errorf("Unexpected error: %s", err.Error())
+			}
+		})
+	}
+
+	if !IsCustomResultType("X90") || IsCustomResultType(PNegWG) {
+		t.Errorf("Incorrect custom result types")
+	}
+
+	if GetResultTypeDescription("X90") != "Custom result:" {
+		t.Errorf("Incorrect description: %s", GetResultTypeDescription("X90"))
+	}
+}
+
+func TestObjectDescription(t *testing.T) {
+	var tests = []struct {
+		name     string
+		objType  string
+		elemType string
+		qSize    int
+		pos      string
+		expected string
+	}{
+		{"Channel", "C", "*Request", 0, "server.go:88", "channel created at server.go:88 (chan *Request, cap 0)"},
+		{"Buffered channel", "C", "func(int, int)", 3, "a.go:1", "channel created at a.go:1 (chan func(int, int), cap 3)"},
+		{"Mutex", "M", "sync.Mutex", 0, "a.go:2", "mutex first used at a.go:2 (sync.Mutex)"},
+		{"RWMutex", "R", "sync.RWMutex", 0, "a.go:3", "rwmutex first used at a.go:3 (sync.RWMutex)"},
+		{"WaitGroup", "W", "sync.WaitGroup", 0, "a.go:4", "waitgroup first used at a.go:4 (sync.WaitGroup)"},
+		{"Once", "O", "sync.Once", 0, "a.go:5", "once first used at a.go:5 (sync.Once)"},
+		{"Cond", "N", "sync.Cond", 0, "a.go:6", "cond first used at a.go:6 (sync.Cond)"},
+		{"Unknown", "Q", "", 0, "a.go:7", "object created at a.go:7"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := ObjectDescription(test.objType, test.elemType, test.qSize, test.pos)
+			if res != test.expected {
+				t.Errorf("Incorrect description. Expected %s. Got %s.", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestIncompleteMarker(t *testing.T) {
+	content := "L01,T:1:2:3:CS:a.go:1\n" + IncompleteMarker + IncompleteTimeout + "\n"
+
+	var tests = []struct {
+		name     string
+		line     string
+		expected bool
+	}{
+		{"Result", "L01,T:1:2:3:CS:a.go:1", true},
+		{"Empty", "", false},
+		{"Marker", IncompleteMarker + IncompleteMemory, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := IsResultLine(test.line); res != test.expected {
+				t.Errorf("Incorrect result for %q. Expected %v. Got %v", test.line, test.expected, res)
+			}
+		})
+	}
+
+	if res := IncompleteFromMachine(content); res != IncompleteTimeout {
+		t.Errorf("Incorrect reason. Expected %s. Got %s", IncompleteTimeout, res)
+	}
+	if res := IncompleteFromMachine("L01,T:1:2:3:CS:a.go:1\n"); res != "" {
+		t.Errorf("Incorrect reason for complete results. Got %s", res)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: score.go
+// Brief: Confidence score of results to rank the found bugs
+//
+// Author: Erik Kassubek
+// Created: 2024-11-25
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// number of operations between the elements of a result at which the
+// distance score is 0.5
+const scoreDistanceHalf = 50.0
+
+/*
+ * Get the score for whether the bug was observed in the recorded run or
+ * only predicted. Leaks on routines without any blocking operation are
+ * mostly routines that were still running when the program terminated
+ * and therefore get a low score.
+ * Args:
+ *   resType: the result type
+ * Returns:
+ *   float64: the score between 0 and 1
+ */
+func observationScore(resType ResultType) float64 {
+	if resType == LWithoutBlock {
+		return 0.2
+	}
+
+	switch string(resType)[:1] {
+	case "A":
+		return 1
+	case "P":
+		return 0.6
+	case "L", "X":
+		return 0.5
+	}
+	return 0
+}
+
+/*
+ * Get the score for the distance in the trace between the elements of the
+ * two arguments of a result. The closer the operations are, the more likely
+ * is it that they can be reordered.
+ * Args:
+ *   tPre1: tPre of the elements of the first argument
+ *   tPre2: tPre of the elements of the second argument
+ * Returns:
+ *   float64: the score between 0 and 1, 0.5 if one of the arguments is empty
+ */
+func distanceScore(tPre1 []int, tPre2 []int) float64 {
+	if len(tPre1) == 0 || len(tPre2) == 0 {
+		return 0.5
+	}
+
+	dist := math.MaxInt
+	for _, t1 := range tPre1 {
+		for _, t2 := range tPre2 {
+			d := t1 - t2
+			if d < 0 {
+				d = -d
+			}
+			dist = min(dist, d)
+		}
+	}
+
+	// the global counter is increased by 2 for each operation
+	return 1 / (1 + float64(dist)/2/scoreDistanceHalf)
+}
+
+/*
+ * Get the score of a result, that can be determined by the analysis
+ * alone, from whether the bug was observed and the distance of the
+ * involved operations
+ * Args:
+ *   resType: the result type
+ *   tPre1: tPre of the elements of the first argument
+ *   tPre2: tPre of the elements of the second argument
+ * Returns:
+ *   float64: the score between 0 and 1
+ */
+func analysisScore(resType ResultType, tPre1 []int, tPre2 []int) float64 {
+	return 0.5*observationScore(resType) + 0.5*distanceScore(tPre1, tPre2)
+}
+
+/*
+ * Get the tPre of the trace elements in a list of result elements
+ * Args:
+ *   args: the result elements
+ * Returns:
+ *   []int: the tPre of the trace elements
+ */
+func getTPres(args []ResultElem) []int {
+	res := make([]int, 0)
+	for _, arg := range args {
+		if t, ok := arg.(TraceElementResult); ok {
+			res = append(res, t.TPre)
+		}
+	}
+	return res
+}
+
+/*
+ * Get the analysis score of a result in the machine readable format, e.g.
+ * P01,T:1:5:12:CS:main.go:10,T:2:5:20:CC:main.go:20
+ * Args:
+ *   result: the result line
+ * Returns:
+ *   float64: the score between 0 and 1
+ */
+func AnalysisScoreMachine(result string) float64 {
+	fields := strings.Split(result, ",")
+
+	tPres := make([][]int, 2)
+	for i := 1; i < len(fields) && i <= 2; i++ {
+		tPres[i-1] = make([]int, 0)
+		for _, elem := range strings.Split(fields[i], ";") {
+			// T:routine:objID:tPre:objType:file:line
+			elemFields := strings.Split(elem, ":")
+			if len(elemFields) < 4 || elemFields[0] != "T" {
+				continue
+			}
+			tPre, err := strconv.Atoi(elemFields[3])
+			if err != nil {
+				continue
+			}
+			tPres[i-1] = append(tPres[i-1], tPre)
+		}
+	}
+
+	return analysisScore(ResultType(fields[0]), tPres[0], tPres[1])
+}
+
+/*
+ * Get the confidence score of a result after it has been rewritten and
+ * replayed.
+ * Args:
+ *   analysis: the score of the analysis (AnalysisScoreMachine)
+ *   rewritten: 1 if a rewritten trace was created or no rewrite is needed, else 0
+ *   replay: 1 if the replay confirmed the bug, 0 if the replay finished
+ *     without confirming it, 0.5 if no replay result is available
+ *   runs: number of runs in which the bug was found
+ * Returns:
+ *   float64: the score between 0 and 1
+ */
+func ConfidenceScore(analysis float64, rewritten float64, replay float64, runs int) float64 {
+	runsScore := 1 - 1/(1+float64(runs))
+	return 0.35*analysis + 0.15*rewritten + 0.35*replay + 0.15*runsScore
+}
+
+/*
+ * Format a score for the output
+ * Args:
+ *   score: the score between 0 and 1
+ * Returns:
+ *   string: the score with two decimals
+ */
+func FormatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 2, 64)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: score_test.go
+// Brief: Tests for score.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-25
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalysisScoreMachine(t *testing.T) {
+	var tests = []struct {
+		name     string
+		result   string
+		expected float64
+	}{
+		{"Actual, same time", "A01,T:1:5:12:CS:main.go:10,T:2:5:12:CC:main.go:20", 1},
+		{"Possible, 50 operations", "P01,T:1:5:12:CS:main.go:10,T:2:5:112:CC:main.go:20", 0.55},
+		{"Leak without partner", "L02,T:1:5:12:CS:main.go:10", 0.5},
+		{"Leak without blocking", "L00,T:1:5:12:F:main.go:10", 0.35},
+		{"Multiple elements", "P01,T:1:5:12:CS:main.go:10;T:3:5:300:CS:main.go:12,T:2:5:312:CC:main.go:20", 0.75},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := AnalysisScoreMachine(test.result)
+			if FormatScore(res) != FormatScore(test.expected) {
+				t.Errorf("Incorrect score for %s. Expected %f. Got %f", test.result, test.expected, res)
+			}
+		})
+	}
+}
+
+func TestConfidenceScoreOrder(t *testing.T) {
+	confirmed := ConfidenceScore(0.5, 1, 1, 1)
+	notReplayed := ConfidenceScore(0.5, 1, 0.5, 1)
+	notConfirmed := ConfidenceScore(0.5, 1, 0, 1)
+	moreRuns := ConfidenceScore(0.5, 1, 0, 3)
+
+	if !(confirmed > notReplayed && notReplayed > notConfirmed) {
+		t.Errorf("Incorrect order for replay: %f, %f, %f", confirmed, notReplayed, notConfirmed)
+	}
+
+	if moreRuns <= notConfirmed {
+		t.Errorf("Incorrect order for runs: %f, %f", moreRuns, notConfirmed)
+	}
+}
+
+func TestSortByScore(t *testing.T) {
+	readable := []string{"a", "b", "c", "d"}
+	machine := []string{"A", "B", "C", "D"}
+	scores := []float64{0.2, 0.9, 0.2, 0.5}
+
+	sortByScore(readable, machine, scores)
+
+	if !reflect.DeepEqual(readable, []string{"b", "d", "a", "c"}) {
+		t.Errorf("Incorrect readable order: %v", readable)
+	}
+	if !reflect.DeepEqual(machine, []string{"B", "D", "A", "C"}) {
+		t.Errorf("Incorrect machine order: %v", machine)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.9, 0.5, 0.2, 0.2}) {
+		t.Errorf("Incorrect scores: %v", scores)
+	}
+}
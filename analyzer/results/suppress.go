@@ -0,0 +1,437 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: suppress.go
+// Brief: Suppression comments in the source code and baseline of known results
+//
+// Author: Erik Kassubek
+// Created: 2024-11-25
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"analyzer/utils"
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// comment to suppress a result, e.g. //advocate:ignore L06 reason
+const suppressDirective = "//advocate:ignore"
+
+// result types suppressed in a line of a file, file -> line -> result types
+var suppressions = make(map[string]map[int][]string)
+
+// number of results that have been suppressed by a comment or the configuration
+var numberSuppressed = 0
+
+/*
+ * Suppression from the configuration file
+ * Fields:
+ *   code (string): result type or prefix of it, e.g. P01 or L
+ *   pattern (string): if not empty, only results with an element in a file
+ *     matching the pattern are suppressed
+ */
+type configSuppression struct {
+	code    string
+	pattern string
+}
+
+// suppressions from the configuration file
+var configSuppressions []configSuppression
+
+/*
+ * Entry of the baseline
+ * Fields:
+ *   resType (ResultType): the result type
+ *   arg1 ([]string): positions of the first argument
+ *   arg2 ([]string): positions of the second argument
+ */
+type baselineEntry struct {
+	resType ResultType
+	arg1    []string
+	arg2    []string
+}
+
+// known results from the baseline file
+var baseline []baselineEntry
+
+// results that are in the baseline, in the baseline format
+var resultsBaselinedReadable []string
+
+// all found results that are not suppressed, in the baseline format
+var resultsBaselineKeys []string
+
+/*
+ * Get the result types that are suppressed in each line of a file.
+ * The file is only read once.
+ * Args:
+ *   file: path to the file
+ * Returns:
+ *   map[int][]string: line -> suppressed result types, empty if the file
+ *     cannot be read
+ */
+func getSuppressions(file string) map[int][]string {
+	if res, ok := suppressions[file]; ok {
+		return res
+	}
+
+	res := make(map[int][]string)
+	suppressions[file] = res
+
+	f, err := os.Open(file)
+	if err != nil {
+		return res
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		index := strings.Index(text, suppressDirective)
+		if index == -1 {
+			continue
+		}
+
+		fields := strings.Fields(text[index+len(suppressDirective):])
+		if len(fields) == 0 {
+			continue
+		}
+		codes := strings.Split(fields[0], ",")
+
+		res[line] = append(res[line], codes...)
+
+		// a comment in its own line applies to the next line
+		if strings.TrimSpace(text[:index]) == "" {
+			res[line+1] = append(res[line+1], codes...)
+		}
+	}
+
+	return res
+}
+
+/*
+ * Set the suppressions from the configuration file
+ * Args:
+ *   entries: suppressions in the form [code] or [code]:[pattern], e.g. L00
+ *     or P03:vendor/. The code can be a prefix of the result type, e.g. L
+ *     for all leaks. With a pattern, only results with an element in a file
+ *     matching the pattern are suppressed
+ */
+func SetConfigSuppressions(entries []string) {
+	configSuppressions = make([]configSuppression, 0, len(entries))
+	for _, entry := range entries {
+		code, pattern, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if code == "" {
+			continue
+		}
+		configSuppressions = append(configSuppressions, configSuppression{code, pattern})
+	}
+}
+
+/*
+ * Check if a result is suppressed by an //advocate:ignore comment
+ * in the line of one of its elements or by the configuration file
+ * Args:
+ *   resType: the result type
+ *   args: the elements of the result
+ * Returns:
+ *   bool: true if the result is suppressed
+ */
+func isSuppressed(resType ResultType, args ...[]ResultElem) bool {
+	for _, suppression := range configSuppressions {
+		if !strings.HasPrefix(string(resType), suppression.code) {
+			continue
+		}
+		if suppression.pattern == "" {
+			return true
+		}
+		for _, arg := range args {
+			for _, elem := range arg {
+				if t, ok := elem.(TraceElementResult); ok && utils.MatchGlob(suppression.pattern, t.File) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, arg := range args {
+		for _, elem := range arg {
+			t, ok := elem.(TraceElementResult)
+			if !ok {
+				continue
+			}
+
+			for _, code := range getSuppressions(t.File)[t.Line] {
+				if code == string(resType) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+/*
+ * Get the position of a result element as used in the baseline
+ * Args:
+ *   elem: the result element
+ * Returns:
+ *   string: file:line for elements, case:[objType]:[index] for select cases
+ */
+func baselinePos(elem ResultElem) string {
+	switch e := elem.(type) {
+	case TraceElementResult:
+		return e.File + ":" + strconv.Itoa(e.Line)
+	case SelectCaseResult:
+		return "case:" + e.ObjType + ":" + strconv.Itoa(e.Index)
+	}
+	return ""
+}
+
+/*
+ * Get the baseline entry of a result
+ * Args:
+ *   resType: the result type
+ *   arg1: the elements of the first argument
+ *   arg2: the elements of the second argument
+ * Returns:
+ *   baselineEntry: the entry
+ */
+func newBaselineEntry(resType ResultType, arg1 []ResultElem, arg2 []ResultElem) baselineEntry {
+	res := baselineEntry{resType: resType, arg1: make([]string, 0), arg2: make([]string, 0)}
+	for _, elem := range arg1 {
+		res.arg1 = append(res.arg1, baselinePos(elem))
+	}
+	for _, elem := range arg2 {
+		res.arg2 = append(res.arg2, baselinePos(elem))
+	}
+	return res
+}
+
+/*
+ * Get the element of a result from its machine readable format
+ * Args:
+ *   elem: the element, T:[routine]:[objID]:[tPre]:[objType]:[file]:[line]
+ *     or S:[objID]:[objType]:[index]
+ * Returns:
+ *   ResultElem: the element, nil if the element is invalid
+ */
+func parseMachineElem(elem string) ResultElem {
+	fields := strings.Split(elem, ":")
+	switch {
+	case len(fields) >= 7 && fields[0] == "T":
+		line, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			return nil
+		}
+		// the file can contain a colon
+		return TraceElementResult{ObjType: fields[4],
+			File: strings.Join(fields[5:len(fields)-1], ":"), Line: line}
+	case len(fields) == 4 && fields[0] == "S":
+		index, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil
+		}
+		return SelectCaseResult{ObjType: fields[2], Index: index}
+	}
+	return nil
+}
+
+/*
+ * Get the key of a result in the machine readable format, that does not
+ * depend on the run, i.e. the result type and the positions of the elements.
+ * The key has the same format as the entries of the baseline.
+ * Args:
+ *   result: the result line
+ * Returns:
+ *   string: the key
+ */
+func PositionKey(result string) string {
+	fields := strings.Split(result, ",")
+	args := make([][]ResultElem, 2)
+	for i := range args {
+		args[i] = make([]ResultElem, 0)
+		if i+1 >= len(fields) || fields[i+1] == "" {
+			continue
+		}
+		for _, elem := range strings.Split(fields[i+1], ";") {
+			if e := parseMachineElem(elem); e != nil {
+				args[i] = append(args[i], e)
+			}
+		}
+	}
+	return newBaselineEntry(ResultType(fields[0]), args[0], args[1]).toString()
+}
+
+/*
+ * Get the string representation of a baseline entry, e.g.
+ * P01,/home/user/prog/main.go:12,/home/user/prog/main.go:20
+ * The positions are escaped with utils.EscapeField, so they can contain
+ * commas and semicolons.
+ * Returns:
+ *   string: the entry as written to the baseline file
+ */
+func (b baselineEntry) toString() string {
+	return string(b.resType) + "," + joinBaselinePos(b.arg1) + "," + joinBaselinePos(b.arg2)
+}
+
+/*
+ * Join the escaped positions of an argument of a baseline entry
+ * Args:
+ *   pos: the positions
+ * Returns:
+ *   string: the positions separated by ;
+ */
+func joinBaselinePos(pos []string) string {
+	escaped := make([]string, 0, len(pos))
+	for _, p := range pos {
+		escaped = append(escaped, utils.EscapeField(p))
+	}
+	return strings.Join(escaped, ";")
+}
+
+/*
+ * Split and unescape the positions of an argument of a baseline entry
+ * Args:
+ *   arg: the positions separated by ;
+ * Returns:
+ *   []string: the positions
+ *   error: if a position contains an invalid escape sequence
+ */
+func splitBaselinePos(arg string) ([]string, error) {
+	res := make([]string, 0)
+	if arg == "" {
+		return res, nil
+	}
+	for _, p := range strings.Split(arg, ";") {
+		pos, err := utils.UnescapeField(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pos)
+	}
+	return res, nil
+}
+
+/*
+ * Check if a position of a result matches a position of the baseline.
+ * The positions in the baseline can be shortened, e.g. to a path
+ * relative to the module, so that the baseline can be checked in.
+ * Args:
+ *   pos: position of the result
+ *   known: position in the baseline
+ * Returns:
+ *   bool: true if the positions match
+ */
+func matchBaselinePos(pos string, known string) bool {
+	return pos == known || strings.HasSuffix(pos, "/"+strings.TrimPrefix(known, "/"))
+}
+
+/*
+ * Check if two lists of positions match
+ * Args:
+ *   pos: positions of the result
+ *   known: positions in the baseline
+ * Returns:
+ *   bool: true if all positions match
+ */
+func matchBaselineArg(pos []string, known []string) bool {
+	if len(pos) != len(known) {
+		return false
+	}
+	for i := range pos {
+		if !matchBaselinePos(pos[i], known[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ * Check if a result is in the baseline
+ * Args:
+ *   entry: the result as baseline entry
+ * Returns:
+ *   bool: true if the result is in the baseline
+ */
+func inBaseline(entry baselineEntry) bool {
+	for _, known := range baseline {
+		if known.resType == entry.resType && matchBaselineArg(entry.arg1, known.arg1) &&
+			matchBaselineArg(entry.arg2, known.arg2) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Read the baseline of known results. Results in the baseline are shown
+ * separately from new results and are not written to the machine result
+ * file, so they are not rewritten.
+ * Each line of the file has the form
+ *   [resType],[pos];[pos],[pos];[pos]
+ * with the positions (file:line) of the first and second argument.
+ * The positions are escaped with utils.EscapeField.
+ * Empty lines and lines starting with # are ignored.
+ * Args:
+ *   path: path to the baseline file, if empty no baseline is used
+ * Returns:
+ *   error: if the file cannot be read or contains an invalid line
+ */
+func SetBaseline(path string) error {
+	baseline = make([]baselineEntry, 0)
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 || fields[0] == "" || fields[1] == "" {
+			return errors.New("Invalid baseline entry in line " + strconv.Itoa(i+1) + ": " + line)
+		}
+
+		arg1, err1 := splitBaselinePos(fields[1])
+		arg2, err2 := splitBaselinePos(fields[2])
+		if err1 != nil || err2 != nil {
+			return errors.New("Invalid escape sequence in baseline entry in line " + strconv.Itoa(i+1) + ": " + line)
+		}
+
+		baseline = append(baseline, baselineEntry{resType: ResultType(fields[0]), arg1: arg1, arg2: arg2})
+	}
+
+	return nil
+}
+
+/*
+ * Write all found results that are not suppressed, including the results
+ * that are already in the baseline, as new baseline
+ * Args:
+ *   path: path to the baseline file
+ * Returns:
+ *   error: if the file cannot be written
+ */
+func WriteBaseline(path string) error {
+	res := "# Known results of Advocate. Results in this file are not reported as new results.\n"
+	res += "# [resType],[positions of first argument],[positions of second argument]\n"
+	res += "# Commas, semicolons and percent signs in the positions are escaped as %2C, %3B and %25\n"
+	for _, key := range resultsBaselineKeys {
+		res += key + "\n"
+	}
+	return os.WriteFile(path, []byte(res), 0644)
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: suppress_test.go
+// Brief: Tests for suppress.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-25
+//
+// License: BSD-3-Clause
+
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSuppressed(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	code := "package main\n\n" +
+		"func main() {\n" +
+		"\tclose(c) //advocate:ignore P01,P02 closed on purpose\n" +
+		"\t//advocate:ignore L02\n" +
+		"\tc <- 1\n" +
+		"}\n"
+	if err := os.WriteFile(file, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name     string
+		resType  ResultType
+		line     int
+		expected bool
+	}{
+		{"Same line", PSendOnClosed, 4, true},
+		{"Same line second code", PRecvOnClosed, 4, true},
+		{"Other code", ASendOnClosed, 4, false},
+		{"Line above", LUnbufferedWithout, 6, true},
+		{"Comment line", LUnbufferedWithout, 5, true},
+		{"No comment", PSendOnClosed, 3, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			arg := []ResultElem{TraceElementResult{ObjType: "CC", File: file, Line: test.line}}
+			if res := isSuppressed(test.resType, arg); res != test.expected {
+				t.Errorf("Incorrect suppression of %s in line %d. Expected %t. Got %t",
+					test.resType, test.line, test.expected, res)
+			}
+		})
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	defer func() { baseline = make([]baselineEntry, 0) }()
+
+	file := filepath.Join(t.TempDir(), "baseline")
+	content := "# comment\n\n" +
+		"P01,prog/main.go:12,prog/main.go:20\n" +
+		"L02,/home/user/prog/worker.go:7,\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetBaseline(file); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	elem := func(file string, line int) []ResultElem {
+		return []ResultElem{TraceElementResult{ObjType: "CS", File: file, Line: line}}
+	}
+
+	var tests = []struct {
+		name     string
+		resType  ResultType
+		arg1     []ResultElem
+		arg2     []ResultElem
+		expected bool
+	}{
+		{"Suffix", PSendOnClosed, elem("/home/user/prog/main.go", 12), elem("/home/user/prog/main.go", 20), true},
+		{"Other type", PRecvOnClosed, elem("/home/user/prog/main.go", 12), elem("/home/user/prog/main.go", 20), false},
+		{"Other line", PSendOnClosed, elem("/home/user/prog/main.go", 13), elem("/home/user/prog/main.go", 20), false},
+		{"Partial file name", PSendOnClosed, elem("/home/user/myprog/main.go", 12), elem("/home/user/myprog/main.go", 20), false},
+		{"Without second argument", LUnbufferedWithout, elem("/home/user/prog/worker.go", 7), []ResultElem{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := newBaselineEntry(test.resType, test.arg1, test.arg2)
+			if res := inBaseline(entry); res != test.expected {
+				t.Errorf("Incorrect result for %s. Expected %t. Got %t", entry.toString(), test.expected, res)
+			}
+		})
+	}
+
+	if err := os.WriteFile(file, []byte("P01\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetBaseline(file); err == nil {
+		t.Errorf("Expected error for invalid baseline")
+	}
+}
+
+func TestConfigSuppressions(t *testing.T) {
+	defer SetConfigSuppressions(nil)
+
+	SetConfigSuppressions([]string{"L", "P01:vendor/*", " "})
+
+	elem := func(file string) []ResultElem {
+		return []ResultElem{TraceElementResult{ObjType: "CS", File: file, Line: 3}}
+	}
+
+	var tests = []struct {
+		name     string
+		resType  ResultType
+		arg      []ResultElem
+		expected bool
+	}{
+		{"Prefix", LUnbufferedWithout, elem("/prog/main.go"), true},
+		{"Matching pattern", PSendOnClosed, elem("/prog/vendor/lib/lib.go"), true},
+		{"Not matching pattern", PSendOnClosed, elem("/prog/main.go"), false},
+		{"Other type", PRecvOnClosed, elem("/prog/vendor/lib/lib.go"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := isSuppressed(test.resType, test.arg); res != test.expected {
+				t.Errorf("Incorrect suppression of %s in %v. Expected %t. Got %t",
+					test.resType, test.arg, test.expected, res)
+			}
+		})
+	}
+}
+
+func TestBaselineEscaped(t *testing.T) {
+	defer func() { baseline = make([]baselineEntry, 0) }()
+
+	arg1 := []ResultElem{TraceElementResult{ObjType: "CS", File: "/prog/a,b;c%d/main.go", Line: 4}}
+	arg2 := []ResultElem{TraceElementResult{ObjType: "CR", File: "/prog/main.go", Line: 9},
+		SelectCaseResult{ObjType: "CR", Index: 1}}
+	entry := newBaselineEntry(PSendOnClosed, arg1, arg2)
+
+	expected := "P01,/prog/a%2Cb%3Bc%25d/main.go:4,/prog/main.go:9;case:CR:1"
+	if entry.toString() != expected {
+		t.Errorf("Incorrect baseline entry. Expected %s. Got %s", expected, entry.toString())
+	}
+
+	file := filepath.Join(t.TempDir(), "baseline")
+	if err := os.WriteFile(file, []byte(entry.toString()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetBaseline(file); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if !inBaseline(entry) {
+		t.Errorf("Entry %s should be in the baseline", entry.toString())
+	}
+
+	if err := os.WriteFile(file, []byte("P01,/prog/%zz.go:4,\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetBaseline(file); err == nil {
+		t.Errorf("Expected error for invalid escape sequence")
+	}
+}
+
+func TestPositionKey(t *testing.T) {
+	var tests = []struct {
+		name     string
+		result   string
+		expected string
+	}{
+		{"Two arguments", "P01,T:1:2:10:CS:/prog/main.go:12,T:2:2:14:CC:/prog/main.go:20",
+			"P01,/prog/main.go:12,/prog/main.go:20"},
+		{"Independent of run", "P01,T:3:5:99:CS:/prog/main.go:12,T:4:5:7:CC:/prog/main.go:20",
+			"P01,/prog/main.go:12,/prog/main.go:20"},
+		{"Select case", "L03,T:1:2:10:SS:/prog/main.go:5,S:3:CR:1;S:4:CS:0",
+			"L03,/prog/main.go:5,case:CR:1;case:CS:0"},
+		{"One argument", "L02,T:1:2:10:CS:/prog/main.go:7,", "L02,/prog/main.go:7,"},
+		{"Colon and percent in file", "P01,T:1:2:10:CS:C:/prog/a%b.go:12,",
+			"P01,C:/prog/a%25b.go:12,"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := PositionKey(test.result); res != test.expected {
+				t.Errorf("Incorrect key. Expected %s. Got %s", test.expected, res)
+			}
+		})
+	}
+}
@@ -62,7 +62,7 @@ import (
  * end()
  */
 
-func rewriteCyclicDeadlock(bug bugs.Bug) error {
+func rewriteCyclicDeadlock(trace *analysis.TraceView, bug bugs.Bug) error {
 	firstTime := -1
 	lastTime := -1
 
@@ -82,7 +82,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 	}
 
 	// remove tail after lastTime
-	analysis.ShortenTrace(lastTime, true)
+	trace.ShortenTrace(lastTime, true)
 
 	routinesInCycle := make(map[int]struct{})
 
@@ -103,7 +103,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 			}
 
 			// shift the routine of elem1 so that elem 2 is before elem1
-			res := analysis.ShiftRoutine(elem1.GetRoutine(), elem1.GetTPre(), elem2.GetTPre()-elem1.GetTPre()+1)
+			res := trace.ShiftRoutine(elem1.GetRoutine(), elem1.GetTPre(), elem2.GetTPre()-elem1.GetTPre()+1)
 
 			if res {
 				found = true
@@ -115,17 +115,17 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 		}
 	}
 
-	currentTrace := analysis.GetTraces()
+	currentTrace := trace.GetTraces()
 	lastTime = -1
 
 	for routine := range routinesInCycle {
 		found := false
-		for i := len((*currentTrace)[routine]) - 1; i >= 0; i-- {
-			elem := (*currentTrace)[routine][i]
+		for i := len(currentTrace[routine]) - 1; i >= 0; i-- {
+			elem := currentTrace[routine][i]
 			switch elem := elem.(type) {
 			case *analysis.TraceElementMutex:
 				if (*elem).IsLock() {
-					analysis.ShortenRoutineIndex(routine, i, true)
+					trace.ShortenRoutineIndex(routine, i, true)
 					if lastTime == -1 || (*elem).GetTSort() > lastTime {
 						lastTime = (*elem).GetTSort()
 					}
@@ -139,7 +139,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 	}
 
 	// add start and end signal
-	analysis.AddTraceElementReplay(lastTime+1, exitCodeCyclic, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+	trace.AddTraceElementReplay(lastTime+1, exitCodeCyclic, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	return nil
 }
@@ -18,10 +18,11 @@ import (
 /*
  * Create a new trace for a negative wait group counter (done before add)
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  *   expectedErrorCode (int): For wg exitNegativeWG, for unlock before lock: exitUnlockBeforeLock
  */
-func rewriteGraph(bug bugs.Bug, expectedErrorCode int) error {
+func rewriteGraph(trace *analysis.TraceView, bug bugs.Bug, expectedErrorCode int) error {
 	if bug.Type == bugs.PNegWG {
 		println("Start rewriting trace for negative waitgroup counter...")
 	} else if bug.Type == bugs.PUnlockBeforeLock {
@@ -34,7 +35,7 @@ func rewriteGraph(bug bugs.Bug, expectedErrorCode int) error {
 	for i := range bug.TraceElement2 {
 		elem1 := bug.TraceElement1[i] // done/unlock
 
-		analysis.ShiftConcurrentOrAfterToAfter(elem1)
+		trace.ShiftConcurrentOrAfterToAfter(elem1)
 
 		if minTime == -1 || elem1.GetTPre() < minTime {
 			minTime = elem1.GetTPre()
@@ -47,7 +48,7 @@ func rewriteGraph(bug bugs.Bug, expectedErrorCode int) error {
 
 	// add start and end
 	if !(minTime == -1 && maxTime == -1) {
-		analysis.AddTraceElementReplay(maxTime+1, expectedErrorCode, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(maxTime+1, expectedErrorCode, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	}
 
 	return nil
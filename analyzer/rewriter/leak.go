@@ -37,11 +37,12 @@ import (
 /*
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeak(bug bugs.Bug) error {
+func rewriteUnbufChanLeak(trace *analysis.TraceView, bug bugs.Bug) error {
 	// check if one or both of the bug elements are select
 	t1Sel := false
 	t2Sel := false
@@ -55,24 +56,25 @@ func rewriteUnbufChanLeak(bug bugs.Bug) error {
 	}
 
 	if !t1Sel && !t2Sel { // both are channel operations
-		return rewriteUnbufChanLeakChanChan(bug)
+		return rewriteUnbufChanLeakChanChan(trace, bug)
 	} else if !t1Sel && t2Sel { // first is channel operation, second is select
-		return rewriteUnbufChanLeakChanSel(bug)
+		return rewriteUnbufChanLeakChanSel(trace, bug)
 	} else if t1Sel && !t2Sel { // first is select, second is channel operation
-		return rewriteUnbufChanLeakSelChan(bug)
+		return rewriteUnbufChanLeakSelChan(trace, bug)
 	} // both are select
-	return rewriteUnbufChanLeakSelSel(bug)
+	return rewriteUnbufChanLeakSelSel(trace, bug)
 }
 
 /*
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are channel operations.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
+func rewriteUnbufChanLeakChanChan(trace *analysis.TraceView, bug bugs.Bug) error {
 	stuck := bug.TraceElement1[0].(*analysis.TraceElementChannel)
 	possiblePartner := bug.TraceElement2[0].(*analysis.TraceElementChannel)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -89,39 +91,39 @@ func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		analysis.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if stuck.Operation() == analysis.RecvOp { // Case 3
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 
 		// add replay signals
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	} else { // Case 4
 		if possiblePartnerPartner != nil {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4' = [h in T4 | h >= e and h < f]
 
 		// add replay signal
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	}
 
 	return nil
@@ -131,11 +133,12 @@ func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if a channel is stuck and a select is a possible partner
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
+func rewriteUnbufChanLeakChanSel(trace *analysis.TraceView, bug bugs.Bug) error {
 	stuck := bug.TraceElement1[0].(*analysis.TraceElementChannel)
 	possiblePartner := bug.TraceElement2[0].(*analysis.TraceElementSelect)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -152,13 +155,13 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		analysis.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if stuck.Operation() == analysis.RecvOp { // Case 3
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
@@ -169,20 +172,20 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
 		}
 
 		// add replay signal
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	} else { // Case 4
 		if possiblePartnerPartner != nil {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
@@ -194,7 +197,7 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
 		}
 
 		// add replay signal
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	}
 
 	return nil
@@ -204,11 +207,12 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if a select is stuck and a channel is a possible partner
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
+func rewriteUnbufChanLeakSelChan(trace *analysis.TraceView, bug bugs.Bug) error {
 	stuck := bug.TraceElement1[0].(*analysis.TraceElementSelect)
 	possiblePartner := bug.TraceElement2[0].(*analysis.TraceElementChannel)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -225,23 +229,23 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		analysis.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if possiblePartner.Operation() == analysis.RecvOp {
 		if possiblePartnerPartner != nil {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		err := bug.TraceElement1[0].(*analysis.TraceElementSelect).SetCase(stuck.GetID(), analysis.SendOp)
 		if err != nil {
@@ -252,10 +256,10 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4' = [h in T4 | h >= e and h < f]
 		// add replay signals
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	} else { // Case 3
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
@@ -266,7 +270,7 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
 		}
 
 		// add replay signal
-		analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	}
 
@@ -277,11 +281,12 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are select operations.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
+func rewriteUnbufChanLeakSelSel(trace *analysis.TraceView, bug bugs.Bug) error {
 	stuck := bug.TraceElement1[0].(*analysis.TraceElementSelect)
 	possiblePartner := bug.TraceElement2[0].(*analysis.TraceElementSelect)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -298,7 +303,7 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		analysis.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// find communication
@@ -315,7 +320,7 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 			// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 			if c.Operation() == analysis.RecvOp { // Case 3
-				analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+				trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 				// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 				// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
@@ -330,18 +335,18 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 				}
 
 				// add replay signal
-				analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+				trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 				return nil
 			}
 
 			// Case 4
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 			// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 			// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 			// and T4 = [h in T4 | h >= e]
 
-			analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 			// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 			// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
@@ -357,7 +362,7 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 			}
 
 			// add replay signals
-			analysis.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+			trace.AddTraceElementReplay(max(bug.TraceElement1[0].GetTSort(), bug.TraceElement2[0].GetTSort())+1, exitCodeLeakUnbuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 			return nil
 		}
@@ -369,11 +374,12 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 /*
  * Rewrite a trace for a leaking buffered channel
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteBufChanLeak(bug bugs.Bug) error {
+func rewriteBufChanLeak(trace *analysis.TraceView, bug bugs.Bug) error {
 	stuck := bug.TraceElement1[0]
 	possiblePartner := bug.TraceElement2[0]
 	var possiblePartnerPartner *analysis.TraceElementChannel
@@ -394,11 +400,11 @@ func rewriteBufChanLeak(bug bugs.Bug) error {
 
 	if possiblePartnerPartner != nil {
 		// T = T1 ++ T2 ++ [e]
-		analysis.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 
 		// T = T1 ++ T2' ++ [e]
 		// where T2' = [ h | h in T2 and h <HB e]
-		analysis.ShiftConcurrentOrAfterToAfterStartingFromElement(stuck, possiblePartnerPartner.GetTSort())
+		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(stuck, possiblePartnerPartner.GetTSort())
 	}
 
 	bug.TraceElement1[0].SetTSort(possiblePartner.GetTSort() + 1)
@@ -407,9 +413,9 @@ func rewriteBufChanLeak(bug bugs.Bug) error {
 	println("partn: ", bug.TraceElement2[0].ToString())
 
 	if possiblePartner.GetTSort() < stuck.GetTSort() {
-		analysis.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakBuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakBuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	} else {
-		analysis.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakBuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakBuf, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	}
 
 	return nil
@@ -433,11 +439,12 @@ func rewriteBufChanLeak(bug bugs.Bug) error {
  * are before (HB) l, X_s is the start and X_e is the stop signal, that releases the program from the
  * guided replay.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteMutexLeak(bug bugs.Bug) error {
+func rewriteMutexLeak(trace *analysis.TraceView, bug bugs.Bug) error {
 	println("Start rewriting trace for mutex leak...")
 
 	// get l and l'
@@ -450,17 +457,17 @@ func rewriteMutexLeak(bug bugs.Bug) error {
 	}
 
 	// remove T_3 -> T_1 + [l'] + T_2 + [l]
-	analysis.ShortenTrace(lockOp.GetTSort(), true)
+	trace.ShortenTrace(lockOp.GetTSort(), true)
 
 	// remove all elements, that are concurrent with l. This includes l'
 	// -> T_1' + T_2' + [l]
-	analysis.RemoveConcurrent(bug.TraceElement1[0], 0)
+	trace.RemoveConcurrent(bug.TraceElement1[0], 0)
 
 	// set tpost of l to non zero
 	lockOp.SetT(lockOp.GetTPre())
 
 	// add the start and stop signal after l -> T_1' + T_2' + [X_s, l, X_e]
-	analysis.AddTraceElementReplay(lockOp.GetTPre()+1, exitCodeLeakMutex, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+	trace.AddTraceElementReplay(lockOp.GetTPre()+1, exitCodeLeakMutex, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
 	return nil
 }
@@ -471,20 +478,21 @@ func rewriteMutexLeak(bug bugs.Bug) error {
 /*
  * Rewrite a trace where a leaking waitgroup was found.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteWaitGroupLeak(bug bugs.Bug) error {
+func rewriteWaitGroupLeak(trace *analysis.TraceView, bug bugs.Bug) error {
 	println("Start rewriting trace for waitgroup leak...")
 
 	wait := bug.TraceElement1[0]
 
-	analysis.ShiftConcurrentOrAfterToAfter(wait)
+	trace.ShiftConcurrentOrAfterToAfter(wait)
 
-	analysis.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakWG, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+	trace.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakWG, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 
-	nrAdd, nrDone := analysis.GetNrAddDoneBeforeTime(wait.GetID(), wait.GetTSort())
+	nrAdd, nrDone := trace.GetNrAddDoneBeforeTime(wait.GetID(), wait.GetTSort())
 
 	if nrAdd != nrDone {
 		return errors.New("The waitgroup is not balanced. Cannot rewrite trace.")
@@ -499,18 +507,19 @@ func rewriteWaitGroupLeak(bug bugs.Bug) error {
 /*
  * Rewrite a trace where a leaking cond was found.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteCondLeak(bug bugs.Bug) error {
+func rewriteCondLeak(trace *analysis.TraceView, bug bugs.Bug) error {
 	println("Start rewriting trace for cond leak...")
 
 	couldRewrite := false
 
 	wait := bug.TraceElement1[0]
 
-	res := analysis.GetConcurrentWaitgroups(wait)
+	res := trace.GetConcurrentWaitgroups(wait)
 
 	// possible signals to release the wait
 	if len(res["signal"]) > 0 {
@@ -519,7 +528,7 @@ func rewriteCondLeak(bug bugs.Bug) error {
 		wait.SetT(wait.GetTPre())
 
 		// move the signal after the wait
-		analysis.ShiftConcurrentOrAfterToAfter(wait)
+		trace.ShiftConcurrentOrAfterToAfter(wait)
 
 		// TODO: Problem: locks create a happens before relation -> currently only works with -c
 	}
@@ -527,15 +536,15 @@ func rewriteCondLeak(bug bugs.Bug) error {
 	// possible broadcasts to release the wait
 	for _, broad := range res["broadcast"] {
 		couldRewrite = true
-		analysis.ShiftConcurrentToBefore(broad)
+		trace.ShiftConcurrentToBefore(broad)
 	}
 
 	wait.SetT(wait.GetTPre())
 
 	if len(bug.TraceElement2) == 0 {
-		analysis.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakCond, bug.TraceElement1[0].GetTPre())
+		trace.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakCond, bug.TraceElement1[0].GetTPre())
 	} else {
-		analysis.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakCond, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
+		trace.AddTraceElementReplay(wait.GetTPre()+1, exitCodeLeakCond, max(bug.TraceElement1[0].GetTPre(), bug.TraceElement2[0].GetTPre()))
 	}
 
 	if couldRewrite {
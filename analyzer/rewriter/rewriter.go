@@ -16,6 +16,7 @@ import (
 	"analyzer/bugs"
 	timemeasurement "analyzer/timeMeasurement"
 	"errors"
+	"time"
 )
 
 const (
@@ -37,8 +38,11 @@ const (
 )
 
 /*
- * Create a new trace from the given bug
+ * Create a new trace from the given bug. Only the view is changed, so
+ * that multiple bugs can be rewritten concurrently on different views of
+ * the same trace.
  * Args:
+ *   trace (*analysis.TraceView): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  *   index (int): only used for rewrite select with partner, index of partner
  * Returns:
@@ -46,9 +50,16 @@ const (
  *   code: expected exit code
  *   error: An error if the trace could not be created
  */
-func RewriteTrace(bug bugs.Bug, index int) (rewriteNeeded bool, code int, err error) {
-	timemeasurement.Start("rewrite")
-	defer timemeasurement.End("rewrite")
+func RewriteTrace(trace *analysis.TraceView, bug bugs.Bug, index int) (rewriteNeeded bool, code int, err error) {
+	start := time.Now()
+	defer func() {
+		timemeasurement.Add("rewrite", time.Since(start))
+	}()
+
+	// the elements of the bug are changed by the rewrite, use the
+	// elements of the view instead of the elements of the trace
+	bug.TraceElement1 = viewElements(trace, bug.TraceElement1)
+	bug.TraceElement2 = viewElements(trace, bug.TraceElement2)
 
 	rewriteNeeded = false
 	code = exitCodeNone
@@ -66,36 +77,36 @@ func RewriteTrace(bug bugs.Bug, index int) (rewriteNeeded bool, code int, err er
 	case bugs.PSendOnClosed:
 		code = exitSendClose
 		rewriteNeeded = true
-		err = rewriteClosedChannel(bug, exitSendClose)
+		err = rewriteClosedChannel(trace, bug, exitSendClose)
 	case bugs.PRecvOnClosed:
 		code = exitRecvClose
 		rewriteNeeded = true
-		err = rewriteClosedChannel(bug, exitRecvClose)
+		err = rewriteClosedChannel(trace, bug, exitRecvClose)
 	case bugs.PNegWG:
 		code = exitNegativeWG
 		rewriteNeeded = true
-		err = rewriteGraph(bug, code)
+		err = rewriteGraph(trace, bug, code)
 	case bugs.PUnlockBeforeLock:
 		code = exitUnlockBeforeLock
 		rewriteNeeded = true
-		err = rewriteGraph(bug, code)
+		err = rewriteGraph(trace, bug, code)
 	// case bugs.MixedDeadlock:
 	// 	err = errors.New("Rewriting trace for mixed deadlock is not implemented yet")
 	// case bugs.CyclicDeadlock:
 	// 	rewriteNeeded = true
-	// err = rewriteCyclicDeadlock(bug)
+	// err = rewriteCyclicDeadlock(trace, bug)
 	case bugs.LWithoutBlock:
 		err = errors.New("Source of blocking not known. Therefore no rewrite is possible.")
 	case bugs.LUnbufferedWith:
 		code = exitCodeLeakUnbuf
 		rewriteNeeded = true
-		err = rewriteUnbufChanLeak(bug)
+		err = rewriteUnbufChanLeak(trace, bug)
 	case bugs.LUnbufferedWithout:
 		err = errors.New("No possible partner for stuck channel found. Cannot rewrite trace.")
 	case bugs.LBufferedWith:
 		code = exitCodeLeakBuf
 		rewriteNeeded = true
-		err = rewriteBufChanLeak(bug)
+		err = rewriteBufChanLeak(trace, bug)
 	case bugs.LBufferedWithout:
 		err = errors.New("No possible partner for stuck channel found. Cannot rewrite trace.")
 	case bugs.LNilChan:
@@ -105,12 +116,12 @@ func RewriteTrace(bug bugs.Bug, index int) (rewriteNeeded bool, code int, err er
 		rewriteNeeded = true
 		switch b := bug.TraceElement2[0].(type) {
 		case *analysis.TraceElementSelect:
-			err = rewriteUnbufChanLeak(bug)
+			err = rewriteUnbufChanLeak(trace, bug)
 		case *analysis.TraceElementChannel:
 			if b.IsBuffered() {
-				err = rewriteBufChanLeak(bug)
+				err = rewriteBufChanLeak(trace, bug)
 			} else {
-				err = rewriteUnbufChanLeak(bug)
+				err = rewriteUnbufChanLeak(trace, bug)
 			}
 		default:
 			rewriteNeeded = false
@@ -123,19 +134,19 @@ func RewriteTrace(bug bugs.Bug, index int) (rewriteNeeded bool, code int, err er
 	case bugs.LMutex:
 		rewriteNeeded = true
 		code = exitCodeLeakMutex
-		err = rewriteMutexLeak(bug)
+		err = rewriteMutexLeak(trace, bug)
 	case bugs.LWaitGroup:
 		rewriteNeeded = true
 		code = exitCodeLeakWG
-		err = rewriteWaitGroupLeak(bug)
+		err = rewriteWaitGroupLeak(trace, bug)
 	case bugs.LCond:
 		rewriteNeeded = true
 		code = exitCodeLeakCond
-		err = rewriteCondLeak(bug)
+		err = rewriteCondLeak(trace, bug)
 	case bugs.SNotExecutedWithPartner:
 		rewriteNeeded = true
 		code = exitCodeNone
-		err = rewriteNotExecutedSelect(bug, index)
+		err = rewriteNotExecutedSelect(trace, bug, index)
 	default:
 		err = errors.New("For the given bug type no trace rewriting is implemented")
 	}
@@ -144,3 +155,19 @@ func RewriteTrace(bug bugs.Bug, index int) (rewriteNeeded bool, code int, err er
 	}
 	return rewriteNeeded, code, err
 }
+
+/*
+ * Get the elements of the view for the given elements
+ * Args:
+ *   trace (*analysis.TraceView): The view
+ *   elems ([]analysis.TraceElement): The elements
+ * Returns:
+ *   []analysis.TraceElement: The elements of the view
+ */
+func viewElements(trace *analysis.TraceView, elems []analysis.TraceElement) []analysis.TraceElement {
+	res := make([]analysis.TraceElement, 0, len(elems))
+	for _, elem := range elems {
+		res = append(res, trace.Element(elem))
+	}
+	return res
+}
@@ -19,7 +19,7 @@ import (
 )
 
 // ========= Not executed select with partner =========================
-func rewriteNotExecutedSelect(bug bugs.Bug, index int) error {
+func rewriteNotExecutedSelect(trace *analysis.TraceView, bug bugs.Bug, index int) error {
 	sel := bug.TraceElement1[0]
 	if sel.GetTSort() == 0 {
 		return fmt.Errorf("Cannot rewrite not executed case, select was not executed")
@@ -32,7 +32,7 @@ func rewriteNotExecutedSelect(bug bugs.Bug, index int) error {
 
 	selPartner := sel.(*analysis.TraceElementSelect).GetPartner()
 	if selPartner != nil {
-		selPartner.SetTPost(0)
+		trace.Element(selPartner).(*analysis.TraceElementChannel).SetTPost(0)
 	}
 
 	if ca.ObjType == "CS" {
@@ -45,7 +45,7 @@ func rewriteNotExecutedSelect(bug bugs.Bug, index int) error {
 
 	partner := bug.TraceElement1[0].(*analysis.TraceElementSelect).GetPartner()
 	if partner != nil {
-		analysis.RemoveElementFromTrace(bug.TraceElement1[0].(*analysis.TraceElementSelect).GetPartner().GetTID())
+		trace.RemoveElementFromTrace(bug.TraceElement1[0].(*analysis.TraceElementSelect).GetPartner().GetTID())
 	}
 
 	return nil
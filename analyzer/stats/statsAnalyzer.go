@@ -12,6 +12,7 @@ package stats
 
 import (
 	"analyzer/explanation"
+	"analyzer/results"
 	"bufio"
 	"fmt"
 	"os"
@@ -58,6 +59,12 @@ func statsAnalyzer(pathToResults string) (map[string]map[string]int, error) {
 		"unexpectedPanic":  unexpactedPanic,
 	}
 
+	// use the result records if they exist, otherwise parse the bug reports
+	if records, err := results.ReadRecords(pathToResults); err == nil {
+		processRecords(records, &res)
+		return res, nil
+	}
+
 	bugs := filepath.Join(pathToResults, "bugs")
 	_, err := os.Stat(bugs)
 	if os.IsNotExist(err) {
@@ -165,3 +172,36 @@ func processBugFile(filePath string, info *map[string]map[string]int) error {
 
 	return nil
 }
+
+/*
+ * Get the information from the result records
+ * Args:
+ *     records ([]results.Record): the result records
+ *     info (*map[string]map[string]int): map to store the info in
+ */
+func processRecords(records []results.Record, info *map[string]map[string]int) {
+	for _, record := range records {
+		// no bug report is written for bugs that were already found before
+		if record.Rewrite == results.RewriteDouble {
+			continue
+		}
+
+		(*info)["detected"][record.Type]++
+
+		if record.Rewrite == results.RewriteDone {
+			(*info)["replayWritten"][record.Type]++
+		}
+
+		if record.Verdict == results.VerdictConfirmed {
+			(*info)["replaySuccessful"][record.Type]++
+		}
+
+		if record.ExitCode == 3 {
+			(*info)["unexpectedPanic"][record.Type]++
+		}
+
+		if !strings.HasPrefix(record.ID, "0_") {
+			(*info)["rerecorded"][record.Type]++
+		}
+	}
+}
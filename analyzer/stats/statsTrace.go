@@ -183,6 +183,10 @@ func parseTraceFile(tracePath string, stats *map[string]int, known *map[string][
 			}
 		case "E":
 			(*stats)["numberRoutineEnds"]++
+		case "I":
+			// creation of an object, no operation
+		case "H":
+			// sync edge of a filtered operation, no operation
 		default:
 			err = errors.New("Unknown trace element: " + fields[0])
 		}
@@ -12,20 +12,26 @@ package timemeasurement
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
 var duration = make(map[string]time.Duration)
 var start = make(map[string]time.Time)
+var mutex sync.Mutex
 
 // counter : total,leak,panic, io, rewrite, other (other beeing untriggered select, recv on closed usw)
 // time for HBAnalysis: total - everythingElse
 
 func Start(counter string) {
+	mutex.Lock()
+	defer mutex.Unlock()
 	start[counter] = time.Now()
 }
 
 func End(counter string) {
+	mutex.Lock()
+	defer mutex.Unlock()
 	if _, ok := duration[counter]; !ok {
 		duration[counter] = time.Since(start[counter])
 	} else {
@@ -34,6 +40,19 @@ func End(counter string) {
 	start[counter] = time.Now()
 }
 
+/*
+ * Add a duration to a counter. Used for parts of the analysis that run
+ * concurrently, where Start and End cannot be used
+ * Args:
+ *   counter (string): the counter
+ *   d (time.Duration): the duration to add
+ */
+func Add(counter string, d time.Duration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	duration[counter] += d
+}
+
 func Print() {
 	fmt.Printf("AdvocateAnalysisTimes:%.5f#%.5f#%.5f#%.5f\n",
 		duration["analysis"].Seconds(),
@@ -0,0 +1,121 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: filter.go
+// Brief: Include and exclude patterns for files
+//
+// Author: Erik Kassubek
+// Created: 2024-11-19
+//
+// License: BSD-3-Clause
+
+package utils
+
+import "strings"
+
+/*
+ * Split a comma separated list of patterns
+ * Args:
+ *   patterns: the list
+ * Returns:
+ *   []string: the patterns without empty entries
+ */
+func SplitPatterns(patterns string) []string {
+	res := make([]string, 0)
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern != "" {
+			res = append(res, pattern)
+		}
+	}
+	return res
+}
+
+/*
+ * Check if a file is filtered out by the include and exclude patterns.
+ * This is the same filter that is used by the runtime when recording.
+ * Args:
+ *   file: the file path
+ *   include: if not empty, only files matching one of the patterns are kept
+ *   exclude: files matching one of the patterns are filtered out
+ * Returns:
+ *   bool: true if the file is filtered out
+ */
+func IsFiltered(file string, include []string, exclude []string) bool {
+	if len(include) > 0 && !MatchAny(file, include) {
+		return true
+	}
+	return MatchAny(file, exclude)
+}
+
+/*
+ * Check if a file matches any of the patterns
+ * Args:
+ *   file: the file path
+ *   patterns: the patterns
+ * Returns:
+ *   bool: true if at least one pattern matches
+ */
+func MatchAny(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if a pattern matches any part of s.
+ * '*' matches any sequence of characters, '?' matches a single character.
+ * Args:
+ *   pattern: the pattern
+ *   s: the string
+ * Returns:
+ *   bool: true if the pattern matches
+ */
+func MatchGlob(pattern string, s string) bool {
+	for start := 0; start <= len(s); start++ {
+		if matchPrefix(pattern, s[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if a pattern matches a prefix of s
+ * Args:
+ *   pattern: the pattern
+ *   s: the string
+ * Returns:
+ *   bool: true if the pattern matches a prefix of s
+ */
+func matchPrefix(pattern string, s string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for {
+		if p == len(pattern) {
+			return true
+		}
+
+		if pattern[p] == '*' {
+			starP, starI = p, i
+			p++
+			continue
+		}
+
+		if i < len(s) && (pattern[p] == '?' || pattern[p] == s[i]) {
+			p++
+			i++
+			continue
+		}
+
+		// backtrack to the last *
+		if starP == -1 || starI >= len(s) {
+			return false
+		}
+		starI++
+		p, i = starP+1, starI
+	}
+}
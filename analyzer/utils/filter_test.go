@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: filter_test.go
+// Brief: Test for filter.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-19
+//
+// License: BSD-3-Clause
+
+package utils
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	var tests = []struct {
+		name     string
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{"Substring", "go/pkg/mod/", "/home/u/go/pkg/mod/github.com/a/b.go", true},
+		{"No match", "go/pkg/mod/", "/home/u/project/main.go", false},
+		{"Star", "github.com/*/internal/", "/go/pkg/mod/github.com/a/b/internal/c.go", true},
+		{"Star no match", "github.com/*/internal/", "/go/pkg/mod/github.com/a/b/c.go", false},
+		{"Question mark", "file?.go", "/project/file1.go", true},
+		{"Question mark no match", "file?.go", "/project/file.go", false},
+		{"Trailing star", "project/*", "/home/project/main.go", true},
+		{"Empty pattern", "", "/project/main.go", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := MatchGlob(test.pattern, test.s)
+			if res != test.expected {
+				t.Errorf("Incorrect result for MatchGlob(%s, %s). Expected %t. Got %t",
+					test.pattern, test.s, test.expected, res)
+			}
+		})
+	}
+}
+
+func TestIsFiltered(t *testing.T) {
+	var tests = []struct {
+		name     string
+		file     string
+		include  []string
+		exclude  []string
+		expected bool
+	}{
+		{"No filter", "/project/main.go", []string{}, []string{}, false},
+		{"Included", "/project/main.go", []string{"project/"}, []string{}, false},
+		{"Not included", "/other/main.go", []string{"project/"}, []string{}, true},
+		{"Excluded", "/go/pkg/mod/a/b.go", []string{}, []string{"go/pkg/mod/"}, true},
+		{"Included and excluded", "/project/vendor/a.go", []string{"project/"}, []string{"vendor/"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := IsFiltered(test.file, test.include, test.exclude)
+			if res != test.expected {
+				t.Errorf("Incorrect result for IsFiltered(%s, %v, %v). Expected %t. Got %t",
+					test.file, test.include, test.exclude, test.expected, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyrigth (c) 2024 Erik Kassubek
+//
+// File: parallel.go
+// Brief: Run independent jobs on a worker pool
+//
+// Author: Erik Kassubek
+// Created: 2024-11-23
+//
+// License: BSD-3-Clause
+
+package utils
+
+import (
+	"runtime"
+	"sync"
+)
+
+/*
+ * Run f for all indices 0 <= i < n on a pool of workers. The call returns
+ * after all jobs are finished.
+ * Args:
+ *   workers (int): number of workers, 0 for the number of cpus, 1 to run sequentially
+ *   n (int): number of jobs
+ *   f (func(i int)): function to run for each job
+ */
+func RunParallel(workers int, n int, f func(i int)) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				f(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
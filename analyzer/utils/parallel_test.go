@@ -0,0 +1,44 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: parallel_test.go
+// Brief: Test for parallel.go
+//
+// Author: Erik Kassubek
+// Created: 2024-11-23
+//
+// License: BSD-3-Clause
+
+package utils
+
+import (
+	"testing"
+)
+
+func TestRunParallel(t *testing.T) {
+	var tests = []struct {
+		name    string
+		workers int
+		n       int
+	}{
+		{"Sequential", 1, 10},
+		{"Parallel", 4, 10},
+		{"Number of cpus", 0, 10},
+		{"More workers than jobs", 8, 3},
+		{"No jobs", 4, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := make([]int, test.n)
+			RunParallel(test.workers, test.n, func(i int) {
+				res[i] = i + 1
+			})
+
+			for i, r := range res {
+				if r != i+1 {
+					t.Errorf("Job %d was not run", i)
+				}
+			}
+		})
+	}
+}
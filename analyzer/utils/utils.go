@@ -11,8 +11,11 @@
 package utils
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -42,6 +45,23 @@ func ContainsInt(slice []int, elem int) bool {
 	return false
 }
 
+/*
+ * Get the keys of a map in ascending order. Used to iterate over maps in a
+ * deterministic order
+ * Args:
+ *   m (map[K]V): the map
+ * Returns:
+ *   []K: the sorted keys
+ */
+func SortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 /*
  * Split the string into two parts at the last occurrence of the separator
  * Args:
@@ -83,3 +103,69 @@ func GetErrorDiff(expected error, given error) error {
 
 	return nil
 }
+
+/*
+ * Escape a field of a trace or result line, so that it can contain the
+ * separators of the line. The characters %, ,, ; and the line break are
+ * replaced by %25, %2C, %3B and %0A. The runtime uses the same encoding.
+ * Args:
+ *   field (string): the field
+ * Returns:
+ *   string: the escaped field
+ */
+func EscapeField(field string) string {
+	if !strings.ContainsAny(field, "%,;\n") {
+		return field
+	}
+
+	var res strings.Builder
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '%':
+			res.WriteString("%25")
+		case ',':
+			res.WriteString("%2C")
+		case ';':
+			res.WriteString("%3B")
+		case '\n':
+			res.WriteString("%0A")
+		default:
+			res.WriteByte(field[i])
+		}
+	}
+	return res.String()
+}
+
+/*
+ * Unescape a field escaped with EscapeField
+ * Args:
+ *   field (string): the escaped field
+ * Returns:
+ *   string: the original field
+ *   error: if the field contains an invalid escape sequence
+ */
+func UnescapeField(field string) (string, error) {
+	if !strings.Contains(field, "%") {
+		return field, nil
+	}
+
+	var res strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '%' {
+			res.WriteByte(field[i])
+			continue
+		}
+
+		if i+2 >= len(field) {
+			return "", fmt.Errorf("Invalid escape sequence in %s", field)
+		}
+
+		val, err := strconv.ParseUint(field[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("Invalid escape sequence in %s", field)
+		}
+		res.WriteByte(byte(val))
+		i += 2
+	}
+	return res.String(), nil
+}
@@ -58,6 +58,26 @@ func TestContainsInt(t *testing.T) {
 	}
 }
 
+func TestSortedKeys(t *testing.T) {
+	var tests = []struct {
+		name     string
+		m        map[int]string
+		expected []int
+	}{
+		{"Empty", map[int]string{}, []int{}},
+		{"Unsorted", map[int]string{3: "c", 1: "a", 2: "b"}, []int{1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := SortedKeys(test.m)
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("Incorrect result. Expected %v. Got %v", test.expected, res)
+			}
+		})
+	}
+}
+
 func TestSplitAtLast(t *testing.T) {
 	var tests = []struct {
 		name     string
@@ -83,3 +103,54 @@ func TestSplitAtLast(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapeField(t *testing.T) {
+	var tests = []struct {
+		name     string
+		field    string
+		expected string
+	}{
+		{"Without separators", "*main.Request", "*main.Request"},
+		{"Comma", "func(int, int)", "func(int%2C int)"},
+		{"Semicolon", "a;b", "a%3Bb"},
+		{"Percent", "100%,", "100%25%2C"},
+		{"Line break", "a\nb", "a%0Ab"},
+		{"Empty", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := EscapeField(test.field)
+			if res != test.expected {
+				t.Errorf("Incorrect result for EscapeField(%s). Expected %s. Got %s", test.field, test.expected, res)
+			}
+
+			back, err := UnescapeField(res)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err.Error())
+			}
+			if back != test.field {
+				t.Errorf("Incorrect round trip for %s. Got %s", test.field, back)
+			}
+		})
+	}
+}
+
+func TestUnescapeFieldInvalid(t *testing.T) {
+	var tests = []struct {
+		name  string
+		field string
+	}{
+		{"Too short", "a%2"},
+		{"No hex", "a%zz"},
+		{"Percent at end", "a%"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := UnescapeField(test.field); err == nil {
+				t.Errorf("Expected error for %s", test.field)
+			}
+		})
+	}
+}
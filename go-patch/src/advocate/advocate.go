@@ -71,6 +71,16 @@ func FinishReplay() {
 	runtime.ExitReplayWithCode(runtime.ExitCodeDefault)
 }
 
+/*
+ * Get the exit code of the replay, e.g. to check in a reproducer test
+ * if the replay confirmed the bug. Must be called after FinishReplay.
+ * Returns:
+ * 	The exit code, -1 if the replay did not return an exit code
+ */
+func ReplayExitCode() int {
+	return runtime.GetReplayExitCode()
+}
+
 /*
  * Write the trace to a set of files. The traces are written into a folder
  * with name trace. For each routine, a file is created. The file is named
@@ -96,8 +106,20 @@ func writeToTraceFiles(tracePath string) {
  * 	- routine: The id of the routine
  */
 func writeToTraceFile(routine int, wg *sync.WaitGroup, tracePath string) {
-	// create the file if it does not exist and open it
 	defer wg.Done()
+	writeTraceFile(routine, tracePath, runtime.TraceToStringByIDChannel)
+}
+
+/*
+ * Write the trace of a routine, given by a function of the runtime, into
+ * the file trace_routineId.log.
+ * Args:
+ * 	- routine: The id of the routine
+ * 	- tracePath: The trace folder
+ * 	- toString: function that sends the trace of the routine to the channel
+ */
+func writeTraceFile(routine int, tracePath string, toString func(int, chan<- string)) {
+	// create the file if it does not exist and open it
 
 	// if runtime.TraceIsEmptyByRoutine(routine) {
 	// 	return
@@ -114,7 +136,7 @@ func writeToTraceFile(routine int, wg *sync.WaitGroup, tracePath string) {
 	// get the runtime to send the trace
 	advocateChan := make(chan string)
 	go func() {
-		runtime.TraceToStringByIDChannel(routine, advocateChan)
+		toString(routine, advocateChan)
 		close(advocateChan)
 	}()
 
@@ -189,13 +211,60 @@ func InitTracing() {
 		os.Exit(1)
 	}()
 
+	handleSnapshotSignal()
+
+	readRecordingConfig()
+
 	// go writeTraceIfFull()
 	// go removeAtomicsIfFull()
 	runtime.InitAdvocate()
 }
 
+/*
+ * Read the configuration of the recording from the environment.
+ * 	- ADVOCATE_STACK_DEPTH: if set to n > 1, the n innermost user frames of
+ * 		the call stack are recorded for each operation
+ * 	- ADVOCATE_INCLUDE: comma separated list of patterns. If set, only
+ * 		operations in files matching one of the patterns are recorded
+ * 	- ADVOCATE_EXCLUDE: comma separated list of patterns. Operations in files
+ * 		matching one of the patterns are not recorded
+ * In the patterns, '*' matches any sequence of characters and '?' a single
+ * character. A pattern matches a file if it matches any part of its path.
+ */
+func readRecordingConfig() {
+	if depth := os.Getenv("ADVOCATE_STACK_DEPTH"); depth != "" {
+		depthInt, err := strconv.Atoi(depth)
+		if err != nil {
+			println("Invalid value for ADVOCATE_STACK_DEPTH: " + depth)
+		} else {
+			runtime.SetAdvocateStackDepth(depthInt)
+		}
+	}
+
+	runtime.SetAdvocateFilter(splitPatterns(os.Getenv("ADVOCATE_INCLUDE")),
+		splitPatterns(os.Getenv("ADVOCATE_EXCLUDE")))
+}
+
+/*
+ * Split a comma separated list of patterns
+ * Args:
+ * 	- patterns: the list
+ * Returns:
+ * 	The patterns without empty entries
+ */
+func splitPatterns(patterns string) []string {
+	res := make([]string, 0)
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern != "" {
+			res = append(res, pattern)
+		}
+	}
+	return res
+}
+
 var timeout = false
-var tracePathRewritten = "rewritten_trace_"
+var tracePathRewritten = ""
 
 /*
  * Read the trace from the trace folder.
@@ -211,14 +280,32 @@ func InitReplay(index string, exitCode bool, timeout int, atomic bool) {
 	// use first as default
 
 	log.Printf("Init Replay for index %s", index)
-	runtime.SetExitCode(exitCode)
-	runtime.SetReplayAtomic(atomic) // set to true to include replay atomic
 
 	if index == "0" {
-		tracePathRewritten = "advocateTrace"
+		InitReplayFolder("advocateTrace", exitCode, timeout, atomic)
 	} else {
-		tracePathRewritten = tracePathRewritten + index
+		InitReplayFolder("rewritten_trace_"+index, exitCode, timeout, atomic)
 	}
+}
+
+/*
+ * Read the trace from a given trace folder, e.g. for a reproducer test
+ * with the trace in its testdata folder.
+ * Args:
+ * 	- path: The path to the trace folder
+ * 	- exitCode: Whether the program should exit after the important replay part passed
+ * 	- timeout: Timeout in seconds, 0: no timeout
+ *  - atomic: if true, replay includes atomic
+ */
+func InitReplayFolder(path string, exitCode bool, timeout int, atomic bool) {
+	// operations that have not been recorded must also be ignored in the replay
+	readRecordingConfig()
+
+	runtime.ResetReplay()
+	runtime.SetExitCode(exitCode)
+	runtime.SetReplayAtomic(atomic) // set to true to include replay atomic
+
+	tracePathRewritten = path
 
 	// if trace folder does not exist, panic
 	if _, err := os.Stat(tracePathRewritten); os.IsNotExist(err) {
@@ -299,6 +386,10 @@ func InitReplayTracing(index string, exitCode bool, timeout int, atomic bool) {
 		os.Exit(1)
 	}()
 
+	handleSnapshotSignal()
+
+	readRecordingConfig()
+
 	// go writeTraceIfFull()
 	// go removeAtomicsIfFull()
 	runtime.InitAdvocate()
@@ -552,7 +643,7 @@ func readTraceFile(fileName string, chanWithoutPartner *map[string]int) (int, ru
 				file = pos[0]
 				line, _ = strconv.Atoi(pos[1])
 			}
-		case "E":
+		case "E", "I", "H":
 			continue
 
 		default:
@@ -0,0 +1,67 @@
+package advocate
+
+import (
+	"runtime"
+)
+
+/*
+ * HappensBefore annotates a release on addr. All operations of the routine
+ * before the call happen before all operations after a later call of
+ * HappensAfter with the same addr. This can be used to make synchronization
+ * that is not visible to the analysis, e.g. lock-free queues or handoffs
+ * over sync/atomic, known to the analyzer, similar to the annotations of the
+ * thread sanitizer. The annotation should be called directly before the
+ * operation that publishes the data.
+ * Args:
+ * 	- addr: the address the synchronization is over, e.g. the slot of a queue
+ */
+func HappensBefore[T any](addr *T) {
+	runtime.AdvocateSyncEdgeAnnotation(addr, "R", 2)
+}
+
+/*
+ * HappensAfter annotates an acquire on addr. All operations of the routine
+ * after the call happen after the operations before all previous calls of
+ * HappensBefore with the same addr. The annotation should be called directly
+ * after the operation that received the data.
+ * Args:
+ * 	- addr: the address the synchronization is over, e.g. the slot of a queue
+ */
+func HappensAfter[T any](addr *T) {
+	runtime.AdvocateSyncEdgeAnnotation(addr, "A", 2)
+}
+
+// operations of a custom mutex for AnnotateMutex
+type MutexAnnotation int
+
+const (
+	MutexLock MutexAnnotation = iota
+	MutexUnlock
+	MutexRLock
+	MutexRUnlock
+)
+
+/*
+ * AnnotateMutex annotates an operation on a custom (rw) mutex, e.g. a spin
+ * lock. A lock happens after all previous unlocks and runlocks on the same
+ * mutex, a rlock only after all previous unlocks. Lock and RLock must be
+ * annotated after the mutex was acquired, Unlock and RUnlock before the mutex
+ * is released.
+ * The annotations only create the happens before relation. Annotated mutexes
+ * are not used for the deadlock and leak detection.
+ * Args:
+ * 	- addr: the address of the mutex
+ * 	- op: the operation
+ */
+func AnnotateMutex[T any](addr *T, op MutexAnnotation) {
+	switch op {
+	case MutexLock:
+		runtime.AdvocateSyncEdgeAnnotation(addr, "A", 2)
+	case MutexUnlock:
+		runtime.AdvocateSyncEdgeAnnotation(addr, "R", 2)
+	case MutexRLock:
+		runtime.AdvocateSyncEdgeAnnotation(addr, "RA", 2)
+	case MutexRUnlock:
+		runtime.AdvocateSyncEdgeAnnotation(addr, "RR", 2)
+	}
+}
@@ -0,0 +1,199 @@
+package advocate
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prefix of the trace folders of the recording regions
+const tracePathRegionPrefix = "advocateTraceRegion_"
+
+// name of the file with the information about a region in its trace folder
+const regionInfoFile = "region_info.txt"
+
+var regionInit sync.Once
+var regionLock sync.Mutex
+var regionName = ""        // name of the current region, empty if no region is running
+var regionPath = ""        // trace folder of the current region
+var regionRunning []uint64 // routines that were running at the start of the region
+
+/*
+ * StartRegion starts the recording of a region, e.g. around a single request
+ * of a server. Only the operations between StartRegion and EndRegion are
+ * written into the trace. A program can record multiple regions one after
+ * another, each of them is written into its own trace folder
+ * advocateTraceRegion_<name>_<n>, where n counts the regions of the process.
+ * InitTracing must not be used together with the regions.
+ * Routines that were started before the region and execute operations in the
+ * region are contained in the trace without the operation that spawned them.
+ * Args:
+ * 	- name: the name of the region
+ */
+func StartRegion(name string) {
+	regionInit.Do(initRegions)
+
+	regionLock.Lock()
+	defer regionLock.Unlock()
+
+	if regionName != "" {
+		println("Cannot start region " + name + " while region " + regionName + " is running.")
+		return
+	}
+
+	traceFileCounter++
+	regionName = name
+	regionPath = tracePathRegionPrefix + pathName(name) + "_" + strconv.Itoa(traceFileCounter)
+	regionRunning = runtime.StartAdvocateRegion()
+}
+
+/*
+ * EndRegion stops the recording of the region started with StartRegion and
+ * writes its trace. Operations that are still running at the end of the
+ * region are written as not finished (tpost = 0).
+ * Args:
+ * 	- name: the name of the region
+ * Returns:
+ * 	The path of the trace folder, empty if the region is not running
+ */
+func EndRegion(name string) string {
+	regionLock.Lock()
+	running := regionName == name
+	path := regionPath
+	regionLock.Unlock()
+
+	if !running {
+		println("Cannot end region " + name + ", it is not running.")
+		return ""
+	}
+
+	// give the routines of the region time to finish their operations. The
+	// lock is not held, so that a panic or signal can still write the region
+	time.Sleep(100 * time.Millisecond)
+
+	regionLock.Lock()
+	defer regionLock.Unlock()
+
+	// the region may have been written in the meantime, e.g. by finishRegion
+	if regionPath != path || regionName == "" {
+		return ""
+	}
+
+	runtime.DisableTrace()
+
+	writeRegion()
+	regionName = ""
+	regionPath = ""
+	regionRunning = nil
+	return path
+}
+
+/*
+ * Write the trace of the current region. The trace contains a file for each
+ * routine with operations in the region and for each routine that was
+ * running at the start of the region. The routines that were running at the
+ * start are listed in the region_info.txt file.
+ * Must be called with regionLock held.
+ */
+func writeRegion() {
+	// remove the trace folder if it exists
+	err := os.RemoveAll(regionPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+
+	// create the trace folder
+	err = os.Mkdir(regionPath, 0755)
+	if err != nil {
+		if !os.IsExist(err) {
+			panic(err)
+		}
+	}
+
+	routines := make(map[uint64]struct{})
+	for _, routine := range runtime.GetRoutinesInRegion() {
+		routines[routine] = struct{}{}
+	}
+	for _, routine := range regionRunning {
+		routines[routine] = struct{}{}
+	}
+
+	var wg sync.WaitGroup
+	for routine := range routines {
+		wg.Add(1)
+		go writeToTraceFile(int(routine), &wg, regionPath)
+	}
+	wg.Wait()
+
+	running := make([]string, 0, len(regionRunning))
+	sort.Slice(regionRunning, func(i, j int) bool { return regionRunning[i] < regionRunning[j] })
+	for _, routine := range regionRunning {
+		running = append(running, strconv.FormatUint(routine, 10))
+	}
+
+	info := "region: " + regionName + "\n" +
+		"number: " + strconv.Itoa(traceFileCounter) + "\n" +
+		"pre-existing routines: " + strings.Join(running, ",") + "\n"
+	err = os.WriteFile(filepath.Join(regionPath, regionInfoFile), []byte(info), 0644)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Initialize the recording of regions. Is only run once per process.
+ */
+func initRegions() {
+	// if the program panics, but is not in the main routine, no trace is written
+	// to prevent this, the following is done. The corresponding send/recv are in the panic definition
+	blocked := make(chan struct{})
+	writingDone := make(chan struct{})
+	runtime.GetAdvocatePanicChannels(blocked, writingDone)
+	go func() {
+		<-blocked
+		finishRegion()
+		writingDone <- struct{}{}
+	}()
+
+	// if the program is terminated by the user, the running region is not
+	// ended. Therefore capture the signal and write the trace.
+	interuptSignal := make(chan os.Signal, 1)
+	signal.Notify(interuptSignal, os.Interrupt)
+	go func() {
+		<-interuptSignal
+		println("\nCancel Run. Write trace. Cancel again to force exit.")
+		go func() {
+			<-interuptSignal
+			os.Exit(1)
+		}()
+		finishRegion()
+		os.Exit(1)
+	}()
+
+	readRecordingConfig()
+}
+
+/*
+ * Write the trace of the running region, if a region is running, e.g. if the
+ * program panics or is canceled
+ */
+func finishRegion() {
+	regionLock.Lock()
+	defer regionLock.Unlock()
+
+	if regionName == "" {
+		return
+	}
+
+	runtime.DisableTrace()
+	writeRegion()
+	regionName = ""
+}
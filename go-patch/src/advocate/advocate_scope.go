@@ -0,0 +1,196 @@
+package advocate
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// prefix of the trace folders of the recording scopes
+const tracePathScopePrefix = "advocateTrace_"
+
+var scopeInit sync.Once
+var scopesLock sync.Mutex
+var activeScopes = make(map[string]bool)
+
+/*
+ * InitTracingScope starts the recording of a scope, e.g. a test.
+ * The calling routine and all routines it spawns afterwards belong to the
+ * scope. This makes it possible to record tests that run in parallel in the
+ * same process, e.g. with t.Parallel(). Each of them must call
+ * InitTracingScope with its own name, e.g.
+ *
+ * 	advocate.InitTracingScope(t.Name())
+ * 	defer advocate.FinishTracingScope(t.Name())
+ *
+ * The trace of the scope is written into the folder advocateTrace_<name>
+ * by FinishTracingScope.
+ * Args:
+ * 	- name: the name of the scope, e.g. the name of the test
+ */
+func InitTracingScope(name string) {
+	scopeInit.Do(initTracingScopes)
+
+	scopesLock.Lock()
+	activeScopes[name] = true
+	scopesLock.Unlock()
+
+	runtime.SetAdvocateScope(name)
+}
+
+/*
+ * FinishTracingScope writes the trace of a scope started with
+ * InitTracingScope into the folder advocateTrace_<name>. The recording of
+ * other scopes that are still running is not stopped.
+ * Args:
+ * 	- name: the name of the scope
+ */
+func FinishTracingScope(name string) {
+	scopesLock.Lock()
+	if !activeScopes[name] {
+		scopesLock.Unlock()
+		return
+	}
+	delete(activeScopes, name)
+	scopesLock.Unlock()
+
+	runtime.AdvocatRoutineExit()
+
+	time.Sleep(100 * time.Millisecond)
+
+	writeScope(name)
+}
+
+/*
+ * Write the traces of all scopes that are still running, e.g. if the program
+ * panics or is canceled
+ */
+func finishAllScopes() {
+	scopesLock.Lock()
+	names := make([]string, 0, len(activeScopes))
+	for name := range activeScopes {
+		names = append(names, name)
+	}
+	activeScopes = make(map[string]bool)
+	scopesLock.Unlock()
+
+	runtime.DisableTrace()
+
+	for _, name := range names {
+		writeScope(name)
+	}
+}
+
+/*
+ * Write the trace of the routines of a scope into its trace folder and
+ * free it in the runtime. The traces are copied and freed first, so that
+ * routines of the scope that are still running do not change them while
+ * they are written.
+ * Args:
+ * 	- name: the name of the scope
+ */
+func writeScope(name string) {
+	routines := runtime.TakeScopeTrace(name)
+	defer runtime.DeleteScopeTrace(name)
+
+	tracePath := TracePathScope(name)
+
+	// remove the trace folder if it exists
+	err := os.RemoveAll(tracePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+
+	// create the trace folder
+	err = os.Mkdir(tracePath, 0755)
+	if err != nil {
+		if !os.IsExist(err) {
+			panic(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, routine := range routines {
+		wg.Add(1)
+		go func(routine int) {
+			defer wg.Done()
+			writeTraceFile(routine, tracePath, func(id int, c chan<- string) {
+				runtime.ScopeTraceToStringByIDChannel(name, id, c)
+			})
+		}(int(routine))
+	}
+	wg.Wait()
+}
+
+/*
+ * TracePathScope returns the path of the trace folder of a scope
+ * Args:
+ * 	- name: the name of the scope, subtests separated by /
+ * Returns:
+ * 	The path of the trace folder, relative to the working directory
+ */
+func TracePathScope(name string) string {
+	return tracePathScopePrefix + pathName(name)
+}
+
+/*
+ * Get a name that can be used in a path
+ * Args:
+ * 	- name: the name, e.g. of a test or region
+ * Returns:
+ * 	The name with / replaced by - and all other special characters by _
+ */
+func pathName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' {
+			return '-'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+/*
+ * Initialize the recording for all scopes. Is only run once per process.
+ */
+func initTracingScopes() {
+	// if the program panics, but is not in the main routine, no trace is written
+	// to prevent this, the following is done. The corresponding send/recv are in the panic definition
+	blocked := make(chan struct{})
+	writingDone := make(chan struct{})
+	runtime.GetAdvocatePanicChannels(blocked, writingDone)
+	go func() {
+		<-blocked
+		finishAllScopes()
+		writingDone <- struct{}{}
+	}()
+
+	// if the program is terminated by the user, the defer in the tests
+	// is not executed. Therefore capture the signal and write the traces.
+	interuptSignal := make(chan os.Signal, 1)
+	signal.Notify(interuptSignal, os.Interrupt)
+	go func() {
+		<-interuptSignal
+		println("\nCancel Run. Write traces. Cancel again to force exit.")
+		go func() {
+			<-interuptSignal
+			os.Exit(1)
+		}()
+		if !runtime.GetAdvocateDisabled() {
+			finishAllScopes()
+		}
+		os.Exit(1)
+	}()
+
+	readRecordingConfig()
+
+	runtime.InitAdvocate()
+}
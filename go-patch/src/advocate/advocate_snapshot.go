@@ -0,0 +1,67 @@
+package advocate
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*
+ * Write a snapshot of the trace whenever the process receives the snapshot
+ * signal (SIGUSR1, not available on windows). In contrast to SIGINT, the
+ * program keeps running, e.g. to analyze a hanging service without stopping
+ * it:
+ *
+ * 	kill -USR1 <pid>
+ */
+func handleSnapshotSignal() {
+	if len(snapshotSignals) == 0 {
+		return
+	}
+
+	snapshotSignal := make(chan os.Signal, 1)
+	signal.Notify(snapshotSignal, snapshotSignals...)
+	go func() {
+		for range snapshotSignal {
+			if runtime.GetAdvocateDisabled() {
+				continue
+			}
+			path := WriteSnapshot()
+			println("Wrote trace snapshot to " + path)
+		}
+	}()
+}
+
+/*
+ * WriteSnapshot writes the trace recorded so far into the folder
+ * <trace folder>_snapshot_<time>, without stopping the recording.
+ * Operations that are still running, e.g. blocked operations, are written
+ * with tpost = 0, so the analyzer can find the cause of a hang without the
+ * program having to exit.
+ * Returns:
+ * 	The path of the snapshot folder
+ */
+func WriteSnapshot() string {
+	path := tracePathRecorded + "_snapshot_" + time.Now().Format("2006-01-02T15-04-05.000")
+
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, routine := range runtime.TakeTraceSnapshot() {
+		wg.Add(1)
+		go func(routine int) {
+			defer wg.Done()
+			writeTraceFile(routine, path, runtime.SnapshotToStringByIDChannel)
+		}(int(routine))
+	}
+	wg.Wait()
+
+	runtime.DeleteTraceSnapshot()
+
+	return path
+}
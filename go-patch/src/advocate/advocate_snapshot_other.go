@@ -0,0 +1,10 @@
+//go:build !unix
+
+package advocate
+
+import (
+	"os"
+)
+
+// signals that trigger a snapshot of the trace, none without SIGUSR1
+var snapshotSignals = []os.Signal{}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package advocate
+
+import (
+	"os"
+	"syscall"
+)
+
+// signals that trigger a snapshot of the trace
+var snapshotSignals = []os.Signal{syscall.SIGUSR1}
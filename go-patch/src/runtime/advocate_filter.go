@@ -0,0 +1,117 @@
+// ADVOCATE-FILE-START
+
+package runtime
+
+// only operations in files matching one of the patterns are recorded,
+// if empty, all files are included
+var advocateInclude []string
+
+// operations in files matching one of the patterns are not recorded
+var advocateExclude []string
+
+/*
+ * SetAdvocateFilter sets the include and exclude patterns for the recording.
+ * A pattern matches a file, if it matches any part of the file path.
+ * '*' matches any sequence of characters and '?' matches a single character.
+ * Operations in files that do not match any include pattern (if include is
+ * not empty) or that match an exclude pattern are not recorded.
+ * Args:
+ * 	include: include patterns
+ * 	exclude: exclude patterns
+ */
+func SetAdvocateFilter(include []string, exclude []string) {
+	advocateInclude = include
+	advocateExclude = exclude
+}
+
+/*
+ * Check if a file is filtered out by the include and exclude patterns
+ * Args:
+ * 	file: file of the operation
+ * Return:
+ * 	true if the operation should not be recorded, false otherwise
+ */
+func advocateFiltered(file string) bool {
+	if len(advocateInclude) > 0 && !advocateMatchAny(file, advocateInclude) {
+		return true
+	}
+
+	return advocateMatchAny(file, advocateExclude)
+}
+
+/*
+ * Check if a file matches any of the patterns
+ * Args:
+ * 	file: the file path
+ * 	patterns: the patterns
+ * Return:
+ * 	true if at least one pattern matches, false otherwise
+ */
+func advocateMatchAny(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if advocateMatchGlob(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if a pattern matches any part of s.
+ * '*' matches any sequence of characters, '?' matches a single character.
+ * Args:
+ * 	pattern: the pattern
+ * 	s: the string
+ * Return:
+ * 	true if the pattern matches, false otherwise
+ */
+func advocateMatchGlob(pattern string, s string) bool {
+	// the pattern can match any part of s, it is therefore
+	// surrounded by implicit *
+	for start := 0; start <= len(s); start++ {
+		if advocateMatchPrefix(pattern, s[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if a pattern matches a prefix of s
+ * Args:
+ * 	pattern: the pattern
+ * 	s: the string
+ * Return:
+ * 	true if the pattern matches a prefix of s, false otherwise
+ */
+func advocateMatchPrefix(pattern string, s string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for {
+		if p == len(pattern) {
+			return true
+		}
+
+		if pattern[p] == '*' {
+			starP, starI = p, i
+			p++
+			continue
+		}
+
+		if i < len(s) && (pattern[p] == '?' || pattern[p] == s[i]) {
+			p++
+			i++
+			continue
+		}
+
+		// backtrack to the last *
+		if starP == -1 || starI >= len(s) {
+			return false
+		}
+		starI++
+		p, i = starP+1, starI
+	}
+}
+
+// ADVOCATE-FILE-END
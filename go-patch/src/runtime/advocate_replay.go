@@ -32,6 +32,7 @@ var ExitCodeNames = map[int]string{
 }
 
 var hasReturnedExitCode = false
+var returnedExitCode = -1
 var ignoreAtomicsReplay = true
 
 var printDebug = false
@@ -159,6 +160,26 @@ var expectedExitCode int
 var lastTPreReplay int
 var stuckReplayExecutedSuc = false
 
+/*
+ * Reset the state of a previous replay in the same program, e.g. of another
+ * reproducer test in the same test binary, so that its exit code is not
+ * returned for the new replay. Must be called before the traces of the new
+ * replay are added.
+ */
+func ResetReplay() {
+	hasReturnedExitCode = false
+	returnedExitCode = -1
+	stuckReplayExecutedSuc = false
+
+	replayData = make(AdvocateReplayTraces, 0)
+	numberElementsInTrace = 0
+	traceElementPositions = make(map[string][]int)
+
+	lock(&replayDoneLock)
+	replayDone = 0
+	unlock(&replayDoneLock)
+}
+
 /*
  * Add a replay trace to the replay data.
  * Arguments:
@@ -584,6 +605,7 @@ func ExitReplayWithCode(code int) {
 		}
 		println("Exit Replay with code ", code, ExitCodeNames[code])
 		hasReturnedExitCode = true
+		returnedExitCode = code
 	}
 	if replayExitCode && ExitCodeNames[code] != "" {
 		if !advocateTracingDisabled { // do not exit if recording is enabled
@@ -593,6 +615,15 @@ func ExitReplayWithCode(code int) {
 	}
 }
 
+/*
+ * Get the first exit code of the replay
+ * Returns:
+ * 	the exit code, -1 if the replay has not returned an exit code yet
+ */
+func GetReplayExitCode() int {
+	return returnedExitCode
+}
+
 func isExitCodeLeak(code int) bool {
 	return code >= 20 && code < 30
 }
@@ -14,6 +14,19 @@ var atomicRecordingDisabled = false
  * id: the id of the routine
  * G: the g struct of the routine
  * Trace: the trace of the routine
+ * Stacks: the call stacks of the elements, only if the stack depth is > 1
+ * scope: the recording scope of the routine, e.g. the test it belongs to,
+ *   inherited by the routines it spawns, empty if the routine has no scope
+ * regionStart: index of the first element of the current recording region.
+ *   The elements before it have been freed, so the element with index i is
+ *   Trace[i-regionStart]. 0 if no region was started
+ * traceLock: protects Trace, Atomics, Stacks, regionStart, freePending and
+ *   exited. Only the routine itself changes its trace, other routines must
+ *   hold the lock to read it. The routine itself can read its trace without
+ *   the lock.
+ * freePending: another routine requested to free the trace, e.g. at the start
+ *   of a region. The routine frees its trace the next time it adds an element
+ * exited: the routine has finished, so its trace can be freed by others
  */
 type AdvocateRoutine struct {
 	id          uint64
@@ -21,7 +34,12 @@ type AdvocateRoutine struct {
 	G           *g
 	Trace       []string
 	Atomics     []string
-	// lock    *mutex
+	Stacks      map[int][]uintptr // index of the element -> call stack
+	scope       string
+	regionStart int
+	traceLock   mutex
+	freePending bool
+	exited      bool
 }
 
 /*
@@ -74,8 +92,108 @@ func (gi *AdvocateRoutine) addToTrace(elem string) int {
 		return -1
 	}
 
+	lock(&gi.traceLock)
+	if gi.freePending {
+		gi.freeTrace()
+	}
 	gi.Trace = append(gi.Trace, elem)
-	return len(gi.Trace) - 1
+	index := gi.regionStart + len(gi.Trace) - 1
+	unlock(&gi.traceLock)
+
+	return index
+}
+
+/*
+ * Free all elements recorded so far. The indices of the elements recorded
+ * afterwards continue after the freed elements, so that operations that are
+ * still running can check with isFreed that their element does not exist
+ * any more.
+ * Must be called with gi.traceLock held, by the routine itself or, if the
+ * routine has exited, by any routine.
+ */
+func (gi *AdvocateRoutine) freeTrace() {
+	gi.regionStart += len(gi.Trace)
+	gi.Trace = nil
+	gi.Atomics = nil
+	gi.Stacks = nil
+	gi.freePending = false
+}
+
+/*
+ * Request to free all elements recorded so far, e.g. at the start of a
+ * recording region. The trace of a routine that has exited is freed directly.
+ * A running routine frees its trace itself the next time it adds an element,
+ * so that the operations it is running never access a freed element. Until
+ * then, the trace is treated as empty by the other routines.
+ */
+func (gi *AdvocateRoutine) requestFree() {
+	lock(&gi.traceLock)
+	if gi.exited {
+		gi.freeTrace()
+	} else {
+		gi.freePending = true
+	}
+	unlock(&gi.traceLock)
+}
+
+/*
+ * Check if an element has been freed by freeTrace. Must only be called by
+ * the routine itself.
+ * Params:
+ * 	index: the index of the element
+ * Return:
+ * 	true if the element has been freed
+ */
+func (gi *AdvocateRoutine) isFreed(index int) bool {
+	return gi != nil && index < gi.regionStart
+}
+
+/*
+ * Mark the routine as exited, so that its trace can be freed by other
+ * routines
+ */
+func (gi *AdvocateRoutine) setExited() {
+	if gi == nil {
+		return
+	}
+	lock(&gi.traceLock)
+	gi.exited = true
+	unlock(&gi.traceLock)
+}
+
+/*
+ * Copy the trace of the routine. Elements of a requested free are not
+ * copied. Must be called with gi.traceLock held or while the world is stopped.
+ * Return:
+ * 	the copy
+ */
+func (gi *AdvocateRoutine) copyTrace() *AdvocateRoutine {
+	if gi.freePending {
+		return &AdvocateRoutine{id: gi.id, regionStart: gi.regionStart + len(gi.Trace)}
+	}
+
+	cp := &AdvocateRoutine{id: gi.id, regionStart: gi.regionStart,
+		Trace: make([]string, len(gi.Trace)), Atomics: make([]string, len(gi.Atomics))}
+	copy(cp.Trace, gi.Trace)
+	copy(cp.Atomics, gi.Atomics)
+	if gi.Stacks != nil {
+		cp.Stacks = make(map[int][]uintptr, len(gi.Stacks))
+		for index, stack := range gi.Stacks {
+			cp.Stacks[index] = stack
+		}
+	}
+	return cp
+}
+
+/*
+ * Copy the trace of the routine, while it may still be running
+ * Return:
+ * 	the copy
+ */
+func (gi *AdvocateRoutine) lockedCopyTrace() *AdvocateRoutine {
+	lock(&gi.traceLock)
+	defer unlock(&gi.traceLock)
+	return gi.copyTrace()
 }
 
 /*
@@ -87,8 +205,10 @@ func IgnoreAtomicOperations() {
 	lock(&AdvocateRoutinesLock)
 	for _, routine := range AdvocateRoutines {
 		println("Delete ", len(routine.Atomics), " atomic operations")
+		lock(&routine.traceLock)
 		sum += len(routine.Atomics)
 		routine.Atomics = nil
+		unlock(&routine.traceLock)
 	}
 	unlock(&AdvocateRoutinesLock)
 	println("Deleted ", sum, " atomic operations")
@@ -125,11 +245,24 @@ func (gi *AdvocateRoutine) addAtomicToTrace(elem string) {
 		return
 	}
 
+	lock(&gi.traceLock)
+	if gi.freePending {
+		gi.freeTrace()
+	}
 	gi.Atomics = append(gi.Atomics, elem)
+	unlock(&gi.traceLock)
 }
 
+/*
+ * Get an element of the trace of the current routine. Must only be called by
+ * the routine itself or on a copy of the trace.
+ * Params:
+ * 	index: the index of the element
+ * Return:
+ * 	the element
+ */
 func (gi *AdvocateRoutine) getElement(index int) string {
-	return gi.Trace[index]
+	return gi.Trace[index-gi.regionStart]
 }
 
 /*
@@ -147,15 +280,17 @@ func (gi *AdvocateRoutine) updateElement(index int, elem string) {
 		return
 	}
 
-	if gi.Trace == nil {
-		panic("Tried to update element in nil trace")
+	if gi.isFreed(index) {
+		return
 	}
 
-	if index >= len(gi.Trace) {
+	if index-gi.regionStart >= len(gi.Trace) {
 		panic("Tried to update element out of bounds")
 	}
 
-	gi.Trace[index] = elem
+	lock(&gi.traceLock)
+	gi.Trace[index-gi.regionStart] = elem
+	unlock(&gi.traceLock)
 }
 
 /*
@@ -179,6 +314,100 @@ func GetRoutineID() uint64 {
 	return currentGoRoutine().id
 }
 
+/*
+ * SetAdvocateScope sets the recording scope of the current routine. All
+ * routines spawned by it afterwards inherit the scope.
+ * Params:
+ * 	scope: the name of the scope, e.g. the name of a test
+ */
+func SetAdvocateScope(scope string) {
+	routine := currentGoRoutine()
+	if routine == nil {
+		getg().goInfo = newAdvocateRoutine(getg())
+		routine = currentGoRoutine()
+	}
+	routine.scope = scope
+}
+
+// copies of the traces of the scopes that are written, created by TakeScopeTrace
+var advocateScopeTraces map[string]map[uint64]*AdvocateRoutine
+var advocateScopeTracesLock = mutex{}
+
+/*
+ * TakeScopeTrace copies the traces of all routines in a recording scope and
+ * frees them, e.g. when the scope has finished. The copy and the free of each
+ * trace are done under the lock of the routine, so routines of the scope that
+ * are still running cannot change the trace in between. Elements they record
+ * afterwards are not contained in the copy. The copy can be written with
+ * ScopeTraceToStringByIDChannel and must be deleted with DeleteScopeTrace.
+ * Params:
+ * 	scope: the name of the scope
+ * Return:
+ * 	the ids of the routines in the scope
+ */
+func TakeScopeTrace(scope string) []uint64 {
+	traces := make(map[uint64]*AdvocateRoutine)
+	ids := make([]uint64, 0)
+
+	lock(&AdvocateRoutinesLock)
+	for id, routine := range AdvocateRoutines {
+		if routine.scope != scope {
+			continue
+		}
+
+		lock(&routine.traceLock)
+		traces[id] = routine.copyTrace()
+		if routine.exited {
+			routine.freeTrace()
+		} else {
+			routine.freePending = true
+		}
+		unlock(&routine.traceLock)
+
+		ids = append(ids, id)
+	}
+	unlock(&AdvocateRoutinesLock)
+
+	lock(&advocateScopeTracesLock)
+	if advocateScopeTraces == nil {
+		advocateScopeTraces = make(map[string]map[uint64]*AdvocateRoutine)
+	}
+	advocateScopeTraces[scope] = traces
+	unlock(&advocateScopeTracesLock)
+
+	return ids
+}
+
+/*
+ * ScopeTraceToStringByIDChannel sends the trace of a routine in the copy
+ * taken with TakeScopeTrace to the channel 'c' in chunks of 1000 elements.
+ * Params:
+ * 	scope: the name of the scope
+ * 	id: id of the routine
+ * 	c: channel to send the trace to
+ */
+func ScopeTraceToStringByIDChannel(scope string, id int, c chan<- string) {
+	lock(&advocateScopeTracesLock)
+	routine, ok := advocateScopeTraces[scope][uint64(id)]
+	unlock(&advocateScopeTracesLock)
+
+	if ok {
+		routine.traceToStringChannel(c)
+	}
+}
+
+/*
+ * DeleteScopeTrace deletes the copy of the trace of a scope taken with
+ * TakeScopeTrace
+ * Params:
+ * 	scope: the name of the scope
+ */
+func DeleteScopeTrace(scope string) {
+	lock(&advocateScopeTracesLock)
+	delete(advocateScopeTraces, scope)
+	unlock(&advocateScopeTracesLock)
+}
+
 /*
  * DisableAtomicRecording disables the recording of atomic operations
  */
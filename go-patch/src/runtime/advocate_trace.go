@@ -150,8 +150,16 @@ func getTpre(elem string) int {
  * 	index of the element in the trace
  */
 func insertIntoTrace(elem string) int {
+	routine := currentGoRoutine()
+	index := routine.addToTrace(elem)
 
-	return currentGoRoutine().addToTrace(elem)
+	// the end of a routine and sync edges have no position
+	if elem[0] != 'E' && elem[0] != 'H' {
+		// skip insertIntoTrace
+		routine.addStack(index, 2)
+	}
+
+	return index
 }
 
 /*
@@ -174,7 +182,8 @@ func TraceToStringByID(id uint64) (string, bool) {
 	lock(&AdvocateRoutinesLock)
 	defer unlock(&AdvocateRoutinesLock)
 	if routine, ok := AdvocateRoutines[id]; ok {
-		return traceToString(&routine.Trace, &routine.Atomics), true
+		cp := routine.lockedCopyTrace()
+		return traceToString(&cp.Trace, &cp.Atomics), true
 	}
 	return "", false
 }
@@ -190,7 +199,9 @@ func TraceIsEmptyByRoutine(routine int) bool {
 	lock(&AdvocateRoutinesLock)
 	defer unlock(&AdvocateRoutinesLock)
 	if routine, ok := AdvocateRoutines[uint64(routine)]; ok {
-		return len(routine.Trace) == 0
+		lock(&routine.traceLock)
+		defer unlock(&routine.traceLock)
+		return routine.freePending || len(routine.Trace) == 0
 	}
 	return true
 }
@@ -209,26 +220,96 @@ func TraceToStringByIDChannel(id int, c chan<- string) {
 
 	if routine, ok := AdvocateRoutines[uint64(id)]; ok {
 		unlock(&AdvocateRoutinesLock)
-		res := ""
-
-		// if atomic recording is disabled
-		for i, elem := range routine.Trace {
-			if i != 0 {
-				res += "\n"
-			}
-			res += elem
-
-			if i%1000 == 0 {
-				c <- res
-				res = ""
-			}
-		}
-		c <- res
+		// the routine may still be running, so a copy of its trace is sent
+		routine.lockedCopyTrace().traceToStringChannel(c)
 	} else {
 		unlock(&AdvocateRoutinesLock)
 	}
 }
 
+/*
+ * Send the trace of a routine to the channel 'c' in chunks of 1000 elements.
+ * The elements before the current region have been freed, so only the
+ * elements of the region are sent. Must only be called on a copy of the
+ * trace, created with copyTrace.
+ * Args:
+ * 	c: channel to send the trace to
+ */
+func (routine *AdvocateRoutine) traceToStringChannel(c chan<- string) {
+	res := ""
+
+	for i := 0; i < len(routine.Trace); i++ {
+		if i != 0 {
+			res += "\n"
+		}
+		res += routine.getElementWithStack(routine.regionStart + i)
+
+		if i%1000 == 0 {
+			c <- res
+			res = ""
+		}
+	}
+	c <- res
+}
+
+// copy of the traces of all routines, created by TakeTraceSnapshot
+var advocateSnapshot map[uint64]*AdvocateRoutine
+var advocateSnapshotLock = mutex{}
+
+/*
+ * TakeTraceSnapshot copies the trace recorded so far, while the recording
+ * continues. Operations that are still running, e.g. blocked operations, are
+ * contained with tpost = 0. The world is stopped while the traces are copied,
+ * so that no routine changes its trace during the copy. The copy can be
+ * written with SnapshotToStringByIDChannel.
+ * Return:
+ * 	the ids of the routines in the snapshot
+ */
+func TakeTraceSnapshot() []uint64 {
+	snapshot := make(map[uint64]*AdvocateRoutine)
+	ids := make([]uint64, 0)
+
+	// While the world is stopped, no other routine can change
+	// AdvocateRoutines, so no lock is needed.
+	stw := stopTheWorld(stwAdvocateSnapshot)
+	for id, routine := range AdvocateRoutines {
+		snapshot[id] = routine.copyTrace()
+		ids = append(ids, id)
+	}
+	startTheWorld(stw)
+
+	lock(&advocateSnapshotLock)
+	advocateSnapshot = snapshot
+	unlock(&advocateSnapshotLock)
+	return ids
+}
+
+/*
+ * Get the trace of the routine with id 'id' in the last snapshot taken
+ * with TakeTraceSnapshot, in chunks of 1000 elements.
+ * Args:
+ * 	id: id of the routine
+ * 	c: channel to send the trace to
+ */
+func SnapshotToStringByIDChannel(id int, c chan<- string) {
+	lock(&advocateSnapshotLock)
+	routine, ok := advocateSnapshot[uint64(id)]
+	unlock(&advocateSnapshotLock)
+
+	if ok {
+		routine.traceToStringChannel(c)
+	}
+}
+
+/*
+ * DeleteTraceSnapshot deletes the last snapshot taken with TakeTraceSnapshot
+ */
+func DeleteTraceSnapshot() {
+	lock(&advocateSnapshotLock)
+	advocateSnapshot = nil
+	unlock(&advocateSnapshotLock)
+}
+
 /*
  * Return the trace of all traces
  * Return:
@@ -246,7 +327,8 @@ func AllTracesToString() string {
 		if routine == nil {
 			panic("Trace is nil")
 		}
-		res += traceToString(&routine.Trace, &routine.Atomics) + "\n"
+		cp := routine.lockedCopyTrace()
+		res += traceToString(&cp.Trace, &cp.Atomics) + "\n"
 
 	}
 	return res
@@ -280,6 +362,55 @@ func InitAdvocate() {
 	advocateTracingDisabled = false
 }
 
+/*
+ * StartAdvocateRegion starts a new recording region. Only the elements
+ * recorded after the start are written into the trace files. The elements
+ * before the start are freed. The routines free their own trace the next
+ * time they add an element, so operations that are still running never
+ * access a freed element.
+ * Return:
+ * 	the ids of the routines that are running at the start of the region
+ */
+func StartAdvocateRegion() []uint64 {
+	lock(&AdvocateRoutinesLock)
+	defer unlock(&AdvocateRoutinesLock)
+
+	running := make([]uint64, 0)
+	for id, routine := range AdvocateRoutines {
+		routine.requestFree()
+
+		// the g of a finished routine may be reused by a new routine
+		if routine.G != nil && routine.G.goInfo == routine && readgstatus(routine.G) != _Gdead {
+			running = append(running, id)
+		}
+	}
+
+	advocateTracingDisabled = false
+
+	return running
+}
+
+/*
+ * GetRoutinesInRegion returns the ids of all routines with elements in the
+ * current recording region
+ * Return:
+ * 	the ids of the routines
+ */
+func GetRoutinesInRegion() []uint64 {
+	lock(&AdvocateRoutinesLock)
+	defer unlock(&AdvocateRoutinesLock)
+
+	res := make([]uint64, 0)
+	for id, routine := range AdvocateRoutines {
+		lock(&routine.traceLock)
+		if !routine.freePending && len(routine.Trace) > 0 {
+			res = append(res, id)
+		}
+		unlock(&routine.traceLock)
+	}
+	return res
+}
+
 /*
  * DisableTrace disables the collection of the trace
  */
@@ -321,7 +452,7 @@ func DeleteTrace() {
 	lock(&AdvocateRoutinesLock)
 	defer unlock(&AdvocateRoutinesLock)
 	for i := range AdvocateRoutines {
-		AdvocateRoutines[i].Trace = AdvocateRoutines[i].Trace[:0]
+		AdvocateRoutines[i].requestFree()
 	}
 }
 
@@ -330,16 +461,19 @@ func DeleteTrace() {
 /*
  * Some operations, like garbage collection and internal operations, can
  * cause the replay to get stuck or are not needed.
- * For this reason, we ignore them.
+ * For this reason, we ignore them. Additionally, operations that are
+ * filtered out by the include and exclude patterns are ignored.
  * Arguments:
- * 	operation: operation that is about to be executed
  * 	file: file in which the operation is executed
- * 	line: line number of the operation
  * Return:
  * 	bool: true if the operation should be ignored, false otherwise
  */
 func AdvocateIgnore(file string) bool {
-	return contains(file, "go-patch/src/")
+	if contains(file, "go-patch/src/") {
+		return true
+	}
+
+	return advocateFiltered(file)
 }
 
 // ADVOCATE-FILE-END
@@ -27,6 +27,10 @@ func AdvocateChanSendPre(id uint64, opID uint64, qSize uint, isNil bool) int {
 	_, file, line, _ := Caller(3)
 
 	if AdvocateIgnore(file) {
+		// a send releases, but does not acquire
+		if !isNil && advocateSummarize(file) {
+			advocateSyncEdgeRelease(id, timer)
+		}
 		return -1
 	}
 
@@ -72,6 +76,10 @@ func AdvocateChanRecvPre(id uint64, opID uint64, qSize uint, isNil bool) int {
 
 	_, file, line, _ := Caller(3)
 	if AdvocateIgnore(file) {
+		// a receive acquires, but does not release
+		if !isNil && advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(id, timer)
+		}
 		return -1
 	}
 
@@ -96,10 +104,14 @@ func AdvocateChanRecvPre(id uint64, opID uint64, qSize uint, isNil bool) int {
  * 	index of the operation in the trace
  */
 func AdvocateChanClose(id uint64, qSize uint) int {
-	timer := uint64ToString(GetNextTimeStep())
+	timerInt := GetNextTimeStep()
+	timer := uint64ToString(timerInt)
 
 	_, file, line, _ := Caller(2)
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			advocateSyncEdgeRelease(id, timerInt)
+		}
 		return -1
 	}
 
@@ -119,7 +131,12 @@ func AdvocateChanClose(id uint64, qSize uint) int {
 func AdvocateChanPost(index int) {
 	time := GetNextTimeStep()
 
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
+		return
+	}
+
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, time, 0, false)
 		return
 	}
 
@@ -177,7 +194,12 @@ func AdvocateChanPost(index int) {
 func AdvocateChanPostCausedByClose(index int) {
 	time := GetNextTimeStep()
 
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
+		return
+	}
+
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, time, 0, false)
 		return
 	}
 
@@ -14,6 +14,12 @@ func AdvocateCondPre(id uint64, op int) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			if op == 0 { // wait
+				return advocateSyncEdgeAcquire(id, timer)
+			}
+			advocateSyncEdgeRelease(id, timer) // signal, broadcast
+		}
 		return -1
 	}
 
@@ -42,9 +48,15 @@ func AdvocateCondPre(id uint64, op int) int {
  */
 func AdvocateCondPost(index int) {
 	timer := GetNextTimeStep()
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
 		return
 	}
+
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, timer, 0, false)
+		return
+	}
+
 	elem := currentGoRoutine().getElement(index)
 
 	split := splitStringAtCommas(elem, []int{2, 3})
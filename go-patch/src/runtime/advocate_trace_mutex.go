@@ -38,6 +38,9 @@ func AdvocateMutexLockPre(id uint64, rw bool, r bool) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(id, timer)
+		}
 		return -1
 	}
 
@@ -80,6 +83,9 @@ func AdvocateMutexLockTry(id uint64, rw bool, r bool) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(id, timer)
+		}
 		return -1
 	}
 
@@ -122,6 +128,9 @@ func AdvocateUnlockPre(id uint64, rw bool, r bool) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			advocateSyncEdgeRelease(id, timer)
+		}
 		return -1
 	}
 
@@ -145,7 +154,7 @@ func AdvocateMutexPost(index int) {
 	timer := GetNextTimeStep()
 
 	// internal elements are not in the trace
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
 		return
 	}
 
@@ -155,6 +164,11 @@ func AdvocateMutexPost(index int) {
 		return
 	}
 
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, timer, 0, false)
+		return
+	}
+
 	elem := currentGoRoutine().getElement(index)
 	split := splitStringAtCommas(elem, []int{2, 3, 4, 5, 6, 7})
 	routine := currentGoRoutine().id
@@ -188,7 +202,15 @@ func AdvocatePostTry(index int, suc bool) {
 	timer := GetNextTimeStep()
 
 	// internal elements are not in the trace
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
+		return
+	}
+
+	// a failed try lock does not acquire the lock
+	if advocateIsSyncEdge(index) {
+		if suc {
+			advocateSyncEdgePost(index, timer, 0, false)
+		}
 		return
 	}
 
@@ -0,0 +1,145 @@
+// ADVOCATE-FILE-START
+
+package runtime
+
+/*
+ * AdvocateChanMake adds the creation of a channel to the trace
+ * MARK: Channel
+ * Args:
+ * 	id: id of the new channel
+ * 	elemType: string representation of the element type of the channel
+ * 	qSize: capacity of the channel, 0 for unbuffered
+ */
+func AdvocateChanMake(id uint64, elemType string, qSize uint) {
+	if advocateTracingDisabled {
+		return
+	}
+
+	// makechan can be called via makechan64 or reflect.MakeChan
+	file, line := advocateCreationCaller(2, "go-patch/src/runtime/chan.go", "go-patch/src/reflect/")
+
+	if file == "" || AdvocateIgnore(file) {
+		return
+	}
+
+	advocateNew(id, "C", elemType, qSize, file, line)
+}
+
+/*
+ * GetAdvocateObjectIDNew returns a new id for a mutex, rwmutex, waitgroup,
+ * once or cond and adds the creation of the object to the trace. Sync objects
+ * do not have a constructor. The creation is therefore recorded at the
+ * first use of the object.
+ * MARK: Sync
+ * Args:
+ * 	objType: type of the object (M: mutex, R: rwmutex, W: waitgroup,
+ * 		O: once, N: cond)
+ * Return:
+ * 	new id
+ */
+func GetAdvocateObjectIDNew(objType string) uint64 {
+	id := GetAdvocateObjectID()
+
+	if advocateTracingDisabled {
+		return id
+	}
+
+	// e.g. the first use of a wait group can be a Done, that calls Add
+	file, line := advocateCreationCaller(2, "go-patch/src/sync/")
+
+	if file == "" || AdvocateIgnore(file) {
+		return id
+	}
+
+	var elemType string
+	switch objType {
+	case "M":
+		elemType = "sync.Mutex"
+	case "R":
+		elemType = "sync.RWMutex"
+	case "W":
+		elemType = "sync.WaitGroup"
+	case "O":
+		elemType = "sync.Once"
+	case "N":
+		elemType = "sync.Cond"
+	default:
+		panic("Unknown object type")
+	}
+
+	advocateNew(id, objType, elemType, 0, file, line)
+
+	return id
+}
+
+/*
+ * Add the creation of an object to the trace
+ * Args:
+ * 	id: id of the new object
+ * 	objType: type of the object
+ * 	elemType: element type of the object
+ * 	qSize: size of the channel, 0 for all other objects
+ * 	file: file of the creation
+ * 	line: line of the creation
+ */
+func advocateNew(id uint64, objType string, elemType string, qSize uint, file string, line int) {
+	timer := GetNextTimeStep()
+
+	// the type can contain commas (e.g. func(int, int)) or semicolons,
+	// which would break the trace format. They are escaped like in an url,
+	// the analyzer reverts this.
+	typeClean := ""
+	for i := 0; i < len(elemType); i++ {
+		switch elemType[i] {
+		case '%':
+			typeClean += "%25"
+		case ',':
+			typeClean += "%2C"
+		case ';':
+			typeClean += "%3B"
+		default:
+			typeClean += string(elemType[i])
+		}
+	}
+
+	elem := "I," + uint64ToString(timer) + "," + uint64ToString(id) + "," +
+		objType + "," + typeClean + "," + uint32ToString(uint32(qSize)) + "," +
+		file + ":" + intToString(line)
+
+	insertIntoTrace(elem)
+}
+
+/*
+ * Get the position of the creation of an object. Frames in one of the
+ * internal files are skipped.
+ * Args:
+ * 	skip: number of frames to skip
+ * 	internal: frames in files containing one of the strings are skipped
+ * Return:
+ * 	file of the creation
+ * 	line of the creation
+ */
+func advocateCreationCaller(skip int, internal ...string) (string, int) {
+	for {
+		// +1 for advocateCreationCaller
+		_, file, line, ok := Caller(skip + 1)
+		if !ok {
+			return "", 0
+		}
+
+		isInternal := false
+		for _, in := range internal {
+			if contains(file, in) {
+				isInternal = true
+				break
+			}
+		}
+
+		if !isInternal {
+			return file, line
+		}
+		skip++
+	}
+}
+
+// ADVOCATE-FILE-END
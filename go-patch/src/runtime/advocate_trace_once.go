@@ -13,6 +13,9 @@ func AdvocateOncePre(id uint64) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(id, timer)
+		}
 		return -1
 	}
 
@@ -31,9 +34,16 @@ func AdvocateOncePre(id uint64) int {
 func AdvocateOncePost(index int, suc bool) {
 	timer := GetNextTimeStep()
 
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
 		return
 	}
+
+	// the do that executed the function releases, all others acquire
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, timer, 0, suc)
+		return
+	}
+
 	elem := currentGoRoutine().getElement(index)
 
 	split := splitStringAtCommas(elem, []int{2, 3, 4, 5})
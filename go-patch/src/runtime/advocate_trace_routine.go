@@ -24,3 +24,11 @@ func AdvocatRoutineExit() {
 	elem := "E," + uint64ToString(timer)
 	insertIntoTrace(elem)
 }
+
+/*
+ * Mark the current routine as finished, so that other routines can free its
+ * trace. Called when the routine exits, after AdvocatRoutineExit.
+ */
+func advocateRoutineFinished() {
+	currentGoRoutine().setExited()
+}
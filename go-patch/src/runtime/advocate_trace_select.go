@@ -28,6 +28,10 @@ func AdvocateSelectPre(cases *[]scase, nsends int, ncases int, block bool, locko
 
 	_, file, line, _ := Caller(2)
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			// the chosen case is not known yet, it is set in AdvocateSelectPost
+			return advocateSyncEdgeAcquire(0, timer)
+		}
 		return -1
 	}
 
@@ -106,13 +110,27 @@ func AdvocateSelectPre(cases *[]scase, nsends int, ncases int, block bool, locko
  * 	index: index of the operation in the trace
  * 	c: channel of the chosen case
  * 	chosenIndex: index of the chosen case in the select
+ * 	send: true if the chosen case is a send
  * 	lockOrder: order of the locks
  * 	rClosed: true if the channel was closed at another routine
  */
-func AdvocateSelectPost(index int, c *hchan, chosenIndex int, lockOrder []uint16, rClosed bool) {
+func AdvocateSelectPost(index int, c *hchan, chosenIndex int, send bool, lockOrder []uint16, rClosed bool) {
 	timer := GetNextTimeStep()
 
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
+		return
+	}
+
+	// only the chosen case releases (send) or acquires (receive),
+	// nothing is synchronized by the default case
+	if advocateIsSyncEdge(index) {
+		if chosenIndex == -1 || c == nil {
+			return
+		} else if send {
+			advocateSyncEdgeToRelease(index, c.id)
+		} else {
+			advocateSyncEdgePost(index, timer, c.id, false)
+		}
 		return
 	}
 
@@ -191,6 +209,10 @@ func AdvocateSelectPreOneNonDef(c *hchan, send bool) int {
 
 	_, file, line, _ := Caller(2)
 	if AdvocateIgnore(file) {
+		// the case is set in AdvocateSelectPostOneNonDef
+		if c != nil && advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(c.id, timer)
+		}
 		return -1
 	}
 
@@ -206,11 +228,24 @@ func AdvocateSelectPreOneNonDef(c *hchan, send bool) int {
  * Args:
  * 	index: index of the operation in the trace
  * 	res: true for channel, false for default
+ * 	c: channel of the non-default case
+ * 	send: true if the non-default case is a send, false otherwise
  */
-func AdvocateSelectPostOneNonDef(index int, res bool, c *hchan) {
+func AdvocateSelectPostOneNonDef(index int, res bool, c *hchan, send bool) {
 	timer := GetNextTimeStep()
 
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
+		return
+	}
+
+	// only the channel case releases (send) or acquires (receive),
+	// nothing is synchronized by the default case
+	if advocateIsSyncEdge(index) {
+		if res && send {
+			advocateSyncEdgeToRelease(index, 0)
+		} else if res {
+			advocateSyncEdgePost(index, timer, 0, false)
+		}
 		return
 	}
 
@@ -0,0 +1,120 @@
+// ADVOCATE-FILE-START
+
+package runtime
+
+// number of innermost user frames recorded for each operation
+// if it is 1, only the position of the operation is recorded
+var advocateStackDepth = 1
+
+// maximum number of internal frames (runtime, sync, ...) that can be between
+// the recording function and the user code
+const advocateStackInternalFrames = 16
+
+/*
+ * SetAdvocateStackDepth sets the number of innermost user frames that are
+ * recorded for each operation. If depth <= 1, only the position of the
+ * operation itself is recorded (default).
+ * Args:
+ * 	depth: number of frames
+ */
+func SetAdvocateStackDepth(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+	advocateStackDepth = depth
+}
+
+/*
+ * GetAdvocateStackDepth returns the number of recorded frames per operation
+ * Return:
+ * 	number of frames
+ */
+func GetAdvocateStackDepth() int {
+	return advocateStackDepth
+}
+
+/*
+ * Record the call stack of the element with the given index. Only the
+ * program counters are stored. They are symbolized when the trace is written.
+ * Args:
+ * 	gi: routine of the element
+ * 	index: index of the element, as returned by addToTrace
+ * 	skip: number of frames to skip
+ */
+func (gi *AdvocateRoutine) addStack(index int, skip int) {
+	if advocateStackDepth <= 1 || gi == nil || index < 0 {
+		return
+	}
+
+	pcs := make([]uintptr, advocateStackDepth+advocateStackInternalFrames)
+	// +1 for addStack
+	n := callers(skip+1, pcs)
+	if n == 0 {
+		return
+	}
+
+	lock(&gi.traceLock)
+	if gi.Stacks == nil {
+		gi.Stacks = make(map[int][]uintptr)
+	}
+	gi.Stacks[index] = pcs[:n]
+	unlock(&gi.traceLock)
+}
+
+/*
+ * Get the element with the given index including its call stack.
+ * The frames are added to the position of the element, separated by ;
+ * Internal frames and the position of the element itself are skipped.
+ * Args:
+ * 	gi: routine of the element
+ * 	index: index of the element, as returned by addToTrace
+ * Return:
+ * 	the element with the stack
+ */
+func (gi *AdvocateRoutine) getElementWithStack(index int) string {
+	elem := gi.getElement(index)
+
+	if gi.Stacks == nil {
+		return elem
+	}
+
+	pcs, ok := gi.Stacks[index]
+	if !ok {
+		return elem
+	}
+
+	// the position is the last field of the element
+	pos := ""
+	for i := len(elem) - 1; i >= 0; i-- {
+		if elem[i] == ',' {
+			pos = elem[i+1:]
+			break
+		}
+	}
+
+	frames := CallersFrames(pcs)
+	added := 0
+	first := true
+	for added < advocateStackDepth-1 {
+		frame, more := frames.Next()
+
+		if frame.File != "" && !AdvocateIgnore(frame.File) {
+			framePos := frame.File + ":" + intToString(frame.Line)
+
+			// the innermost user frame is the position of the element
+			if !(first && framePos == pos) {
+				elem += ";" + framePos
+				added++
+			}
+			first = false
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return elem
+}
+
+// ADVOCATE-FILE-END
@@ -0,0 +1,161 @@
+// ADVOCATE-FILE-START
+
+package runtime
+
+/*
+ * Operations in files that are filtered out by the include and exclude
+ * patterns (ADVOCATE_INCLUDE, ADVOCATE_EXCLUDE) are not recorded. To keep
+ * their happens-before effect between the routines, they are replaced by
+ * sync edges:
+ * 	H,[tPre],[tPost],[id],[op],[pos]
+ * where op is R for a release and A for an acquire on the object with the
+ * given id. The position is empty for summarized operations.
+ * Operations that release something (e.g. unlock, close, send) add a release
+ * when they start, operations that acquire something (e.g. lock, recv, wait)
+ * add an acquire when they finish. For a select, only the chosen case
+ * releases (send) or acquires (receive).
+ * Happens before annotations of the user create the same elements with the
+ * position of the annotation. Annotated rw mutexes additionally use RR for
+ * a read release and RA for a read acquire.
+ */
+
+/*
+ * Check if an ignored operation should be summarized as a sync edge.
+ * This is the case if the operation is filtered out by the include and
+ * exclude patterns. Internal operations are never summarized.
+ * Args:
+ * 	file: file of the operation
+ * Return:
+ * 	true if the operation should be replaced by sync edges
+ */
+func advocateSummarize(file string) bool {
+	if advocateTracingDisabled || file == "" || contains(file, "go-patch/src/") {
+		return false
+	}
+
+	return advocateFiltered(file)
+}
+
+/*
+ * Add a release sync edge to the trace
+ * MARK: Release
+ * Args:
+ * 	id: id of the object
+ * 	timer: time of the operation
+ */
+func advocateSyncEdgeRelease(id uint64, timer uint64) {
+	t := uint64ToString(timer)
+	elem := "H," + t + "," + t + "," + uint64ToString(id) + ",R,"
+	insertIntoTrace(elem)
+}
+
+/*
+ * Add an acquire sync edge to the trace. The tPost is set by
+ * advocateSyncEdgePost when the operation has finished.
+ * MARK: Acquire
+ * Args:
+ * 	id: id of the object, 0 if not known yet (select)
+ * 	timer: time of the start of the operation
+ * Return:
+ * 	index of the edge in the trace
+ */
+func advocateSyncEdgeAcquire(id uint64, timer uint64) int {
+	elem := "H," + uint64ToString(timer) + ",0," + uint64ToString(id) + ",A,"
+	return insertIntoTrace(elem)
+}
+
+/*
+ * Check if the element with the given index is a sync edge
+ * Args:
+ * 	index: index of the element in the trace
+ * Return:
+ * 	true if the element is a sync edge
+ */
+func advocateIsSyncEdge(index int) bool {
+	routine := currentGoRoutine()
+	if index < 0 || routine == nil || routine.isFreed(index) || index-routine.regionStart >= len(routine.Trace) {
+		return false
+	}
+
+	return routine.getElement(index)[0] == 'H'
+}
+
+/*
+ * Set the tPost of an acquire sync edge, when the operation has finished
+ * MARK: Post
+ * Args:
+ * 	index: index of the edge in the trace
+ * 	timer: time of the end of the operation
+ * 	id: id of the object, if 0 the id is not changed
+ * 	release: if true, the edge is changed into a release (once)
+ */
+func advocateSyncEdgePost(index int, timer uint64, id uint64, release bool) {
+	elem := currentGoRoutine().getElement(index)
+
+	// split into H,[tPre] - [tPost] - [id] - [op] - [pos]
+	split := splitStringAtCommas(elem, []int{2, 3, 4, 5})
+	split[1] = uint64ToString(timer)
+	if id != 0 {
+		split[2] = uint64ToString(id)
+	}
+	if release {
+		split[3] = "R"
+	}
+
+	currentGoRoutine().updateElement(index, mergeString(split))
+}
+
+/*
+ * Change a sync edge added by advocateSyncEdgeAcquire into a release at the
+ * start of the operation, e.g. if the chosen case of a select is a send.
+ * MARK: ToRelease
+ * Args:
+ * 	index: index of the edge in the trace
+ * 	id: id of the object, if 0 the id is not changed
+ */
+func advocateSyncEdgeToRelease(index int, id uint64) {
+	elem := currentGoRoutine().getElement(index)
+
+	// split into H,[tPre] - [tPost] - [id] - [op] - [pos]
+	split := splitStringAtCommas(elem, []int{2, 3, 4, 5})
+	split[1] = split[0][2:]
+	if id != 0 {
+		split[2] = uint64ToString(id)
+	}
+	split[3] = "R"
+
+	currentGoRoutine().updateElement(index, mergeString(split))
+}
+
+/*
+ * Add a sync edge for a happens before annotation of the user
+ * (advocate.HappensBefore, advocate.HappensAfter, advocate.AnnotateMutex).
+ * The id of the edge is created from the annotated address. If the
+ * annotation is in a filtered file, the edge is kept, but without position.
+ * MARK: Annotation
+ * Args:
+ * 	addr: annotated address
+ * 	op: R (release), A (acquire), RR (read release) or RA (read acquire)
+ * 	skip: number of frames to skip to get the position of the annotation
+ */
+func AdvocateSyncEdgeAnnotation[T any](addr *T, op string, skip int) {
+	timer := GetNextTimeStep()
+
+	_, file, line, _ := Caller(skip)
+
+	pos := ""
+	if AdvocateIgnore(file) {
+		if !advocateSummarize(file) {
+			return
+		}
+	} else {
+		pos = file + ":" + intToString(line)
+	}
+
+	t := uint64ToString(timer)
+	elem := "H," + t + "," + t + "," + pointerAddressAsString(addr, true) + "," +
+		op + "," + pos
+	insertIntoTrace(elem)
+}
+
+// ADVOCATE-FILE-END
@@ -22,6 +22,9 @@ func AdvocateWaitGroupAdd(id uint64, delta int, val int32) int {
 	}
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			advocateSyncEdgeRelease(id, timer)
+		}
 		return -1
 	}
 
@@ -48,6 +51,9 @@ func AdvocateWaitGroupWaitPre(id uint64) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
+		if advocateSummarize(file) {
+			return advocateSyncEdgeAcquire(id, timer)
+		}
 		return -1
 	}
 
@@ -67,7 +73,7 @@ func AdvocateWaitGroupPost(index int) {
 	timer := GetNextTimeStep()
 
 	// internal elements are not in the trace
-	if index == -1 {
+	if index == -1 || currentGoRoutine().isFreed(index) {
 		return
 	}
 
@@ -77,6 +83,11 @@ func AdvocateWaitGroupPost(index int) {
 		return
 	}
 
+	if advocateIsSyncEdge(index) {
+		advocateSyncEdgePost(index, timer, 0, false)
+		return
+	}
+
 	elem := currentGoRoutine().getElement(index)
 	split := splitStringAtCommas(elem, []int{2, 3})
 	split[1] = uint64ToString(timer)
@@ -136,6 +136,7 @@ func makechan(t *chantype, size int) *hchan {
 	c.advocateIgnore = advocateIgnored
 	if !c.advocateIgnore {
 		c.id = GetAdvocateObjectID()
+		AdvocateChanMake(c.id, toRType(elem).string(), c.dataqsiz)
 	}
 	// ADVOCATE-CHANGE-END
 
@@ -974,7 +975,7 @@ func selectnbsend(c *hchan, elem unsafe.Pointer) (selected bool) {
 	}
 	if c != nil && !c.advocateIgnore {
 		CheckLastTPreReplay(replayElem.TimePre)
-		AdvocateSelectPostOneNonDef(advocateIndex, res, c)
+		AdvocateSelectPostOneNonDef(advocateIndex, res, c, true)
 	}
 
 	return res
@@ -1026,7 +1027,7 @@ func selectnbrecv(elem unsafe.Pointer, c *hchan) (selected, received bool) {
 	}
 	if c != nil && !c.advocateIgnore {
 		CheckLastTPreReplay(replayElem.TimePre)
-		AdvocateSelectPostOneNonDef(advocateIndex, res, c)
+		AdvocateSelectPostOneNonDef(advocateIndex, res, c, false)
 	}
 	return res, recv
 
@@ -1274,6 +1274,9 @@ const (
 	stwForTestReadMemStatsSlow                      // "ReadMemStatsSlow (test)"
 	stwForTestPageCachePagesLeaked                  // "PageCachePagesLeaked (test)"
 	stwForTestResetDebugLog                         // "ResetDebugLog (test)"
+	// ADVOCATE-CHANGE-START
+	stwAdvocateSnapshot // "advocate trace snapshot"
+	// ADVOCATE-CHANGE-END
 )
 
 func (r stwReason) String() string {
@@ -1305,6 +1308,9 @@ var stwReasonStrings = [...]string{
 	stwForTestReadMemStatsSlow:     "ReadMemStatsSlow (test)",
 	stwForTestPageCachePagesLeaked: "PageCachePagesLeaked (test)",
 	stwForTestResetDebugLog:        "ResetDebugLog (test)",
+	// ADVOCATE-CHANGE-START
+	stwAdvocateSnapshot: "advocate trace snapshot",
+	// ADVOCATE-CHANGE-END
 }
 
 // worldStop provides context from the stop-the-world required by the
@@ -4169,6 +4175,7 @@ func goexit1() {
 	}
 	// ADVOCATE-CHANGE-START
 	AdvocatRoutineExit()
+	advocateRoutineFinished()
 	// ADVOCATE-CHANGE-END
 	trace := traceAcquire()
 	if trace.ok() {
@@ -4897,6 +4904,7 @@ func newproc(fn *funcval) {
 		newg.goInfo = newAdvocateRoutine(newg)
 		if gp != nil && gp.goInfo != nil {
 			AdvocateSpawnCaller(gp.goInfo, newg.goInfo.id, file, line)
+			newg.goInfo.scope = gp.goInfo.scope
 		}
 		// ADVOCATE-CHANGE-END
 
@@ -282,7 +282,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		selunlock(scases, lockorder)
 		casi = -1
 		CheckLastTPreReplay(replayElem.TimePre)
-		AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+		AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 		goto retc
 	}
 	// ADVOCATE-CHANGE-END
@@ -349,7 +349,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 			selunlock(scases, lockorder)
 			casi = -1
 			CheckLastTPreReplay(replayElem.TimePre)
-			AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+			AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 			goto retc
 		}
 	}
@@ -501,7 +501,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 	// ADVOCATE-CHANGE-START
 	advocateRClose = !caseSuccess
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	selunlock(scases, lockorder)
 	goto retc
@@ -533,7 +533,7 @@ bufrecv:
 	c.qcount--
 	// ADVOCATE-CHANGE-START
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	selunlock(scases, lockorder)
 	goto retc
@@ -558,7 +558,7 @@ bufsend:
 	c.qcount++
 	// ADVOCATE-CHANGE-START
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	selunlock(scases, lockorder)
 	goto retc
@@ -572,7 +572,7 @@ recv:
 	recvOK = true
 	// ADVOCATE-CHANGE-START
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	goto retc
 
@@ -580,7 +580,7 @@ rclose:
 	// ADVOCATE-CHANGE-START
 	advocateRClose = true
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	// read at end of closed channel
 	selunlock(scases, lockorder)
@@ -606,7 +606,7 @@ send:
 	}
 	// ADVOCATE-CHANGE-START
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 	send(c, sg, cas.elem, func() { selunlock(scases, lockorder) }, 2)
 	if debugSelect {
@@ -625,14 +625,14 @@ sclose:
 	// ADVOCATE-CHANGE-START
 	advocateRClose = true
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 
 	selunlock(scases, lockorder)
 
 	// ADVOCATE-CHANGE-START
 	CheckLastTPreReplay(replayElem.TimePre)
-	AdvocateSelectPost(advocateIndex, c, casi, lockorder, advocateRClose)
+	AdvocateSelectPost(advocateIndex, c, casi, casi < nsends, lockorder, advocateRClose)
 	// ADVOCATE-CHANGE-END
 
 	panic(plainError("send on closed channel"))
@@ -73,7 +73,7 @@ func NewCond(l Locker) *Cond {
 func (c *Cond) Wait() {
 	// ADVOCATE-CHANGE-START
 	if c.id == 0 {
-		c.id = runtime.GetAdvocateObjectID()
+		c.id = runtime.GetAdvocateObjectIDNew("N")
 	}
 	// replay
 	wait, ch := runtime.WaitForReplay(runtime.OperationCondWait, 2)
@@ -102,7 +102,7 @@ func (c *Cond) Wait() {
 func (c *Cond) Signal() {
 	// ADVOCATE-CHANGE-START
 	if c.id == 0 {
-		c.id = runtime.GetAdvocateObjectID()
+		c.id = runtime.GetAdvocateObjectIDNew("N")
 	}
 	// replay
 	wait, ch := runtime.WaitForReplay(runtime.OperationCondSignal, 2)
@@ -124,7 +124,7 @@ func (c *Cond) Signal() {
 func (c *Cond) Broadcast() {
 	// ADVOCATE-CHANGE-START
 	if c.id == 0 {
-		c.id = runtime.GetAdvocateObjectID()
+		c.id = runtime.GetAdvocateObjectIDNew("N")
 	}
 	// replay
 	wait, ch := runtime.WaitForReplay(runtime.OperationCondBroadcast, 2)
@@ -91,7 +91,7 @@ func (m *Mutex) Lock() {
 	if wait {
 		replayElem := <-ch
 		if m.id == 0 {
-			m.id = runtime.GetAdvocateObjectID()
+			m.id = runtime.GetAdvocateObjectIDNew("M")
 		}
 		if replayElem.Blocked {
 			_ = runtime.AdvocateMutexLockPre(m.id, false, false)
@@ -104,7 +104,7 @@ func (m *Mutex) Lock() {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id.
 	if m.id == 0 {
-		m.id = runtime.GetAdvocateObjectID()
+		m.id = runtime.GetAdvocateObjectIDNew("M")
 	}
 
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
@@ -141,7 +141,7 @@ func (m *Mutex) TryLock() bool {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if m.id == 0 {
-				m.id = runtime.GetAdvocateObjectID()
+				m.id = runtime.GetAdvocateObjectIDNew("M")
 			}
 			_ = runtime.AdvocateMutexLockTry(m.id, false, false)
 			runtime.BlockForever()
@@ -160,7 +160,7 @@ func (m *Mutex) TryLock() bool {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id
 	if m.id == 0 {
-		m.id = runtime.GetAdvocateObjectID()
+		m.id = runtime.GetAdvocateObjectIDNew("M")
 	}
 
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
@@ -306,7 +306,7 @@ func (m *Mutex) Unlock() {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if m.id == 0 {
-				m.id = runtime.GetAdvocateObjectID()
+				m.id = runtime.GetAdvocateObjectIDNew("M")
 			}
 			_ = runtime.AdvocateUnlockPre(m.id, false, false)
 			runtime.BlockForever()
@@ -73,7 +73,7 @@ func (o *Once) Do(f func()) {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if o.id == 0 {
-				o.id = runtime.GetAdvocateObjectID()
+				o.id = runtime.GetAdvocateObjectIDNew("O")
 			}
 			_ = runtime.AdvocateOncePre(o.id)
 			runtime.BlockForever()
@@ -90,7 +90,7 @@ func (o *Once) Do(f func()) {
 	}
 
 	if o.id == 0 {
-		o.id = runtime.GetAdvocateObjectID()
+		o.id = runtime.GetAdvocateObjectIDNew("O")
 	}
 	index := runtime.AdvocateOncePre(o.id)
 	res := false
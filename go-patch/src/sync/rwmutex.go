@@ -74,7 +74,7 @@ func (rw *RWMutex) RLock() {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if rw.id == 0 {
-				rw.id = runtime.GetAdvocateObjectID()
+				rw.id = runtime.GetAdvocateObjectIDNew("R")
 			}
 			_ = runtime.AdvocateMutexLockPre(rw.id, true, true)
 			runtime.BlockForever()
@@ -86,7 +86,7 @@ func (rw *RWMutex) RLock() {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id
 	if rw.id == 0 {
-		rw.id = runtime.GetAdvocateObjectID()
+		rw.id = runtime.GetAdvocateObjectIDNew("R")
 	}
 
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
@@ -126,7 +126,7 @@ func (rw *RWMutex) TryRLock() bool {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if rw.id == 0 {
-				rw.id = runtime.GetAdvocateObjectID()
+				rw.id = runtime.GetAdvocateObjectIDNew("R")
 			}
 			_ = runtime.AdvocateMutexLockTry(rw.id, true, true)
 			runtime.BlockForever()
@@ -145,7 +145,7 @@ func (rw *RWMutex) TryRLock() bool {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id
 	if rw.id == 0 {
-		rw.id = runtime.GetAdvocateObjectID()
+		rw.id = runtime.GetAdvocateObjectIDNew("R")
 	}
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
 	// advocateIndex is used for AdvocatePostTry to find the pre event.
@@ -243,7 +243,7 @@ func (rw *RWMutex) Lock() {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if rw.id == 0 {
-				rw.id = runtime.GetAdvocateObjectID()
+				rw.id = runtime.GetAdvocateObjectIDNew("R")
 			}
 			_ = runtime.AdvocateMutexLockPre(rw.id, true, false)
 			runtime.BlockForever()
@@ -254,7 +254,7 @@ func (rw *RWMutex) Lock() {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id
 	if rw.id == 0 {
-		rw.id = runtime.GetAdvocateObjectID()
+		rw.id = runtime.GetAdvocateObjectIDNew("R")
 	}
 
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
@@ -299,7 +299,7 @@ func (rw *RWMutex) TryLock() bool {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if rw.id == 0 {
-				rw.id = runtime.GetAdvocateObjectID()
+				rw.id = runtime.GetAdvocateObjectIDNew("R")
 			}
 			// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
 			// advocateIndex is used for AdvocatePostTry to find the pre event.
@@ -317,7 +317,7 @@ func (rw *RWMutex) TryLock() bool {
 	// is directly in the lock function. If the id of the channel is the default
 	// value, it is set to a new, unique object id
 	if rw.id == 0 {
-		rw.id = runtime.GetAdvocateObjectID()
+		rw.id = runtime.GetAdvocateObjectIDNew("R")
 	}
 	// AdvocateMutexLockPre records, that a routine tries to lock a mutex.
 	// advocateIndex is used for AdvocatePostTry to find the pre event.
@@ -77,7 +77,7 @@ func (wg *WaitGroup) Add(delta int) {
 	// is directly in it's functions. If the id of the wg is the default
 	// value, it is set to a new, unique object id
 	if wg.id == 0 {
-		wg.id = runtime.GetAdvocateObjectID()
+		wg.id = runtime.GetAdvocateObjectIDNew("W")
 	}
 	// Record the add or done of a wait group in the routine's trace.
 	// If delta > 0, it is an add, if it's -1, it's a done.
@@ -131,7 +131,7 @@ func (wg *WaitGroup) Wait() {
 		replayElem := <-ch
 		if replayElem.Blocked {
 			if wg.id == 0 {
-				wg.id = runtime.GetAdvocateObjectID()
+				wg.id = runtime.GetAdvocateObjectIDNew("W")
 			}
 			_ = runtime.AdvocateWaitGroupWaitPre(wg.id)
 			runtime.BlockForever()
@@ -149,7 +149,7 @@ func (wg *WaitGroup) Wait() {
 	// is directly in it's functions. If the id of the wg is the default
 	// value, it is set to a new, unique object id
 	if wg.id == 0 {
-		wg.id = runtime.GetAdvocateObjectID()
+		wg.id = runtime.GetAdvocateObjectIDNew("W")
 	}
 
 	// Record the wait of a wait group in the routine's trace.
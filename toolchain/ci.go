@@ -0,0 +1,351 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: ci.go
+// Brief: Write a JUnit report and a summary of all tests and get the exit
+//    code of the toolchain for continuous integration
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"analyzer/results"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// settings of the exit code of the toolchain
+var (
+	failOn    string // comma separated list of bug codes or prefixes, e.g. P01,L, or all
+	failFound bool   // also fail on bugs that were not confirmed by a replay
+)
+
+// names of the reports in the advocateResult folder
+const (
+	junitFile   = "advocate_junit.xml"
+	summaryFile = "advocate_summary.json"
+)
+
+// exit codes of the toolchain
+const (
+	exitCodeWorkflowFailed = 1 // the workflow or the workflow of a test failed
+	exitCodeBugFound       = 3 // a bug matches the fail policy
+)
+
+/*
+ * Record of a bug, as written by the analyzer and updated after the replay,
+ * with the path to its report and if it matches the fail policy
+ */
+type bugRecord struct {
+	results.Record
+	Report string `json:"report"`
+	Fails  bool   `json:"fails"`
+}
+
+/*
+ * Result of the workflow for a test or main program
+ */
+type testResult struct {
+	Name     string  `json:"name"`
+	Package  string  `json:"package"`
+	Folder   string  `json:"folder"`
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error,omitempty"`
+	// number of the analyzed trace -> reason, for analyses that were
+	// stopped before they finished
+	Incomplete map[string]string `json:"incomplete,omitempty"`
+	Bugs       []bugRecord       `json:"bugs"`
+}
+
+// results of all tests of the current run of the toolchain
+var testResults = make([]testResult, 0)
+
+/*
+ * Add the result of a test or main program. The bugs are read from the
+ * result records in its result folder.
+ * Args:
+ *    name (string): name of the test or program
+ *    pkg (string): package of the test, main for main programs
+ *    folder (string): result folder of the test
+ *    duration (time.Duration): duration of the workflow for the test
+ *    err (error): error of the workflow, nil if it succeeded
+ */
+func addTestResult(name, pkg, folder string, duration time.Duration, err error) {
+	res := testResult{
+		Name:     name,
+		Package:  pkg,
+		Folder:   folder,
+		Duration: duration.Seconds(),
+		Bugs:     make([]bugRecord, 0),
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	data, readErr := results.ReadRecordsData(folder)
+	if readErr == nil {
+		res.Incomplete = data.Incomplete
+		for _, record := range data.Records {
+			res.Bugs = append(res.Bugs, bugRecord{Record: record})
+		}
+	} else if !errors.Is(readErr, os.ErrNotExist) {
+		fmt.Printf("Could not read result records of %s: %v\n", name, readErr)
+	}
+
+	for i := range res.Bugs {
+		// the reports of the recorded run are named without the rewrite number
+		res.Bugs[i].Report = filepath.Join("bugs", "bug_"+strings.TrimPrefix(res.Bugs[i].ID, "0_")+".md")
+		res.Bugs[i].Fails = bugFails(res.Bugs[i])
+	}
+
+	testResults = append(testResults, res)
+}
+
+/*
+ * Check if a bug matches the fail policy given with -fail-on and -fail-found
+ * Args:
+ *    bug (bugRecord): the bug
+ * Returns:
+ *    bool: true if the toolchain should fail because of the bug
+ */
+func bugFails(bug bugRecord) bool {
+	if failOn == "" || bug.Rewrite == results.RewriteDouble {
+		return false
+	}
+
+	if !failFound && !bug.IsConfirmed() {
+		return false
+	}
+
+	for _, code := range strings.Split(failOn, ",") {
+		code = strings.TrimSpace(code)
+		if code == "all" || (code != "" && strings.HasPrefix(bug.Type, code)) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Get the exit code of the toolchain based on the fail policy
+ * Returns:
+ *    int: exitCodeBugFound if a bug matches the policy, 0 otherwise
+ */
+func ciExitCode() int {
+	for _, test := range testResults {
+		for _, bug := range test.Bugs {
+			if bug.Fails {
+				return exitCodeBugFound
+			}
+		}
+	}
+	return 0
+}
+
+/*
+ * Check if the workflow failed for a test or main program
+ * Returns:
+ *    bool: true if the workflow returned an error for at least one test
+ */
+func workflowFailed() bool {
+	for _, test := range testResults {
+		if test.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// elements of the JUnit report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     float64         `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Time      float64        `xml:"time,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Error     *junitFailure  `xml:"error"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+/*
+ * Write the JUnit report and the summary of all tests into the result folder,
+ * if they are selected in the output formats.
+ * In the JUnit report, each test is a testcase with one failure per
+ * confirmed bug. Bugs that were not confirmed are listed in the output of
+ * the testcase.
+ * Args:
+ *    resultPath (string): path to the advocateResult folder
+ * Returns:
+ *    error
+ */
+func writeCIReports(resultPath string) error {
+	if outputFormats["junit"] {
+		if err := writeJUnit(filepath.Join(resultPath, junitFile)); err != nil {
+			return err
+		}
+	}
+	if outputFormats["summary"] {
+		return writeSummary(filepath.Join(resultPath, summaryFile))
+	}
+	return nil
+}
+
+/*
+ * Write the JUnit report
+ * Args:
+ *    path (string): path to the file
+ * Returns:
+ *    error
+ */
+func writeJUnit(path string) error {
+	report := junitTestSuites{Name: "ADVOCATE"}
+	suites := make(map[string]int) // package -> index in report.Suites
+
+	for _, test := range testResults {
+		testCase := junitTestCase{Name: test.Name, Classname: test.Package, Time: test.Duration}
+
+		for _, bug := range test.Bugs {
+			text := fmt.Sprintf("%s\nRewrite: %s\nVerdict: %s\nReport: %s",
+				bug.Result, bug.Rewrite, bug.Verdict, filepath.Join(test.Folder, bug.Report))
+			if bug.IsConfirmed() {
+				testCase.Failures = append(testCase.Failures, junitFailure{
+					Message: fmt.Sprintf("Bug %s (%s) confirmed", bug.ID, bug.Type),
+					Type:    bug.Type,
+					Text:    text,
+				})
+			} else {
+				testCase.SystemOut += fmt.Sprintf("Bug %s (%s) not confirmed\n%s\n\n", bug.ID, bug.Type, text)
+			}
+		}
+
+		traceNrs := make([]string, 0, len(test.Incomplete))
+		for traceNr := range test.Incomplete {
+			traceNrs = append(traceNrs, traceNr)
+		}
+		sort.Strings(traceNrs)
+		for _, traceNr := range traceNrs {
+			testCase.SystemOut += fmt.Sprintf("Analysis of trace %s incomplete (%s), only the bugs found until then are reported\n\n",
+				traceNr, test.Incomplete[traceNr])
+		}
+
+		if test.Error != "" {
+			testCase.Error = &junitFailure{Message: test.Error, Type: "error",
+				Text: "Output: " + filepath.Join(test.Folder, "output.log")}
+		}
+
+		index, ok := suites[test.Package]
+		if !ok {
+			index = len(report.Suites)
+			suites[test.Package] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: test.Package})
+		}
+		suite := &report.Suites[index]
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suite.Time += test.Duration
+		report.Tests++
+		report.Time += test.Duration
+		if len(testCase.Failures) > 0 {
+			suite.Failures++
+			report.Failures++
+		}
+		if testCase.Error != nil {
+			suite.Errors++
+			report.Errors++
+		}
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
+
+/*
+ * Write the summary of all tests as json
+ * Args:
+ *    path (string): path to the file
+ * Returns:
+ *    error
+ */
+func writeSummary(path string) error {
+	summary := struct {
+		Tests      int          `json:"tests"`
+		Errors     int          `json:"errors"`
+		Incomplete int          `json:"incomplete"`
+		Bugs       int          `json:"bugs"`
+		Confirmed  int          `json:"confirmed"`
+		Failing    int          `json:"failing"`
+		FailOn     string       `json:"failOn"`
+		FailFound  bool         `json:"failFound"`
+		ExitCode   int          `json:"exitCode"`
+		Results    []testResult `json:"results"`
+	}{
+		Tests:     len(testResults),
+		FailOn:    failOn,
+		FailFound: failFound,
+		ExitCode:  ciExitCode(),
+		Results:   testResults,
+	}
+
+	for _, test := range testResults {
+		if test.Error != "" {
+			summary.Errors++
+		}
+		if len(test.Incomplete) > 0 {
+			summary.Incomplete++
+		}
+		for _, bug := range test.Bugs {
+			summary.Bugs++
+			if bug.IsConfirmed() {
+				summary.Confirmed++
+			}
+			if bug.Fails {
+				summary.Failing++
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
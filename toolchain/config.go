@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: config.go
+// Brief: Read the configuration file advocate.toml and apply it to the flags
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"analyzer/config"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	configFile    string                // path to the configuration file, given with -config
+	skipTests     []string              // tests and subtests that are not run
+	scenarios     = map[string]string{} // package -> analysis scenarios
+	outputFormats = map[string]bool{"markdown": true, "html": true, "junit": true, "summary": true}
+)
+
+/*
+ * Setting of the configuration file that corresponds to a flag
+ */
+type configFlag struct {
+	flag    string // name of the flag
+	section string // section in the configuration file
+	key     string // key in the section
+	path    bool   // the value is a path relative to the configuration file
+}
+
+// settings of the configuration file that correspond to flags of the toolchain
+var configFlags = []configFlag{
+	{"p", "test", "packages", false},
+	{"tags", "test", "tags", false},
+	{"short", "test", "short", false},
+	{"timeout", "test", "timeout", false},
+	{"T", "timeout", "analysis", false},
+	{"R", "timeout", "replay", false},
+	{"r", "analysis", "rerecord", false},
+	{"D", "record", "stackDepth", false},
+	{"I", "record", "include", false},
+	{"X", "record", "exclude", false},
+	{"B", "analysis", "baseline", true},
+	{"fail-on", "fail", "on", false},
+	{"fail-found", "fail", "found", false},
+}
+
+/*
+ * Read the configuration file and set all flags that are not set on the
+ * command line. The path of the file is passed to the analyzer with
+ * ADVOCATE_CONFIG, so that it uses the same configuration.
+ * Args:
+ *    dir (string): the analyzed folder, or a file in it. If no file is
+ *      given with -config, advocate.toml in this folder is used if it exists
+ * Returns:
+ *    error: if the file could not be read or a value is invalid
+ */
+func applyConfig(dir string) error {
+	path := configFile
+	if path == "" {
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		path = filepath.Join(dir, config.FileName)
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	path = cfg.File
+
+	fmt.Printf("Use configuration %s\n", path)
+	os.Setenv("ADVOCATE_CONFIG", path)
+
+	setOnCommandLine := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	for _, cf := range configFlags {
+		str, ok := cfg.FlagValue(cf.section, cf.key)
+		if !ok || setOnCommandLine[cf.flag] {
+			continue
+		}
+
+		if cf.path && str != "" && !filepath.IsAbs(str) {
+			str = filepath.Join(filepath.Dir(path), str)
+		}
+
+		if err := flag.Set(cf.flag, str); err != nil {
+			return fmt.Errorf("%s: invalid value for %s.%s: %v", path, cf.section, cf.key, err)
+		}
+	}
+
+	if skip, ok := cfg.List("test", "skip"); ok {
+		skipTests = skip
+	}
+
+	for _, pkg := range cfg.Keys("scenarios") {
+		scenarios[testPackageName(pkg)], _ = cfg.FlagValue("scenarios", pkg)
+	}
+
+	if formats, ok := cfg.List("output", "formats"); ok {
+		for format := range outputFormats {
+			outputFormats[format] = false
+		}
+		for _, format := range formats {
+			if _, ok := outputFormats[format]; !ok {
+				return fmt.Errorf("%s: unknown output format %s", path, format)
+			}
+			outputFormats[format] = true
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Check if a test is skipped by the configuration
+ * Args:
+ *    testName (string): name of the test, subtests separated by /
+ * Returns:
+ *    bool: true if the test or one of its parents is skipped
+ */
+func isSkipped(testName string) bool {
+	for _, skip := range skipTests {
+		if testName == skip || strings.HasPrefix(testName, skip+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Get the arguments of the analyzer for the scenarios of a package
+ * Args:
+ *    pkg (string): package of the test, relative to the analyzed folder
+ * Returns:
+ *    []string: -s and the scenarios, empty if the configuration does not
+ *      contain scenarios for the package
+ */
+func scenarioArgs(pkg string) []string {
+	if s, ok := scenarios[testPackageName(pkg)]; ok && s != "" {
+		return []string{"-s", s}
+	}
+	return []string{}
+}
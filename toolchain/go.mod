@@ -1,3 +1,7 @@
 module tool
 
 go 1.22.1
+
+require analyzer v0.0.0
+
+replace analyzer => ../analyzer
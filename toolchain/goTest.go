@@ -0,0 +1,383 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: goTest.go
+// Brief: Find packages, tests and subtests and create the go test commands
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// settings of go test, used for all runs of the tests
+var (
+	testPatterns string // comma separated list of package patterns
+	testTags     string // build tags
+	testShort    bool   // run the tests with -short
+	testTimeout  string // timeout of go test, empty for the default
+)
+
+/*
+ * Get the arguments for go test
+ * Args:
+ *    defaultTimeout (string): timeout used if no timeout is given with -timeout
+ *    testName (string): name of the test, subtests separated by /
+ *    pkg (string): package of the test, relative to the current directory
+ * Returns:
+ *    []string: the arguments
+ */
+func goTestArgs(defaultTimeout string, testName string, pkg string) []string {
+	timeout := defaultTimeout
+	if testTimeout != "" {
+		timeout = testTimeout
+	}
+
+	args := []string{"test", "-v", "-count=1", "-timeout", timeout}
+	if testTags != "" {
+		args = append(args, "-tags", testTags)
+	}
+	if testShort {
+		args = append(args, "-short")
+	}
+	return append(args, "-run="+testRunPattern(testName), "./"+strings.TrimPrefix(pkg, "/"))
+}
+
+/*
+ * Get the name of the test function of a test or subtest
+ * Args:
+ *    testName (string): name of the test, subtests separated by /
+ * Returns:
+ *    string: name of the test function
+ */
+func testFuncName(testName string) string {
+	return strings.Split(testName, "/")[0]
+}
+
+/*
+ * Get the -run pattern that only matches the test or subtest
+ * Args:
+ *    testName (string): name of the test, subtests separated by /
+ * Returns:
+ *    string: the pattern
+ */
+func testRunPattern(testName string) string {
+	parts := strings.Split(testName, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+/*
+ * Get the name of the package of a test for the reports, i.e. its path
+ * relative to the analyzed folder
+ * Args:
+ *    pkg (string): package of the test, relative to the current directory
+ * Returns:
+ *    string: the name, . for the root package
+ */
+func testPackageName(pkg string) string {
+	pkg = strings.Trim(filepath.ToSlash(pkg), "/")
+	if pkg == "" {
+		return "."
+	}
+	return pkg
+}
+
+/*
+ * Get a name of a test or subtest that can be used in file names
+ * Args:
+ *    testName (string): name of the test, subtests separated by /
+ * Returns:
+ *    string: the name
+ */
+func testFileName(testName string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' {
+			return '-'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, testName)
+}
+
+/*
+ * Find the test files of all packages matching the package patterns.
+ * The packages are listed with go list, so that build tags are respected.
+ * If go list fails, all _test.go files in the folder are used.
+ * Args:
+ *    dir (string): folder of the program
+ *    pathToGoRoot (string): path to the GOROOT of the patched go runtime
+ *    pathToPatchedGoRuntime (string): path to the patched go runtime
+ * Returns:
+ *    []string: found files
+ *    error
+ */
+func findTestFiles(dir, pathToGoRoot, pathToPatchedGoRuntime string) ([]string, error) {
+	args := []string{"list", "-f", "{{.Dir}}{{range .TestGoFiles}},{{.}}{{end}}{{range .XTestGoFiles}},{{.}}{{end}}"}
+	if testTags != "" {
+		args = append(args, "-tags", testTags)
+	}
+	args = append(args, strings.Split(testPatterns, ",")...)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(pathToPatchedGoRuntime, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOROOT="+pathToGoRoot, "PWD="+dir)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		fmt.Printf("Could not list packages, use all test files in %s: %v\n%s", dir, err, stderr.String())
+		return findAllTestFiles(dir)
+	}
+
+	var testFiles []string
+	for _, line := range strings.Split(string(output), "\n") {
+		elems := strings.Split(strings.TrimSpace(line), ",")
+		for _, file := range elems[1:] {
+			if isAnalyzedTestFile(file) {
+				testFiles = append(testFiles, filepath.Join(elems[0], file))
+			}
+		}
+	}
+	return testFiles, nil
+}
+
+/*
+ * Function to find all _test.go files in the specified directory
+ * Args:
+ *    dir (string): folder to search in
+ * Returns:
+ *    []string: found files
+ *    error
+ */
+func findAllTestFiles(dir string) ([]string, error) {
+	var testFiles []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if isAnalyzedTestFile(info.Name()) {
+			testFiles = append(testFiles, path)
+		}
+		return nil
+	})
+	return testFiles, err
+}
+
+/*
+ * Check if a file is a test file that is analyzed. The reproducer tests
+ * created by the toolchain are not analyzed.
+ * Args:
+ *    name (string): name of the file
+ * Returns:
+ *    bool: true if the file is analyzed
+ */
+func isAnalyzedTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(name, reproSuffix)
+}
+
+/*
+ * Get the path of a package relative to the analyzed folder. Symlinks are
+ * resolved in both paths, because go list returns the resolved path of the
+ * package.
+ * Args:
+ *    dir (string): the analyzed folder
+ *    packagePath (string): path of the package
+ * Returns:
+ *    string: the relative path, . for the analyzed folder itself
+ *    error: if the package is not in the analyzed folder
+ */
+func relPackagePath(dir, packagePath string) (string, error) {
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	resolvedPkg, err := filepath.EvalSymlinks(packagePath)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(resolvedDir, resolvedPkg)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("package %s is not in %s", packagePath, dir)
+	}
+	return rel, nil
+}
+
+/*
+ * Function to find all test function in the specified file.
+ * TestMain is not a test and is run by go test around each test.
+ * Args:
+ *    file (string): file to search in
+ * Returns:
+ *    []string: functions
+ *    error
+ */
+func findTestFunctions(file string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var testFunctions []string
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !isTestFunc(fn) {
+			continue
+		}
+		testFunctions = append(testFunctions, fn.Name.Name)
+	}
+	return testFunctions, nil
+}
+
+/*
+ * Check if a function is a test function, i.e. it has the form
+ * func TestXxx(t *testing.T)
+ * Args:
+ *    fn (*ast.FuncDecl): the function
+ * Returns:
+ *    bool: true if it is a test function
+ */
+func isTestFunc(fn *ast.FuncDecl) bool {
+	name := fn.Name.Name
+	if !strings.HasPrefix(name, "Test") || name == "TestMain" {
+		return false
+	}
+
+	// Test must not be followed by a lower case letter
+	if len(name) > 4 {
+		r, _ := utf8.DecodeRuneInString(name[4:])
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	switch t := star.X.(type) {
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "T"
+	case *ast.Ident: // dot import of testing
+		return t.Name == "T"
+	}
+	return false
+}
+
+/*
+ * Check if a test function may contain subtests, i.e. calls Run
+ * with a function literal
+ * Args:
+ *    file (string): file with the test
+ *    testName (string): name of the test function
+ * Returns:
+ *    bool: true if the test may contain subtests
+ */
+func hasSubtests(file string, testName string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return false
+	}
+
+	found := false
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != testName || fn.Body == nil {
+			continue
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return !found
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Run" {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+/*
+ * Find the subtests of a test by running it with the patched go runtime
+ * without recording. Only the innermost subtests are returned, each of
+ * them is recorded, analyzed and replayed on its own.
+ * Args:
+ *    pathToGoRoot (string): path to the GOROOT of the patched go runtime
+ *    pathToPatchedGoRuntime (string): path to the patched go runtime
+ *    pkg (string): package of the test
+ *    file (string): file with the test
+ *    testName (string): name of the test function
+ * Returns:
+ *    []string: the subtests, e.g. TestX/case_1, empty if the test has no subtests
+ */
+func findSubtests(pathToGoRoot, pathToPatchedGoRuntime, pkg, file, testName string) []string {
+	if !hasSubtests(file, testName) {
+		return nil
+	}
+
+	fmt.Printf("Find subtests of %s\n", testName)
+	cmd := exec.Command(pathToPatchedGoRuntime, goTestArgs(timeout, testName, pkg)...)
+	cmd.Env = append(os.Environ(), "GOROOT="+pathToGoRoot)
+	output, _ := cmd.CombinedOutput()
+
+	names := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "=== RUN") {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(line, "=== RUN"))
+		if strings.HasPrefix(name, testName+"/") {
+			names = append(names, name)
+		}
+	}
+
+	subtests := make([]string, 0)
+	for _, name := range names {
+		leaf := true
+		for _, other := range names {
+			if strings.HasPrefix(other, name+"/") {
+				leaf = false
+				break
+			}
+		}
+		if leaf {
+			subtests = append(subtests, name)
+		}
+	}
+
+	return subtests
+}
@@ -25,7 +25,7 @@ import (
  * Add the header into a unit test
  * Args:
  *    fileName (string): path to the file containing the the test
- *    testName (string): name of the test
+ *    testName (string): name of the test, subtests separated by /
  *    replay (bool): true for replay, false for only recording
  *    replayNumber (string): id of the trace to replay
  *    timeoutReplay (int): timeout for replay
@@ -34,6 +34,9 @@ import (
  *    error
  */
 func headerInserterUnit(fileName string, testName string, replay bool, replayNumber string, timeoutReplay int, record bool) error {
+	// the header of a subtest is added into its test function
+	testName = testFuncName(testName)
+
 	if _, err := os.Stat(fileName); os.IsNotExist(err) {
 		return fmt.Errorf("file %s does not exist", fileName)
 	}
@@ -153,7 +156,7 @@ func addHeaderUnit(fileName string, testName string, replay bool, replayNumber s
 			atomicReplayStr = "true"
 		}
 
-		if strings.Contains(line, "func "+testName) {
+		if strings.Contains(line, "func "+testName+"(") {
 
 			if replay {
 				if record {
@@ -16,6 +16,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -33,6 +35,11 @@ var (
 	numberRerecord int
 	testNameFlag   string
 	replayAtomic   bool
+	stackDepth     int
+	includeFilter  string
+	excludeFilter  string
+	baselineFile   string
+	replayTrace    string
 )
 
 func init() {
@@ -49,8 +56,23 @@ func init() {
 	flag.IntVar(&timeoutAna, "T", -1, "Set a timeout in seconds for each run of the analyzer")
 	flag.IntVar(&timeoutReplay, "R", 0, "Set a timeout for each replay")
 	flag.IntVar(&numberRerecord, "r", 10, "limit the number of rerecordings/reanalyses of not executed select cases (per test), set to 0 to not reanalyze, set to -1 to remove limit, default: 10")
-	flag.StringVar(&testNameFlag, "n", "", "set which test to run. If not set, all tests will be run")
+	flag.StringVar(&testNameFlag, "n", "", "set which test to run, subtests can be selected with TestName/subtest. If not set, all tests will be run")
+	flag.StringVar(&testPatterns, "p", "./...", "test: comma separated list of package patterns of the tests, default: ./...")
+	flag.StringVar(&testTags, "tags", "", "test: build tags for go test")
+	flag.BoolVar(&testShort, "short", false, "test: run go test with -short")
+	flag.StringVar(&testTimeout, "timeout", "", "test: timeout of go test for each run, e.g. 30m, default: 10m for the recording, 15m for the replay")
 	flag.BoolVar(&replayAtomic, "A", false, "if set, atomics are ignored for replay")
+	flag.StringVar(&includeFilter, "I", "", "comma separated list of patterns. If set, only operations in matching files are recorded and analyzed")
+	flag.StringVar(&excludeFilter, "X", "", "comma separated list of patterns. Operations in matching files are not recorded and analyzed, e.g. go/pkg/mod/ to exclude dependencies")
+	flag.StringVar(&baselineFile, "B", "", "path to a baseline file with known results. Known results are shown separately and are not replayed")
+	flag.StringVar(&replayTrace, "W", "", "replay: path to the rewritten trace to replay")
+	flag.Var(&programEnv, "e", "main: environment variable KEY=VALUE for the program, can be used multiple times")
+	flag.StringVar(&programStdin, "i", "", "main: path to a file used as stdin of the program")
+	flag.IntVar(&programDuration, "d", 0, "main: send SIGINT to the program after the given number of seconds, 0 to run until the end")
+	flag.StringVar(&configFile, "config", "", "path to the configuration file, default: advocate.toml in the analyzed folder. Flags set on the command line override the file")
+	flag.StringVar(&failOn, "fail-on", "", "comma separated list of bug codes or prefixes, e.g. P01,L, or all. If a confirmed bug matches, the toolchain exits with code 3")
+	flag.BoolVar(&failFound, "fail-found", false, "with -fail-on, also fail on bugs that were not confirmed by a replay")
+	flag.IntVar(&stackDepth, "D", 1, "number of innermost user frames of the call stack recorded for each operation, default: 1 (only the position of the operation)")
 
 	replayAtomic = !replayAtomic // set A to disable atomics for replay
 
@@ -65,6 +87,8 @@ func main() {
 	if len(os.Args) > 2 {
 		mode = os.Args[1]
 		flag.CommandLine.Parse(os.Args[2:])
+		// main: the arguments of the program are given after --
+		programArgs = flag.CommandLine.Args()
 	}
 
 	if help {
@@ -73,6 +97,8 @@ func main() {
 			printHelpMain()
 		case "test", "tests":
 			printHelpUnit()
+		case "replay":
+			printHelpReplay()
 		default:
 			printHelp()
 		}
@@ -83,6 +109,41 @@ func main() {
 	home, _ := os.UserHomeDir()
 	pathToAdvocate = strings.Replace(pathToAdvocate, "~", home, -1)
 	pathToFile = strings.Replace(pathToFile, "~", home, -1)
+	configFile = strings.Replace(configFile, "~", home, -1)
+
+	// settings in the configuration file are used for all flags that are
+	// not set on the command line
+	if err := applyConfig(pathToFile); err != nil {
+		fmt.Println("Could not read configuration: ", err)
+		os.Exit(exitCodeWorkflowFailed)
+	}
+
+	// the recorded programs and the analyzer read the recording
+	// configuration from the environment
+	if stackDepth > 1 {
+		os.Setenv("ADVOCATE_STACK_DEPTH", strconv.Itoa(stackDepth))
+	}
+	if includeFilter != "" {
+		os.Setenv("ADVOCATE_INCLUDE", includeFilter)
+	}
+	if excludeFilter != "" {
+		os.Setenv("ADVOCATE_EXCLUDE", excludeFilter)
+	}
+	if programStdin != "" {
+		programStdin = strings.Replace(programStdin, "~", home, -1)
+		// the program is run in the folder of the main file
+		if abs, err := filepath.Abs(programStdin); err == nil {
+			programStdin = abs
+		}
+	}
+	if baselineFile != "" {
+		baselineFile = strings.Replace(baselineFile, "~", home, -1)
+		// the analyzer is run in the folders of the tests
+		if abs, err := filepath.Abs(baselineFile); err == nil {
+			baselineFile = abs
+		}
+		os.Setenv("ADVOCATE_BASELINE", baselineFile)
+	}
 
 	var err error
 	switch mode {
@@ -90,59 +151,89 @@ func main() {
 		if pathToAdvocate == "" {
 			fmt.Println("Path to advocate required for mode main")
 			printHelpMain()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if pathToFile == "" {
 			fmt.Println("Path to file required")
 			printHelpMain()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if executableName == "" {
 			fmt.Println("Name of the executable required")
 			printHelpMain()
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if (stats || measureTime) && progName == "" {
 			fmt.Println("If -s or -t is set, -N [name] must be set as well")
 			printHelpMain()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		err = runWorkflowMain(pathToAdvocate, pathToFile, executableName, timeoutAna, timeoutReplay)
 	case "test", "tests":
 		if pathToAdvocate == "" {
 			fmt.Println("Path to advocate required")
 			printHelpUnit()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if pathToFile == "" {
 			fmt.Println("Path to test folder required for mode main")
 			printHelpUnit()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if (stats || measureTime) && progName == "" {
 			fmt.Println("If -s or -t is set, -N [name] must be set as well")
 			printHelpUnit()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		err = runWorkflowUnit(pathToAdvocate, pathToFile, progName, measureTime, notExecuted, stats, timeoutAna, timeoutReplay)
 	case "explain":
 		if pathToAdvocate == "" {
 			fmt.Println("Path to advocate required")
 			printHelpUnit()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		if pathToFile == "" {
 			fmt.Println("Path to test folder required for mode main")
 			printHelpUnit()
-			return
+			os.Exit(exitCodeWorkflowFailed)
 		}
 		generateBugReports(pathToFile, pathToAdvocate)
+	case "replay":
+		if pathToAdvocate == "" {
+			fmt.Println("Path to advocate required")
+			printHelpReplay()
+			os.Exit(exitCodeWorkflowFailed)
+		}
+		if pathToFile == "" {
+			fmt.Println("Path to the file with the test or main function required")
+			printHelpReplay()
+			os.Exit(exitCodeWorkflowFailed)
+		}
+		if replayTrace == "" {
+			fmt.Println("Path to the rewritten trace required")
+			printHelpReplay()
+			os.Exit(exitCodeWorkflowFailed)
+		}
+		err = runReplay(pathToAdvocate, pathToFile, testNameFlag, executableName, replayTrace)
 	default:
 		fmt.Println("Choose one mode from 'main' or 'test'")
 		printHelp()
+		os.Exit(exitCodeWorkflowFailed)
 	}
 
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(exitCodeWorkflowFailed)
+	}
+
+	if code := ciExitCode(); code != 0 {
+		fmt.Printf("Found bugs matching -fail-on %s, exit with code %d\n", failOn, code)
+		os.Exit(code)
+	}
+
+	if workflowFailed() {
+		fmt.Println("The workflow failed for at least one test")
+		os.Exit(exitCodeWorkflowFailed)
 	}
 }
 
@@ -151,13 +242,15 @@ func printHelp() {
 	fmt.Println("Modes:")
 	fmt.Println("  main:   Run the workflow for a program with a main function")
 	fmt.Println("  test:   Run the workflow for unit tests")
+	fmt.Println("  replay: Replay a single rewritten trace")
 	fmt.Println("Use ./toolchain <mode> -h for more help")
 }
 
 func printHelpMain() {
-	fmt.Println("Usage: ./toolchain main [options]")
+	fmt.Println("Usage: ./toolchain main [options] [-- program arguments]")
 	fmt.Println("Required Flags:")
 	fmt.Println("  -a [path]: path to the ADCOVATE folder")
+	fmt.Println("  -config [file]: configuration file, default: advocate.toml in the analyzed folder, flags override it")
 	fmt.Println("  -f [path]: path to the file containing the main function")
 	fmt.Println("  -E [name]: name of the program executable")
 	fmt.Println("  -t       : measure the runtimes")
@@ -167,20 +260,61 @@ func printHelpMain() {
 	fmt.Println("  -T [sec] : set a time limit for each analyzer run")
 	fmt.Println("  -R [sec] : set a time limit for each replay run, if 0 there is no timeout, if -1, the timeout is set to 100 times the recording time")
 	fmt.Println("  -r [nr]  : limit the number of rerecordings/reanalyses of not executed select cases, set to 0 to not reanalyze, set to -1 to remove limit, default: 10")
+	fmt.Println("  -D [nr]  : number of innermost user frames of the call stack recorded for each operation, default: 1")
+	fmt.Println("  -I [pat] : comma separated list of patterns. If set, only operations in matching files are recorded and analyzed")
+	fmt.Println("  -X [pat] : comma separated list of patterns. Operations in matching files are not recorded and analyzed, e.g. go/pkg/mod/")
+	fmt.Println("  -B [file]: baseline file with known results, known results are shown separately and not replayed")
+	fmt.Println("  -fail-on [codes]: comma separated list of bug codes or prefixes, e.g. P01,L, or all. Exit with code 3 if a confirmed bug matches")
+	fmt.Println("  -fail-found: with -fail-on, also fail on bugs that were not confirmed by a replay")
+	fmt.Println("  -e [K=V] : set an environment variable for the program, can be used multiple times")
+	fmt.Println("  -i [file]: file used as stdin of the program")
+	fmt.Println("  -d [sec] : send SIGINT to the program after the given number of seconds, e.g. for servers")
+	fmt.Println("  -- [args]: arguments of the program, must be the last option")
+	fmt.Println("The arguments, environment, input and duration are used for the recording and for each replay")
 }
 
 func printHelpUnit() {
 	fmt.Println("Usage: ./toolchain test [options]")
 	fmt.Println("Required Flags:")
 	fmt.Println("  -a [path]: path to the ADCOVATE folder")
+	fmt.Println("  -config [file]: configuration file, default: advocate.toml in the analyzed folder, flags override it")
 	fmt.Println("  -f [path]: path to the folder containing the tests")
 	fmt.Println("  -t       : measure the runtimes")
 	fmt.Println("  -m       : check for never executed operations")
 	fmt.Println("  -s       : create statistics about the analyzed program")
 	fmt.Println("  -N [name]: give a name for the analyzed program. Only required if -s or -t is set")
-	fmt.Println("  -n [name]: name of the test to run. If not set, all tests will be run")
+	fmt.Println("  -n [name]: name of the test to run, a subtest can be selected with TestName/subtest. If not set, all tests will be run")
+	fmt.Println("  -p [pat] : comma separated list of package patterns, e.g. ./pkg/...,./cmd, default: ./...")
+	fmt.Println("  -tags [t]: build tags for go test")
+	fmt.Println("  -short   : run go test with -short")
+	fmt.Println("  -timeout [d]: timeout of go test for each run, e.g. 30m, default: 10m for the recording, 15m for the replay")
 	fmt.Println("  -T [sec] : set a time limit for each analyzer run")
 	fmt.Println("  -R [sec] : set a time limit for each replay run, if 0 there is no timeout, if -1, the timeout is set to 100 times the recording time")
 	fmt.Println("  -L       : disable the rerecording and analysis of replays of leaks")
 	fmt.Println("  -r [nr]  : limit the number of rerecordings/reanalyses of not executed select cases per test, set to 0 to not reanalyze, set to -1 to remove limit, default: 10")
+	fmt.Println("  -D [nr]  : number of innermost user frames of the call stack recorded for each operation, default: 1")
+	fmt.Println("  -I [pat] : comma separated list of patterns. If set, only operations in matching files are recorded and analyzed")
+	fmt.Println("  -X [pat] : comma separated list of patterns. Operations in matching files are not recorded and analyzed, e.g. go/pkg/mod/")
+	fmt.Println("  -B [file]: baseline file with known results, known results are shown separately and not replayed")
+	fmt.Println("  -fail-on [codes]: comma separated list of bug codes or prefixes, e.g. P01,L, or all. Exit with code 3 if a confirmed bug matches")
+	fmt.Println("  -fail-found: with -fail-on, also fail on bugs that were not confirmed by a replay")
+}
+
+func printHelpReplay() {
+	fmt.Println("Usage: ./toolchain replay [options] [-- program arguments]")
+	fmt.Println("Required Flags:")
+	fmt.Println("  -a [path]: path to the ADCOVATE folder")
+	fmt.Println("  -config [file]: configuration file, default: advocate.toml in the analyzed folder, flags override it")
+	fmt.Println("  -f [path]: path to the file containing the test or the main function")
+	fmt.Println("  -W [path]: path to the rewritten trace, e.g. advocateResult/.../rewritten_trace_1")
+	fmt.Println("  -n [name]: name of the test, subtests separated by /, only for tests")
+	fmt.Println("  -tags [t]: build tags for go test, only for tests")
+	fmt.Println("  -short   : run go test with -short, only for tests")
+	fmt.Println("  -timeout [d]: timeout of go test, only for tests, default: 15m")
+	fmt.Println("  -E [name]: name of the program executable, only for main")
+	fmt.Println("  -R [sec] : set a time limit for the replay, if 0 there is no timeout")
+	fmt.Println("  -e [K=V] : set an environment variable for the program, only for main, can be used multiple times")
+	fmt.Println("  -i [file]: file used as stdin of the program, only for main")
+	fmt.Println("  -d [sec] : send SIGINT to the program after the given number of seconds, only for main")
+	fmt.Println("  -- [args]: arguments of the program, only for main, must be the last option")
 }
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: program.go
+// Brief: Run the executable of a main program with its arguments,
+//    environment, input and run duration
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// settings of a main program, used identically for the recording and all replays
+var (
+	programArgs     []string // arguments of the program, given after --
+	programEnv      envList  // additional environment variables, KEY=VALUE
+	programStdin    string   // path to the file used as stdin
+	programDuration int      // seconds after which SIGINT is sent, 0 to run until the end
+)
+
+// list of environment variables given with -e
+type envList []string
+
+func (e *envList) String() string {
+	return strings.Join(*e, ",")
+}
+
+func (e *envList) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("environment variable must have the form KEY=VALUE: %s", value)
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+/*
+ * Run the executable of a main program with the program settings
+ * Args:
+ *    executableName (string): name of the executable in the current directory
+ *    stdout (io.Writer): writer for the output of the program
+ *    stderr (io.Writer): writer for the errors of the program
+ * Returns:
+ *    error: the error of the run
+ */
+func runProgram(executableName string, stdout io.Writer, stderr io.Writer) error {
+	cmd := exec.Command("./"+executableName, programArgs...)
+	cmd.Env = append(os.Environ(), programEnv...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// open the input for each run, so that all runs get the same input
+	if programStdin != "" {
+		stdin, err := os.Open(programStdin)
+		if err != nil {
+			return err
+		}
+		defer stdin.Close()
+		cmd.Stdin = stdin
+	}
+
+	fmt.Println(cmd.String())
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if programDuration > 0 {
+		timer := time.AfterFunc(time.Duration(programDuration)*time.Second, func() {
+			fmt.Printf("Send SIGINT to %s after %d seconds\n", executableName, programDuration)
+			// SIGINT cannot be sent on windows
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				cmd.Process.Kill()
+			}
+		})
+		defer timer.Stop()
+	}
+
+	return cmd.Wait()
+}
+
+/*
+ * Run the replay of a main program with the program settings and get its
+ * exit code. The output is still written to stdout and stderr.
+ * Args:
+ *    executableName (string): name of the executable in the current directory
+ * Returns:
+ *    int: the exit code of the replay, -1 if it could not be determined
+ *    bool: true if the replay timed out
+ */
+func runReplayProgram(executableName string) (int, bool) {
+	var output bytes.Buffer
+	err := runProgram(executableName, io.MultiWriter(os.Stdout, &output),
+		io.MultiWriter(os.Stderr, &output))
+	return getReplayExitCode(output.Bytes(), err)
+}
+
+/*
+ * Get the options of the toolchain for the program settings, e.g. for
+ * the commands in the bug report
+ * Returns:
+ *    string: the options, quoted for the shell, empty if no settings are given
+ */
+func programOptions() string {
+	res := ""
+	for _, env := range programEnv {
+		res += " -e " + shellQuote(env)
+	}
+	if programStdin != "" {
+		res += " -i " + shellQuote(programStdin)
+	}
+	if programDuration > 0 {
+		res += " -d " + strconv.Itoa(programDuration)
+	}
+	if len(programArgs) > 0 {
+		res += " --"
+		for _, arg := range programArgs {
+			res += " " + shellQuote(arg)
+		}
+	}
+	return strings.TrimSpace(res)
+}
+
+/*
+ * Quote a string for the shell
+ * Args:
+ *    s (string): the string
+ * Returns:
+ *    string: the quoted string
+ */
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: records.go
+// Brief: Add the verdict of the replay to the result records of the analyzer
+//
+// Author: Erik Kassubek
+// Created: 2024-11-26
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"analyzer/results"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+var exitCodeRegex = regexp.MustCompile(`Exit Replay with code\s+(\d+)`)
+
+/*
+ * Run a replay and get its exit code. The output is still written to
+ * stdout and stderr.
+ * Args:
+ *    name (string): the command
+ *    args (...string): the arguments
+ * Returns:
+ *    int: the exit code of the replay, -1 if it could not be determined
+ *    bool: true if the replay timed out
+ */
+func runReplayCommand(name string, args ...string) (int, bool) {
+	var output bytes.Buffer
+
+	cmd := exec.Command(name, args...)
+	fmt.Println(cmd.String())
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	err := cmd.Run()
+
+	return getReplayExitCode(output.Bytes(), err)
+}
+
+/*
+ * Get the exit code of a replay from its output
+ * Args:
+ *    output ([]byte): the output of the replay
+ *    err (error): the error of the run of the replay
+ * Returns:
+ *    int: the exit code of the replay, -1 if it could not be determined
+ *    bool: true if the replay timed out
+ */
+func getReplayExitCode(output []byte, err error) (int, bool) {
+	if match := exitCodeRegex.FindSubmatch(output); match != nil {
+		code, _ := strconv.Atoi(string(match[1]))
+		return code, code == 10
+	}
+
+	if bytes.Contains(output, []byte("test timed out")) {
+		return -1, true
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), false
+	}
+
+	return -1, false
+}
+
+/*
+ * Get the verdict of a replay
+ * Args:
+ *    exitCode (int): the exit code of the replay
+ *    timeout (bool): true if the replay timed out, e.g. by the test timeout
+ *      without an exit code of the replay
+ * Returns:
+ *    string: the verdict (results.Verdict...)
+ */
+func replayVerdict(exitCode int, timeout bool) string {
+	if timeout {
+		return results.VerdictTimeout
+	}
+	return results.VerdictFromExitCode(exitCode)
+}
+
+/*
+ * Add the verdict of a replay to the record of the replayed bug
+ * Args:
+ *    folder (string): folder containing the result records
+ *    trace (string): path to the rewritten trace that was replayed
+ *    exitCode (int): the exit code of the replay
+ *    timeout (bool): true if the replay timed out
+ * Returns:
+ *    error: if the records could not be read or written
+ */
+func updateRecord(folder string, trace string, exitCode int, timeout bool) error {
+	return results.SetReplayResult(folder, filepath.Base(trace), exitCode, replayVerdict(exitCode, timeout))
+}
+
+/*
+ * Get the analyses in a result folder, that were stopped before they
+ * finished, e.g. by the timeout of the analyzer
+ * Args:
+ *    folder (string): folder containing the result records
+ * Returns:
+ *    map[string]string: number of the analyzed trace -> reason, e.g. timeout or memory
+ */
+func readIncomplete(folder string) map[string]string {
+	data, err := results.ReadRecordsData(folder)
+	if err != nil {
+		return nil
+	}
+
+	return data.Incomplete
+}
+
+/*
+ * Print a note if the analysis of a trace was stopped before it finished.
+ * Only the bugs found until then are replayed.
+ * Args:
+ *    folder (string): folder containing the result records
+ *    traceNr (string): number of the analyzed trace, 0 for the recorded trace
+ */
+func printIncomplete(folder string, traceNr string) {
+	if reason, ok := readIncomplete(folder)[traceNr]; ok {
+		fmt.Println("Analysis incomplete (" + reason + "). Only the results found until then are used")
+	}
+}
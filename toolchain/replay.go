@@ -0,0 +1,157 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: replay.go
+// Brief: Replay a single rewritten trace
+//
+// Author: Erik Kassubek
+// Created: 2024-11-27
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"analyzer/results"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+/*
+ * Replay one rewritten trace of a test or main program, e.g. to reproduce
+ * a bug from the bug report. The trace is copied next to the program, if it
+ * is not already there, and removed after the replay.
+ * Args:
+ *    pathToAdvocate (string): path to the ADVOCATE folder
+ *    pathToFile (string): path to the file containing the test or main function
+ *    testName (string): name of the test, subtests separated by /, empty for main
+ *    executableName (string): name of the executable, only for main
+ *    pathToTrace (string): path to the rewritten trace folder
+ * Returns:
+ *    error
+ */
+func runReplay(pathToAdvocate, pathToFile, testName, executableName, pathToTrace string) error {
+	pathToPatchedGoRuntime := filepath.Join(pathToAdvocate, "go-patch/bin/go")
+	pathToGoRoot := filepath.Join(pathToAdvocate, "go-patch")
+
+	if runtime.GOOS == "windows" {
+		pathToPatchedGoRuntime += ".exe"
+	}
+
+	if testName == "" && executableName == "" {
+		return errors.New("Name of the test or of the executable required")
+	}
+
+	pathToFile, err := filepath.Abs(pathToFile)
+	if err != nil {
+		return err
+	}
+	pathToTrace, err = filepath.Abs(pathToTrace)
+	if err != nil {
+		return err
+	}
+
+	traceNum := extractTraceNumber(filepath.Base(pathToTrace))
+	if traceNum == "" {
+		return fmt.Errorf("%s is not a rewritten trace", pathToTrace)
+	}
+
+	dir := filepath.Dir(pathToFile)
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("Failed to change directory: %v", err)
+	}
+
+	// the replay reads the trace from the folder of the program
+	traceInDir := filepath.Join(dir, "rewritten_trace_"+traceNum)
+	if traceInDir != pathToTrace {
+		if _, err := os.Stat(traceInDir); err == nil {
+			return fmt.Errorf("%s already exists", traceInDir)
+		}
+		if err := copyDir(pathToTrace, traceInDir); err != nil {
+			return fmt.Errorf("Failed to copy trace: %v", err)
+		}
+		defer os.RemoveAll(traceInDir)
+	}
+
+	timeout := max(timeoutReplay, 0)
+
+	os.Setenv("GOROOT", pathToGoRoot)
+	defer os.Unsetenv("GOROOT")
+
+	var exitCode int
+	var timedOut bool
+	if testName != "" {
+		if err := headerInserterUnit(pathToFile, testName, true, traceNum, timeout, false); err != nil {
+			return err
+		}
+		exitCode, timedOut = runReplayCommand(pathToPatchedGoRuntime, goTestArgs("15m", testName, "")...)
+		headerRemoverUnit(pathToFile)
+	} else {
+		if err := headerInserterMain(pathToFile, true, traceNum, timeout, false); err != nil {
+			return err
+		}
+		if err := runCommand(pathToPatchedGoRuntime, "build"); err != nil {
+			headerRemoverMain(pathToFile)
+			return err
+		}
+		exitCode, timedOut = runReplayProgram(executableName)
+		headerRemoverMain(pathToFile)
+	}
+
+	fmt.Printf("Replay of %s: %s (exit code %d)\n", filepath.Base(pathToTrace),
+		replayVerdict(exitCode, timedOut), exitCode)
+
+	if testName != "" && replayVerdict(exitCode, timedOut) == results.VerdictConfirmed {
+		repro, err := generateRepro(pathToFile, testName, pathToTrace)
+		if err != nil {
+			return fmt.Errorf("Could not create reproducer test: %v", err)
+		}
+		fmt.Println("Reproducer test created: ", repro)
+	}
+
+	return nil
+}
+
+/*
+ * Copy a folder with all files in it
+ * Args:
+ *    src (string): the folder to copy
+ *    dest (string): the new folder
+ * Returns:
+ *    error
+ */
+func copyDir(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
@@ -29,6 +29,17 @@ func generateBugReports(folder string, advocateRoot string) {
 	runCommand(analyzerPath, "explain", "-t", folder)
 }
 
+/*
+ * Generate the html report over all bugs in the result folder
+ * Args:
+ *    folder string: path to the advocateResult folder
+ *    advocateRoot string: path to ADVOCATE
+ */
+func generateHTMLReport(folder string, advocateRoot string) {
+	analyzerPath := filepath.Join(advocateRoot, "analyzer", "analyzer")
+	runCommand(analyzerPath, "report", "-R", folder)
+}
+
 /*
  * Get all files in folder path with name fileName
  * Args:
@@ -69,6 +80,7 @@ func moveResults(packagePath, destination string) {
 		"advocateTrace",
 		"results_machine.log",
 		"results_readable.log",
+		"results_records.json",
 		"output.log",
 	}
 
@@ -77,6 +89,7 @@ func moveResults(packagePath, destination string) {
 		"advocateTraceReplay_*",
 		"results_machine_*",
 		"results_readable_*",
+		scopeResultPrefix + "*",
 	}
 
 	for _, file := range filesToMove {
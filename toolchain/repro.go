@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: repro.go
+// Brief: Create a standalone reproducer test from a confirmed replay
+//
+// Author: Erik Kassubek
+// Created: 2024-11-28
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// suffix of the files of the reproducer tests
+const reproSuffix = "_advocate_repro_test.go"
+
+// code of the reproducer test
+const reproTemplate = `//go:build advocate
+
+// Code generated by ADVOCATE. DO NOT EDIT.
+//
+// Reproducer for the bug in %[1]s confirmed by the replay of %[2]s.
+// It replays the trace in
+//
+//	%[3]s
+//
+// and fails as long as the replay confirms the bug. Run it in the package
+// with the patched go runtime of ADVOCATE:
+//
+//	go test -tags advocate -count=1 -run '%[7]s' .
+//
+// If the code of the test changes, the trace may not fit the test any more.
+// In this case, run ADVOCATE again to create a new reproducer.
+
+package %[5]s
+
+import (
+	"advocate"
+	"testing"
+)
+
+func %[4]s(t *testing.T) {
+	advocate.InitReplayFolder(%[3]q, false, 0, %[6]t)
+	defer func() {
+		// recover must be called directly in the deferred function
+		r := recover()
+		advocate.FinishReplay()
+		if code := advocate.ReplayExitCode(); code >= 20 {
+			t.Errorf("The replay confirmed the bug (exit code %%d)", code)
+		} else if r != nil {
+			t.Errorf("The replay panicked: %%v", r)
+		}
+	}()
+
+	%[8]s(t)
+}
+`
+
+/*
+ * Create a reproducer test for a confirmed replay of a test. The test calls
+ * the original test, so it is created in the package of the test, with a copy
+ * of the trace in the testdata folder of the package. It is only built with
+ * the advocate build tag.
+ * Args:
+ *    fileName (string): path to the file containing the test
+ *    testName (string): name of the test, subtests separated by /
+ *    trace (string): path to the replayed rewritten trace
+ * Returns:
+ *    string: path to the created test file
+ *    error
+ */
+func generateRepro(fileName, testName, trace string) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+
+	traceNum := extractTraceNumber(filepath.Base(trace))
+	if traceNum == "" {
+		return "", fmt.Errorf("%s is not a rewritten trace", trace)
+	}
+
+	// subtests are run as subtests of the reproducer test
+	name := testFileName(testName) + "_" + traceNum
+	reproTest := "TestAdvocateRepro_" + strings.TrimPrefix(testFuncName(testName), "Test") + "_" + traceNum
+	runName := reproTest + strings.TrimPrefix(testName, testFuncName(testName))
+	traceDir := "testdata/advocate_repro_" + name
+
+	// the test must be in the same folder as the file of the original test
+	folder := filepath.Dir(fileName)
+
+	dest := filepath.Join(folder, filepath.FromSlash(traceDir))
+	os.RemoveAll(dest)
+	if err := copyDir(trace, dest); err != nil {
+		return "", err
+	}
+
+	code := fmt.Sprintf(reproTemplate, testName, filepath.Base(trace), traceDir,
+		reproTest, file.Name.Name, replayAtomic, testRunPattern(runName), testFuncName(testName))
+
+	path := filepath.Join(folder, name+reproSuffix)
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -39,6 +39,8 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 		return fmt.Errorf("file %s does not exist", pathToFile)
 	}
 
+	timeStartWorkflow := time.Now()
+
 	pathToPatchedGoRuntime := filepath.Join(pathToAdvocate, "go-patch/bin/go")
 
 	if runtime.GOOS == "windows" {
@@ -85,6 +87,12 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 		return fmt.Errorf("Failed to set GOROOT: %v", err)
 	}
 	fmt.Println("GOROOT exported")
+	// the working directory is the directory of the main file
+	if absFile, err := filepath.Abs(filepath.Base(pathToFile)); err == nil {
+		fmt.Println("FileName: ", absFile)
+	}
+	fmt.Println("ExecutableName: ", executableName)
+	fmt.Println("ProgramOptions: ", programOptions())
 	// Unset GOROOT
 	defer os.Unsetenv("GOROOT")
 
@@ -108,9 +116,8 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 		}
 
 		// run the program
-		fmt.Printf("./%s\n", executableName)
 		timeStart := time.Now()
-		if err := runCommand("./" + executableName); err != nil {
+		if err := runProgram(executableName, os.Stdout, os.Stderr); err != nil {
 			headerRemoverMain(pathToFile)
 		}
 		durationRun = time.Since(timeStart)
@@ -131,9 +138,8 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 	}
 
 	// run the program
-	fmt.Printf("./%s\n", executableName)
 	timeStart := time.Now()
-	if err := runCommand("./" + executableName); err != nil {
+	if err := runProgram(executableName, os.Stdout, os.Stderr); err != nil {
 		headerRemoverMain(pathToFile)
 	}
 	durationRecord = time.Since(timeStart)
@@ -150,6 +156,8 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 		return fmt.Errorf("Error applying analyzer: %v", err)
 	}
 	durationAnalysis = time.Since(timeStart)
+	printIncomplete(dir, "0")
+	analyzeScopes(pathToAnalyzer, dir, nil)
 
 	// Find rewritten_trace directories
 	rewrittenTraces, err := filepath.Glob(filepath.Join(dir, "rewritten_trace*"))
@@ -182,8 +190,10 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 		}
 
 		// run the program
-		fmt.Printf("./%s\n", executableName)
-		runCommand("./" + executableName)
+		exitCode, timeout := runReplayProgram(executableName)
+		if err := updateRecord(dir, trace, exitCode, timeout); err != nil {
+			log.Println("Could not update result record: ", err)
+		}
 
 		fmt.Printf("Remove replay header from %s\n", pathToFile)
 		if err := headerRemoverMain(pathToFile); err != nil {
@@ -199,7 +209,17 @@ func runWorkflowMain(pathToAdvocate string, pathToFile string, executableName st
 
 	// Generate Bug Reports
 	fmt.Println("Generate Bug Reports")
-	generateBugReports(resultPath, pathToAdvocate)
+	if outputFormats["markdown"] {
+		generateBugReports(resultPath, pathToAdvocate)
+	}
+	if outputFormats["html"] {
+		generateHTMLReport(resultPath, pathToAdvocate)
+	}
+	addTestResult(executableName, "main", resultPath, time.Since(timeStartWorkflow), nil)
+	addScopeResults(resultPath, executableName, "main", pathToAdvocate, time.Since(timeStartWorkflow))
+	if err := writeCIReports(resultPath); err != nil {
+		fmt.Println("Could not write the JUnit report and summary: ", err)
+	}
 
 	resTimes := map[string]time.Duration{
 		"run":      durationRun,
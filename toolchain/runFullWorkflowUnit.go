@@ -13,6 +13,7 @@
 package main
 
 import (
+	"analyzer/results"
 	"errors"
 	"fmt"
 	"io"
@@ -55,6 +56,12 @@ func runWorkflowUnit(pathToAdvocate, dir, progName string,
 	}
 
 	pathToAnalyzer := filepath.Join(pathToAdvocate, "analyzer/analyzer")
+	pathToPatchedGoRuntime := filepath.Join(pathToAdvocate, "go-patch/bin/go")
+	pathToGoRoot := filepath.Join(pathToAdvocate, "go-patch")
+
+	if runtime.GOOS == "windows" {
+		pathToPatchedGoRuntime += ".exe"
+	}
 
 	// Change to the directory
 	if err := os.Chdir(dir); err != nil {
@@ -69,8 +76,8 @@ func runWorkflowUnit(pathToAdvocate, dir, progName string,
 		return fmt.Errorf("Failed to create advocateResult directory: %v", err)
 	}
 
-	// Find all _test.go files in the directory
-	testFiles, err := findTestFiles(dir)
+	// Find the test files of all packages matching the package patterns
+	testFiles, err := findTestFiles(dir, pathToGoRoot, pathToPatchedGoRuntime)
 	if err != nil {
 		return fmt.Errorf("Failed to find test files: %v", err)
 	}
@@ -92,6 +99,11 @@ func runWorkflowUnit(pathToAdvocate, dir, progName string,
 		}
 
 		packagePath := filepath.Dir(file)
+		adjustedPackagePath, err := relPackagePath(dir, packagePath)
+		if err != nil {
+			return fmt.Errorf("Failed to get the package of %s: %v", file, err)
+		}
+
 		testFunctions, err := findTestFunctions(file)
 		if err != nil {
 			log.Printf("Failed to find test functions in %s: %v", file, err)
@@ -99,45 +111,69 @@ func runWorkflowUnit(pathToAdvocate, dir, progName string,
 		}
 
 		for _, testFunc := range testFunctions {
-			if testNameFlag != "" && testNameFlag != testFunc {
+			if testNameFlag != "" && testFuncName(testNameFlag) != testFunc {
+				continue
+			}
+			if isSkipped(testFunc) {
+				fmt.Printf("Skip test %s\n", testFunc)
 				continue
 			}
 			ranTest = true
 
-			attemptedTests++
-			packageName := filepath.Base(packagePath)
-			fileName := filepath.Base(file)
-			fmt.Printf("\nRunning full workflow for test: %s in package: %s in file: %s\n\n", testFunc, packageName, file)
-
-			adjustedPackagePath := strings.TrimPrefix(packagePath, dir)
-			fileNameWithoutEnding := strings.TrimSuffix(fileName, ".go")
-			directoryName := fmt.Sprintf("advocateResult/file(%d)-test(%d)-%s-%s", currentFile, attemptedTests, fileNameWithoutEnding, testFunc)
-			directoryPath := filepath.Join(dir, directoryName)
-			if err := os.MkdirAll(directoryName, os.ModePerm); err != nil {
-				log.Printf("Failed to create directory %s: %v", directoryName, err)
-				continue
+			// record each subtest on its own
+			testNames := []string{testFunc}
+			if strings.Contains(testNameFlag, "/") {
+				testNames = []string{testNameFlag}
+			} else if subtests := findSubtests(pathToGoRoot, pathToPatchedGoRuntime, adjustedPackagePath, file, testFunc); len(subtests) > 0 {
+				testNames = subtests
 			}
 
-			// Execute full workflow
-			times, nrReplay, nrAnalyzer, err := unitTestFullWorkflow(pathToAdvocate, dir, testFunc, adjustedPackagePath, file, directoryName)
+			for _, testName := range testNames {
+				if isSkipped(testName) {
+					fmt.Printf("Skip test %s\n", testName)
+					continue
+				}
 
-			if measureTime {
-				updateTimeFiles(progName, testFunc, resultPath, times, nrReplay, nrAnalyzer)
-			}
+				attemptedTests++
+				packageName := filepath.Base(packagePath)
+				fileName := filepath.Base(file)
+				fmt.Printf("\nRunning full workflow for test: %s in package: %s in file: %s\n\n", testName, packageName, file)
 
-			// Move logs and results to the appropriate directory
-			moveResults(packagePath, directoryName)
+				fileNameWithoutEnding := strings.TrimSuffix(fileName, ".go")
+				directoryName := fmt.Sprintf("advocateResult/file(%d)-test(%d)-%s-%s", currentFile, attemptedTests, fileNameWithoutEnding, testFileName(testName))
+				directoryPath := filepath.Join(dir, directoryName)
+				if err := os.MkdirAll(directoryName, os.ModePerm); err != nil {
+					log.Printf("Failed to create directory %s: %v", directoryName, err)
+					continue
+				}
 
-			if err != nil {
-				fmt.Printf("File %d with Test %d failed, check output.log for more information.\n", currentFile, attemptedTests)
-				skippedTests++
-			}
+				// Execute full workflow
+				timeStart := time.Now()
+				times, nrReplay, nrAnalyzer, err := unitTestFullWorkflow(pathToAdvocate, dir, testName, adjustedPackagePath, file, directoryName)
+				duration := time.Since(timeStart)
+
+				if measureTime {
+					updateTimeFiles(progName, testName, resultPath, times, nrReplay, nrAnalyzer)
+				}
+
+				// Move logs and results to the appropriate directory
+				moveResults(packagePath, directoryName)
+
+				if err != nil {
+					fmt.Printf("File %d with Test %d failed, check output.log for more information.\n", currentFile, attemptedTests)
+					skippedTests++
+				}
 
-			generateBugReports(directoryPath, pathToAdvocate)
+				if outputFormats["markdown"] {
+					generateBugReports(directoryPath, pathToAdvocate)
+				}
+				addTestResult(testName, testPackageName(adjustedPackagePath), directoryPath, duration, err)
+				addScopeResults(directoryPath, testName, testPackageName(adjustedPackagePath), pathToAdvocate, duration)
 
-			if stats {
-				updateStatsFiles(pathToAnalyzer, progName, testFunc, directoryPath)
-				// create statistics
+				if stats {
+					updateStatsFiles(pathToAnalyzer, progName, testName, directoryPath)
+					// create statistics
+				}
 			}
 		}
 
@@ -148,6 +184,13 @@ func runWorkflowUnit(pathToAdvocate, dir, progName string,
 		return fmt.Errorf("Could not find test function %s\n", testNameFlag)
 	}
 
+	if outputFormats["html"] {
+		generateHTMLReport(resultPath, pathToAdvocate)
+	}
+	if err := writeCIReports(resultPath); err != nil {
+		fmt.Println("Could not write the JUnit report and summary: ", err)
+	}
+
 	// Check for untriggered selects
 	if notExecuted && testNameFlag != "" {
 		fmt.Println("Check for untriggered selects and not executed progs")
@@ -215,54 +258,6 @@ func updateTimeFiles(progName string, testName string, folderName string, times
 	}
 }
 
-/*
- * Function to find all _test.go files in the specified directory
- * Args:
- *    dir (string): folder to search in
- * Returns:
- *    []string: found files
- *    error
- */
-func findTestFiles(dir string) ([]string, error) {
-	var testFiles []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if strings.HasSuffix(info.Name(), "_test.go") {
-			testFiles = append(testFiles, path)
-		}
-		return nil
-	})
-	return testFiles, err
-}
-
-/*
- * Function to find all test function in the specified file
- * Args:
- *    file (string): file to search in
- * Returns:
- *    []string: functions
- *    error
- */
-func findTestFunctions(file string) ([]string, error) {
-	content, err := os.ReadFile(file)
-	if err != nil {
-		return nil, err
-	}
-
-	var testFunctions []string
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "func Test") && strings.Contains(line, "*testing.T") {
-			testFunc := strings.TrimSpace(strings.Split(line, "(")[0])
-			testFunc = strings.TrimPrefix(testFunc, "func ")
-			testFunctions = append(testFunctions, testFunc)
-		}
-	}
-	return testFunctions, nil
-}
-
 /*
  * Run the full workflow for a given unit test
  * Args:
@@ -346,6 +341,7 @@ func unitTestFullWorkflow(pathToAdvocate string, dir string,
 	}
 
 	unitTestAnalyzer(pathToAnalyzer, dir, pkg, "advocateTrace", output, resTimes, "-1")
+	analyzeScopes(pathToAnalyzer, filepath.Join(dir, pkg), scenarioArgs(pkg))
 
 	lenRewTraces := unitTestReplay(pathToGoRoot, pathToPatchedGoRuntime, dir, pkg, file, testName, resTimes, false)
 
@@ -371,7 +367,7 @@ func unitTestRun(pkg, file, testName string, resTimes map[string]time.Duration)
 
 		timeStart := time.Now()
 		fmt.Println("Run T0")
-		err := runCommand("go", "test", "-v", "-timeout", timeout, "-count=1", "-run="+testName, "./"+pkg)
+		err := runCommand("go", goTestArgs(timeout, testName, pkg)...)
 		if err != nil {
 			log.Println("Test failed: ", err)
 		}
@@ -402,7 +398,7 @@ func unitTestRecord(pathToGoRoot, pathToPatchedGoRuntime, pkg, file, testName st
 	fmt.Println("GOROOT = " + pathToGoRoot + " exported")
 
 	timeStart := time.Now()
-	err := runCommand(pathToPatchedGoRuntime, "test", "-v", "-timeout", timeout, "-count=1", "-run="+testName, "./"+pkg)
+	err := runCommand(pathToPatchedGoRuntime, goTestArgs(timeout, testName, pkg)...)
 	if err != nil {
 		log.Println(err)
 		// log.Println("Test failed, removing header and exiting")
@@ -427,20 +423,26 @@ func unitTestAnalyzer(pathToAnalyzer, dir, pkg, traceName, output string, resTim
 
 	startTime := time.Now()
 	var err error
-	if resultID == "-1" {
-		err = runCommand(pathToAnalyzer, "run", "-t", filepath.Join(dir, pkg, traceName), "-T", strconv.Itoa(timeoutAna))
-	} else {
+	args := append([]string{"run", "-t", filepath.Join(dir, pkg, traceName), "-T", strconv.Itoa(timeoutAna)}, scenarioArgs(pkg)...)
+	if resultID != "-1" {
 		outM := fmt.Sprintf("results_machine_%s", resultID)
 		outR := fmt.Sprintf("results_readable_%s", resultID)
 		outT := fmt.Sprintf("rewritten_trace_%s", resultID)
-		err = runCommand(pathToAnalyzer, "run", "-t", filepath.Join(dir, pkg, traceName), "-T", strconv.Itoa(timeoutAna), "-outM", outM, "-outR", outR, "-outT", outT, "-ignoreRew", "results_machine.log")
+		args = append(args, "-outM", outM, "-outR", outR, "-outT", outT, "-ignoreRew", "results_machine.log")
 	}
+	err = runCommand(pathToAnalyzer, args...)
 	if err != nil {
 		fmt.Println("Analyzer failed", err)
 		log.Println("Analyzer failed", err)
 	}
 	resTimes["analyzer"] += time.Since(startTime)
 
+	traceNr := "0"
+	if resultID != "-1" {
+		traceNr = resultID
+	}
+	printIncomplete(filepath.Join(dir, pkg), traceNr)
+
 	fileOuputRead, err := os.OpenFile(output, os.O_RDONLY, 0644)
 	if err != nil {
 		fmt.Println("Could not open file: ", err)
@@ -519,8 +521,18 @@ func unitTestReplay(pathToGoRoot, pathToPatchedGoRuntime, dir, pkg, file, testNa
 
 		fmt.Printf("\nRun replay %d/%d\n", i+1, len(rewrittenTraces))
 		startTime := time.Now()
-		runCommand(pathToPatchedGoRuntime, "test", "-v", "-count=1", "-timeout", "15m", "-run="+testName, "./"+pkg)
+		exitCode, timeout := runReplayCommand(pathToPatchedGoRuntime, goTestArgs("15m", testName, pkg)...)
 		resTimes["replay"] += time.Since(startTime)
+		if err := updateRecord(pathPkg, trace, exitCode, timeout); err != nil {
+			log.Println("Could not update result record: ", err)
+		}
+		if !record && replayVerdict(exitCode, timeout) == results.VerdictConfirmed {
+			if repro, err := generateRepro(file, testName, trace); err != nil {
+				log.Println("Could not create reproducer test: ", err)
+			} else {
+				fmt.Println("Reproducer test created: ", repro)
+			}
+		}
 		fmt.Println("Add replay time: ", resTimes["replay"])
 
 		os.Unsetenv("GOROOT")
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: scopes.go
+// Brief: Analyze the traces of the recording scopes separately
+//
+// Author: Erik Kassubek
+// Created: 2024-12-02
+//
+// License: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// prefix of the trace folders written by advocate.InitTracingScope
+	scopeTracePrefix = "advocateTrace_"
+	// prefix of the result folders of the scopes
+	scopeResultPrefix = "advocateScope_"
+)
+
+/*
+ * Analyze the trace of each recording scope, e.g. of each test that called
+ * advocate.InitTracingScope, separately. The trace advocateTrace_<name> is
+ * moved into the folder advocateScope_<name>, in which the analyzer writes
+ * the results of the scope. The traces of the scopes are not rewritten,
+ * because the replay can only replay a complete run.
+ * Args:
+ *    pathToAnalyzer (string): path to the analyzer
+ *    dir (string): folder containing the trace folders
+ *    scenarios ([]string): arguments of the analyzer for the scenarios
+ */
+func analyzeScopes(pathToAnalyzer, dir string, scenarios []string) {
+	traces, _ := filepath.Glob(filepath.Join(dir, scopeTracePrefix+"*"))
+	for _, trace := range traces {
+		name := strings.TrimPrefix(filepath.Base(trace), scopeTracePrefix)
+		resultFolder := filepath.Join(dir, scopeResultPrefix+name)
+
+		if err := os.RemoveAll(resultFolder); err != nil {
+			fmt.Printf("Could not remove old results of scope %s: %v\n", name, err)
+			continue
+		}
+		if err := os.MkdirAll(resultFolder, os.ModePerm); err != nil {
+			fmt.Printf("Could not create result folder of scope %s: %v\n", name, err)
+			continue
+		}
+
+		scopeTrace := filepath.Join(resultFolder, "advocateTrace")
+		if err := os.Rename(trace, scopeTrace); err != nil {
+			fmt.Printf("Could not move trace of scope %s: %v\n", name, err)
+			continue
+		}
+
+		fmt.Printf("Run the analyzer for scope %s\n", name)
+		args := append([]string{"run", "-t", scopeTrace, "-r", resultFolder, "-x", "-T", strconv.Itoa(timeoutAna)}, scenarios...)
+		if err := runCommand(pathToAnalyzer, args...); err != nil {
+			fmt.Printf("Analyzer failed for scope %s: %v\n", name, err)
+		}
+		printIncomplete(resultFolder, "0")
+	}
+}
+
+/*
+ * Add the reports and the CI results of all scopes in a result folder
+ * Args:
+ *    folder (string): result folder containing the advocateScope_<name> folders
+ *    name (string): name of the test or program the scopes were recorded in
+ *    pkg (string): package of the test, main for main programs
+ *    pathToAdvocate (string): path to ADVOCATE
+ *    duration (time.Duration): duration of the workflow
+ */
+func addScopeResults(folder, name, pkg, pathToAdvocate string, duration time.Duration) {
+	scopes, _ := filepath.Glob(filepath.Join(folder, scopeResultPrefix+"*"))
+	for _, scope := range scopes {
+		if outputFormats["markdown"] {
+			generateBugReports(scope, pathToAdvocate)
+		}
+		scopeName := strings.TrimPrefix(filepath.Base(scope), scopeResultPrefix)
+		addTestResult(name+"/"+scopeName, pkg, scope, duration, nil)
+	}
+}